@@ -0,0 +1,216 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"bytemystery-com/vboxssh/vm"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+func initAuditLog() {
+	vm.SetParseReportCallBack(func(r *vm.ParseReport) {
+		SetStatusText(fmt.Sprintf(lang.X("parse.unparsed", "VBoxManage output of '%s' on '%s' was not fully understood - see the audit log"), r.Cmd, r.Server), MsgWarning)
+	})
+	root := Gui.App.Storage().RootURI()
+	if root == nil {
+		return
+	}
+	vm.SetAuditLogDir(filepath.Join(root.Path(), "audit"))
+}
+
+type AuditViewer struct {
+	server   *vm.VmServer
+	entries  []vm.AuditEntry
+	filtered []*vm.AuditEntry
+
+	search     *widget.Entry
+	thisServer *widget.Check
+	polling    *widget.Check
+	errorsOnly *widget.Check
+	list       *widget.List
+	details    *widget.Label
+}
+
+// Audit log of all commands - filtered to server s
+func showAuditLog(s *vm.VmServer) {
+	a := AuditViewer{server: s}
+
+	a.search = widget.NewEntry()
+	a.search.SetPlaceHolder(lang.X("audit.search_placeholder", "Search command, VM, output ..."))
+	a.search.OnChanged = func(string) {
+		a.filter()
+	}
+	a.thisServer = widget.NewCheck(lang.X("audit.thisserver", "Only this server"), func(bool) {
+		a.filter()
+	})
+	a.thisServer.SetChecked(s != nil)
+	if s == nil {
+		a.thisServer.Disable()
+	}
+	a.polling = widget.NewCheck(lang.X("audit.polling", "Status polling"), func(bool) {
+		a.filter()
+	})
+	a.errorsOnly = widget.NewCheck(lang.X("audit.errors", "Only errors"), func(bool) {
+		a.filter()
+	})
+	reload := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), func() {
+		a.load()
+	})
+
+	a.details = widget.NewLabel("")
+	a.details.Wrapping = fyne.TextWrapWord
+	a.details.TextStyle = fyne.TextStyle{Monospace: true}
+
+	a.list = widget.NewList(func() int {
+		return len(a.filtered)
+	}, func() fyne.CanvasObject {
+		label := widget.NewLabel("")
+		label.Truncation = fyne.TextTruncateEllipsis
+		return label
+	}, func(id widget.ListItemID, o fyne.CanvasObject) {
+		label, ok := o.(*widget.Label)
+		if !ok || id >= len(a.filtered) {
+			return
+		}
+		e := a.filtered[id]
+		label.SetText(fmt.Sprintf("%s  %s  %s  %s %s", e.Time.Local().Format("2006-01-02 15:04:05"), e.Server,
+			a.vmName(e), e.Cmd, strings.Join(e.Args, " ")))
+		if e.Exit != 0 {
+			label.Importance = widget.DangerImportance
+		} else {
+			label.Importance = widget.MediumImportance
+		}
+		label.Refresh()
+	})
+	a.list.OnSelected = func(id widget.ListItemID) {
+		if id < len(a.filtered) {
+			a.details.SetText(a.format(a.filtered[id]))
+		}
+	}
+
+	top := container.NewBorder(nil, nil, nil, reload,
+		container.NewVBox(a.search, container.NewHBox(a.thisServer, a.polling, a.errorsOnly)))
+	split := container.NewVSplit(a.list, container.NewVScroll(a.details))
+	split.SetOffset(0.65)
+
+	title := lang.X("audit.title", "Audit log")
+	if s != nil {
+		title += " - " + s.Name
+	}
+	dia := dialog.NewCustom(title, lang.X("audit.close", "Close"), container.NewBorder(top, nil, nil, nil, split), Gui.MainWindow)
+	ms := Gui.MainWindow.Canvas().Size()
+	dia.Resize(fyne.NewSize(ms.Width*.9, ms.Height*.9))
+	dia.Show()
+
+	a.load()
+}
+
+func (a *AuditViewer) load() {
+	go func() {
+		entries, err := vm.ReadAuditLog(nil)
+		fyne.Do(func() {
+			if err != nil {
+				SetStatusText(fmt.Sprintf(lang.X("audit.read_error", "Audit log could not be read: %s"), err.Error()), MsgError)
+			}
+			a.entries = entries
+			a.filter()
+		})
+	}()
+}
+
+func (a *AuditViewer) vmName(e *vm.AuditEntry) string {
+	if e.VmUuid == "" {
+		return "-"
+	}
+	if a.server != nil && e.Server == a.server.AuditAddress() {
+		if v := Data.GetVm(a.server.UUID, e.VmUuid, true); v != nil {
+			return v.Name
+		}
+	}
+	return e.VmUuid
+}
+
+// newest first
+func (a *AuditViewer) filter() {
+	search := strings.ToLower(strings.TrimSpace(a.search.Text))
+	address := ""
+	if a.server != nil && a.thisServer.Checked {
+		address = a.server.AuditAddress()
+	}
+	a.filtered = a.filtered[:0]
+	for n := range slices.Backward(a.entries) {
+		e := &a.entries[n]
+		if address != "" && e.Server != address {
+			continue
+		}
+		if e.Background && !a.polling.Checked {
+			continue
+		}
+		if a.errorsOnly.Checked && e.Exit == 0 && e.Error == "" {
+			continue
+		}
+		if search != "" {
+			text := strings.ToLower(strings.Join([]string{e.Server, e.VmUuid, a.vmName(e), e.Cmd,
+				strings.Join(e.Args, " "), e.Error, e.Output}, "\n"))
+			if !strings.Contains(text, search) {
+				continue
+			}
+		}
+		a.filtered = append(a.filtered, e)
+	}
+	a.list.UnselectAll()
+	a.list.Refresh()
+	a.details.SetText("")
+}
+
+func (a *AuditViewer) format(e *vm.AuditEntry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s\n", lang.X("audit.time", "Time"), e.Time.Local().Format("2006-01-02 15:04:05.000"))
+	fmt.Fprintf(&b, "%s: %s\n", lang.X("audit.server", "Server"), e.Server)
+	if e.VmUuid != "" {
+		fmt.Fprintf(&b, "%s: %s (%s)\n", lang.X("audit.vm", "VM"), a.vmName(e), e.VmUuid)
+	}
+	fmt.Fprintf(&b, "%s: %s %s\n", lang.X("audit.command", "Command"), e.Cmd, strings.Join(e.Args, " "))
+	fmt.Fprintf(&b, "%s: %d ms\n", lang.X("audit.duration", "Duration"), e.Duration)
+	fmt.Fprintf(&b, "%s: %d\n", lang.X("audit.exit", "Exit status"), e.Exit)
+	if e.Error != "" {
+		fmt.Fprintf(&b, "%s: %s\n", lang.X("audit.error", "Error"), e.Error)
+	}
+	if e.Output != "" {
+		fmt.Fprintf(&b, "\n%s\n", e.Output)
+	}
+	return b.String()
+}
@@ -0,0 +1,302 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"bytemystery-com/vboxssh/util"
+	"bytemystery-com/vboxssh/vm"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	bandwidthKiB = 1024
+	bandwidthMiB = 1024 * 1024
+)
+
+// Loads the groups in the background - f is called in the ui thread
+func loadBandwidthGroups(s *vm.VmServer, v *vm.VMachine, f func(groups []vm.BandwidthGroup)) {
+	go func() {
+		groups, err := v.GetBandwidthGroups(&s.Client)
+		if err != nil {
+			SetStatusText(fmt.Sprintf(lang.X("bandwidth.list.error", "Reading bandwidth groups of VM '%s' failed with: %s"), v.Name, err.Error()), MsgError)
+			return
+		}
+		fyne.Do(func() {
+			f(groups)
+		})
+	}()
+}
+
+// Distributes the groups to the tabs which assign them - only if v is still active
+func setBandwidthGroups(v *vm.VMachine, groups []vm.BandwidthGroup) {
+	_, active := getActiveServerAndVm()
+	if active != v {
+		return
+	}
+	for _, n := range Gui.VmNetworkTabs {
+		n.setBandwidthGroups(groups)
+	}
+	Gui.VmStorageContent.setBandwidthGroups(groups)
+}
+
+// The first entry means no group
+func bandwidthGroupOptions(groups []vm.BandwidthGroup, t vm.BandwidthGroupType) []string {
+	list := []string{lang.X("bandwidth.none", "None")}
+	for _, g := range groups {
+		if g.Type == t {
+			list = append(list, g.Name)
+		}
+	}
+	return list
+}
+
+func selectBandwidthGroup(w *widget.Select, name string) {
+	if name == "" {
+		w.SetSelectedIndex(0)
+		return
+	}
+	w.SetSelected(name)
+}
+
+func selectedBandwidthGroup(w *widget.Select) string {
+	if w.SelectedIndex() <= 0 {
+		return ""
+	}
+	return w.Selected
+}
+
+func bandwidthTypeName(t vm.BandwidthGroupType) string {
+	if t == vm.BandwidthGroup_network {
+		return lang.X("bandwidth.type.network", "Network")
+	}
+	return lang.X("bandwidth.type.disk", "Disk")
+}
+
+func formatBandwidthLimit(limit int64) string {
+	switch {
+	case limit <= 0:
+		return lang.X("bandwidth.unlimited", "unlimited")
+	case limit%bandwidthMiB == 0:
+		return fmt.Sprintf("%d MB/s", limit/bandwidthMiB)
+	default:
+		return fmt.Sprintf("%d KB/s", (limit+bandwidthKiB-1)/bandwidthKiB)
+	}
+}
+
+type bandwidthDialog struct {
+	s         *vm.VmServer
+	v         *vm.VMachine
+	groups    []vm.BandwidthGroup
+	selected  int
+	list      *widget.List
+	add       *widget.Button
+	edit      *widget.Button
+	remove    *widget.Button
+	onChanged func(groups []vm.BandwidthGroup)
+}
+
+// Creates, changes and removes the bandwidth groups of a VM.
+// The limits of a running VM are changed live.
+func showBandwidthGroupsDialog(s *vm.VmServer, v *vm.VMachine, onChanged func(groups []vm.BandwidthGroup)) {
+	bw := bandwidthDialog{
+		s:         s,
+		v:         v,
+		selected:  -1,
+		onChanged: onChanged,
+	}
+	bw.list = widget.NewList(func() int {
+		return len(bw.groups)
+	}, func() fyne.CanvasObject {
+		return widget.NewLabel("")
+	}, func(id widget.ListItemID, o fyne.CanvasObject) {
+		label, ok := o.(*widget.Label)
+		if !ok || id >= len(bw.groups) {
+			return
+		}
+		g := bw.groups[id]
+		label.SetText(fmt.Sprintf("%s - %s - %s", g.Name, bandwidthTypeName(g.Type), formatBandwidthLimit(g.Limit)))
+	})
+	bw.list.OnSelected = func(id widget.ListItemID) {
+		bw.selected = id
+		bw.updateButtons()
+	}
+	bw.list.OnUnselected = func(id widget.ListItemID) {
+		bw.selected = -1
+		bw.updateButtons()
+	}
+	bw.add = widget.NewButtonWithIcon("", theme.ContentAddIcon(), func() {
+		bw.editGroup(nil)
+	})
+	bw.edit = widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), func() {
+		if bw.selected >= 0 && bw.selected < len(bw.groups) {
+			g := bw.groups[bw.selected]
+			bw.editGroup(&g)
+		}
+	})
+	bw.remove = widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		bw.removeGroup()
+	})
+	bw.updateButtons()
+
+	top := container.NewHBox(widget.NewLabel(fmt.Sprintf(lang.X("bandwidth.groups", "Bandwidth groups of '%s'"), v.Name)),
+		layout.NewSpacer(), bw.add, bw.edit, bw.remove)
+	dia := dialog.NewCustom(lang.X("bandwidth.title", "Bandwidth groups"), lang.X("bandwidth.close", "Close"),
+		container.NewBorder(top, nil, nil, nil, bw.list), Gui.MainWindow)
+	si := Gui.MainWindow.Canvas().Size()
+	dia.Resize(fyne.NewSize(si.Width*0.5, si.Height*0.5))
+	dia.Show()
+	bw.reload()
+}
+
+func (bw *bandwidthDialog) reload() {
+	loadBandwidthGroups(bw.s, bw.v, func(groups []vm.BandwidthGroup) {
+		bw.groups = groups
+		bw.selected = -1
+		bw.list.UnselectAll()
+		bw.list.Refresh()
+		bw.updateButtons()
+		if bw.onChanged != nil {
+			bw.onChanged(groups)
+		}
+	})
+}
+
+func (bw *bandwidthDialog) updateButtons() {
+	if bw.selected >= 0 && bw.selected < len(bw.groups) {
+		bw.edit.Enable()
+		bw.remove.Enable()
+	} else {
+		bw.edit.Disable()
+		bw.remove.Disable()
+	}
+}
+
+// nil adds a new group - an existing group only gets a new limit
+func (bw *bandwidthDialog) editGroup(old *vm.BandwidthGroup) {
+	name := widget.NewEntry()
+	groupType := widget.NewSelect([]string{bandwidthTypeName(vm.BandwidthGroup_disk), bandwidthTypeName(vm.BandwidthGroup_network)}, nil)
+	groupType.SetSelectedIndex(0)
+	limit := widget.NewEntry()
+	limit.OnChanged = util.GetNumberFilter(limit, nil)
+	limit.SetPlaceHolder(lang.X("bandwidth.unlimited", "unlimited"))
+	unit := widget.NewSelect([]string{"KB/s", "MB/s"}, nil)
+	unit.SetSelectedIndex(1)
+	if old != nil {
+		name.SetText(old.Name)
+		name.Disable()
+		if old.Type == vm.BandwidthGroup_network {
+			groupType.SetSelectedIndex(1)
+		}
+		groupType.Disable()
+		if old.Limit > 0 {
+			if old.Limit%bandwidthMiB == 0 {
+				limit.SetText(strconv.FormatInt(old.Limit/bandwidthMiB, 10))
+			} else {
+				unit.SetSelectedIndex(0)
+				limit.SetText(strconv.FormatInt((old.Limit+bandwidthKiB-1)/bandwidthKiB, 10))
+			}
+		}
+	}
+
+	c := container.New(layout.NewFormLayout(),
+		widget.NewLabel(lang.X("bandwidth.name", "Name")), name,
+		widget.NewLabel(lang.X("bandwidth.type", "Type")), groupType,
+		widget.NewLabel(lang.X("bandwidth.limit", "Limit")), container.NewBorder(nil, nil, nil, unit, limit),
+	)
+	title := lang.X("bandwidth.add.title", "Add bandwidth group")
+	if old != nil {
+		title = lang.X("bandwidth.edit.title", "Change bandwidth limit")
+	}
+	dia := dialog.NewCustomConfirm(title, lang.X("import.ok", "Ok"), lang.X("import.cancel", "Cancel"), c,
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			group := vm.BandwidthGroup{Name: name.Text}
+			if groupType.SelectedIndex() == 1 {
+				group.Type = vm.BandwidthGroup_network
+			}
+			val, _ := strconv.ParseInt(limit.Text, 10, 64)
+			if unit.SelectedIndex() == 0 {
+				group.Limit = val * bandwidthKiB
+			} else {
+				group.Limit = val * bandwidthMiB
+			}
+			go func() {
+				var err error
+				if old != nil {
+					err = bw.v.SetBandwidthLimit(&bw.s.Client, group.Name, group.Limit, VMStatusUpdateCallBack)
+					if err != nil {
+						SetStatusText(fmt.Sprintf(lang.X("bandwidth.set.error", "Changing the limit of bandwidth group '%s' of VM '%s' failed with: %s"), group.Name, bw.v.Name, err.Error()), MsgError)
+					}
+				} else {
+					err = bw.v.AddBandwidthGroup(&bw.s.Client, group, VMStatusUpdateCallBack)
+					if err != nil {
+						SetStatusText(fmt.Sprintf(lang.X("bandwidth.add.error", "Adding bandwidth group '%s' to VM '%s' failed with: %s"), group.Name, bw.v.Name, err.Error()), MsgError)
+					}
+				}
+				fyne.Do(bw.reload)
+			}()
+		}, Gui.MainWindow)
+	si := Gui.MainWindow.Canvas().Size()
+	dia.Resize(fyne.NewSize(si.Width*0.4, dia.MinSize().Height*1.1))
+	dia.Show()
+	if old != nil {
+		Gui.MainWindow.Canvas().Focus(limit)
+	} else {
+		Gui.MainWindow.Canvas().Focus(name)
+	}
+}
+
+func (bw *bandwidthDialog) removeGroup() {
+	if bw.selected < 0 || bw.selected >= len(bw.groups) {
+		return
+	}
+	group := bw.groups[bw.selected]
+	dialog.ShowConfirm(lang.X("bandwidth.remove.title", "Remove bandwidth group"),
+		fmt.Sprintf(lang.X("bandwidth.remove.msg", "Do you really want to remove the bandwidth group '%s' of '%s' ?"), group.Name, bw.v.Name),
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			go func() {
+				err := bw.v.RemoveBandwidthGroup(&bw.s.Client, group.Name, VMStatusUpdateCallBack)
+				if err != nil {
+					SetStatusText(fmt.Sprintf(lang.X("bandwidth.remove.error", "Removing bandwidth group '%s' of VM '%s' failed with: %s"), group.Name, bw.v.Name, err.Error()), MsgError)
+				}
+				fyne.Do(bw.reload)
+			}()
+		}, Gui.MainWindow)
+}
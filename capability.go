@@ -0,0 +1,118 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package main
+
+import (
+	"fmt"
+
+	"bytemystery-com/vboxssh/vm"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Small info icon next to an option not supported by the VirtualBox version
+// of the server - shows the reason as tooltip
+type CapabilityHint struct {
+	widget.BaseWidget
+	icon  *widget.Icon
+	text  string
+	popup *widget.PopUp
+}
+
+func NewCapabilityHint() *CapabilityHint {
+	h := &CapabilityHint{
+		icon: widget.NewIcon(theme.InfoIcon()),
+	}
+	h.ExtendBaseWidget(h)
+	h.Hide()
+	return h
+}
+
+func (h *CapabilityHint) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(h.icon)
+}
+
+// Shows the hint if the capability is missing - returns true if supported
+func (h *CapabilityHint) Update(s *vm.VmServer, capability vm.CapabilityType) bool {
+	if s == nil || s.HasCapability(capability) {
+		h.text = ""
+		h.hidePopup()
+		h.Hide()
+		return true
+	}
+	c := s.Capabilities()
+	h.text = fmt.Sprintf(lang.X("capability.unsupported", "Not supported by VirtualBox %s on this server - requires version %s or newer"),
+		c.Version.String(), c.Requires(capability).String())
+	h.Show()
+	return false
+}
+
+func (h *CapabilityHint) showPopup(pos fyne.Position) {
+	if h.text == "" || h.popup != nil {
+		return
+	}
+	h.popup = widget.NewPopUp(container.NewPadded(widget.NewLabel(h.text)), Gui.MainWindow.Canvas())
+	h.popup.ShowAtPosition(pos.AddXY(theme.Padding()*2, theme.Padding()*2))
+}
+
+func (h *CapabilityHint) hidePopup() {
+	if h.popup != nil {
+		h.popup.Hide()
+		h.popup = nil
+	}
+}
+
+func (h *CapabilityHint) MouseIn(e *desktop.MouseEvent) {
+	h.showPopup(e.AbsolutePosition)
+}
+
+func (h *CapabilityHint) MouseMoved(e *desktop.MouseEvent) {
+}
+
+func (h *CapabilityHint) MouseOut() {
+	h.hidePopup()
+}
+
+func (h *CapabilityHint) Tapped(e *fyne.PointEvent) {
+	if h.popup != nil {
+		h.hidePopup()
+	} else {
+		h.showPopup(e.AbsolutePosition)
+	}
+}
+
+// Disables w if the capability is missing - returns true if supported
+func enableByCapability(w fyne.Disableable, h *CapabilityHint, s *vm.VmServer, capability vm.CapabilityType) bool {
+	if h.Update(s, capability) {
+		return true
+	}
+	w.Disable()
+	return false
+}
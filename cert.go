@@ -0,0 +1,94 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"bytemystery-com/vboxssh/server"
+	"bytemystery-com/vboxssh/vm"
+
+	"fyne.io/fyne/v2/lang"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	CERT_CHECK_INTERVAL = 5 * time.Minute
+)
+
+// certificates (server + serial) which were already reported
+var certWarned sync.Map
+
+func formatCertInfo(cert *ssh.Certificate) string {
+	after, before := server.CertValidity(cert)
+	from := lang.X("details.srvssh.cert_always", "always")
+	if !after.IsZero() {
+		from = after.Format(time.DateTime)
+	}
+	to := lang.X("details.srvssh.cert_forever", "forever")
+	if !before.IsZero() {
+		to = before.Format(time.DateTime)
+	}
+	principals := strings.Join(cert.ValidPrincipals, ", ")
+	if principals == "" {
+		principals = lang.X("details.srvssh.cert_any", "any")
+	}
+	return fmt.Sprintf(lang.X("details.srvssh.cert_info", "Principals: %s - valid from %s to %s"), principals, from, to)
+}
+
+func checkCertExpiry(s *vm.VmServer) {
+	cert, err := s.GetCertificate()
+	if err != nil || !server.CertExpiresSoon(cert, time.Now()) {
+		return
+	}
+	key := fmt.Sprintf("%s-%d", s.UUID, cert.Serial)
+	if _, ok := certWarned.LoadOrStore(key, true); ok {
+		return
+	}
+	_, before := server.CertValidity(cert)
+	var msg string
+	if before.Before(time.Now()) {
+		msg = fmt.Sprintf(lang.X("cert.expired", "The SSH certificate for server '%s' has expired (%s)."), s.Name, before.Format(time.DateTime))
+		SetStatusText(msg, MsgError)
+	} else {
+		msg = fmt.Sprintf(lang.X("cert.expires", "The SSH certificate for server '%s' expires at %s."), s.Name, before.Format(time.DateTime))
+		SetStatusText(msg, MsgWarning)
+	}
+	SendNotification(lang.X("cert.title", "SSH certificate"), msg)
+}
+
+func certExpiryTimerProc() {
+	for {
+		for _, s := range Data.GetServers(true) {
+			if !s.IsLocal() {
+				checkCertExpiry(s)
+			}
+		}
+		time.Sleep(CERT_CHECK_INTERVAL)
+	}
+}
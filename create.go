@@ -25,9 +25,9 @@
 package main
 
 import (
+	"context"
 	"fmt"
 
-	"bytemystery-com/vboxssh/util"
 	"bytemystery-com/vboxssh/vm"
 
 	"fyne.io/fyne/v2"
@@ -52,10 +52,22 @@ func doCreateVm() {
 	name.SetPlaceHolder(lang.X("create.name.placeholder", "Name of the new VM"))
 	item := widget.NewFormItem(lang.X("create.name", "Name"), name)
 
+	archMapIndexToType := map[int]vm.PlatformArchType{0: vm.PlatformArch_default, 1: vm.PlatformArch_x86, 2: vm.PlatformArch_arm}
+	archSelect := widget.NewSelect([]string{
+		lang.X("create.arch.default", "Default"),
+		lang.X("create.arch.x86", "x86"),
+		lang.X("create.arch.arm", "ARM"),
+	}, nil)
+	archSelect.SetSelectedIndex(0)
+	archHint := NewCapabilityHint()
+	enableByCapability(archSelect, archHint, s, vm.Capability_platformArch)
+	itemArch := widget.NewFormItem(lang.X("create.arch", "Architecture"), container.NewBorder(nil, nil, nil, archHint, archSelect))
+
 	dia := dialog.NewForm(lang.X("create.title", "Create new VM"),
 		lang.X("create.create", "Create"),
-		lang.X("create.cancel", "Cancel"), []*widget.FormItem{item}, func(ok bool) {
-			err := s.CreateVm(&s.Client, name.Text)
+		lang.X("create.cancel", "Cancel"), []*widget.FormItem{item, itemArch}, func(ok bool) {
+			arch := archMapIndexToType[archSelect.SelectedIndex()]
+			err := s.CreateVm(&s.Client, name.Text, arch)
 			if err != nil {
 				SetStatusText(fmt.Sprintf(lang.X("create.failed", "Creating VM width name '%s' failed"), name.Text), MsgError)
 			} else {
@@ -166,21 +178,20 @@ func doCloneVm() {
 				go func() {
 					uuid := uuid.NewString()
 					tname := fmt.Sprintf(lang.X("clone.msg", "Clone of '%s'"), m.Name)
-					Gui.TasksInfos.AddTask(uuid, tname, "")
+					ctx, cancel := context.WithCancel(context.Background())
+					defer cancel()
+					Gui.TasksInfos.AddTask(uuid, tname, "", cancel)
 					OpenTaskDetails()
 					ResetStatus()
 					snapshotname := fmt.Sprintf("Linked base for %s and %s", m.Name, name.Text)
 					var err, errSnap error
 					if linkMap[cloneType.SelectedIndex()] == vm.CloneOption_link {
-						err = m.TakeSnapshot(&s.Client, snapshotname, "", false, nil)
+						err = m.TakeSnapshot(s.Client.WithContext(ctx), snapshotname, "", false, nil)
 						errSnap = err
 					}
 					if err == nil {
-						err = m.CloneVm(s, name.Text, cloneModeMap[cloneModeType.SelectedIndex()], linkMap[cloneType.SelectedIndex()],
-							macMap[mac.SelectedIndex()], diskMap[disk.Checked], hwIdMap[hwuuid.Checked], snapshotname, util.WriterFunc(func(p []byte) (int, error) {
-								Gui.TasksInfos.UpdateTaskStatus(uuid, string(p), true)
-								return len(p), nil
-							}))
+						err = m.CloneVm(s.Client.WithContext(ctx), name.Text, cloneModeMap[cloneModeType.SelectedIndex()], linkMap[cloneType.SelectedIndex()],
+							macMap[mac.SelectedIndex()], diskMap[disk.Checked], hwIdMap[hwuuid.Checked], snapshotname, Gui.TasksInfos.NewProgressWriter(uuid))
 					}
 					if err != nil {
 						t := fmt.Sprintf(lang.X("clone.done.error", "Clone of '%s' failed"), m.Name)
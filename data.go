@@ -30,6 +30,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"bytemystery-com/vboxssh/crypt"
 
@@ -52,12 +53,22 @@ func saveServers(servers []*vm.VmServer, masterKey string) error {
 			DvdImagesPath:    ss.DvdImagesPath,
 			HddImagesPath:    ss.HddImagesPath,
 			OvaPath:          ss.OvaPath,
+			CmdConfig:        ss.CmdConfig,
 		}
 		x, err := crypt.Encrypt(pass, s.Password)
 		if err != nil {
 			return err
 		}
 		s.Password = x
+		// own slice - the passwords of the running servers must stay plain
+		s.JumpHosts = slices.Clone(ss.JumpHosts)
+		for n := range s.JumpHosts {
+			x, err := crypt.Encrypt(pass, s.JumpHosts[n].Password)
+			if err != nil {
+				return err
+			}
+			s.JumpHosts[n].Password = x
+		}
 		list = append(list, s)
 	}
 	b, err := json.Marshal(list)
@@ -120,8 +131,18 @@ func loadServers(masterKey string) ([]vm.VmServer, error) {
 			list[i].Password = ""
 			fmt.Println("!!! Unable to decrypt !!!")
 		}
+		for n := range list[i].JumpHosts {
+			x, err := crypt.Decrypt(pass, list[i].JumpHosts[n].Password)
+			if err == nil {
+				list[i].JumpHosts[n].Password = x
+			} else {
+				list[i].JumpHosts[n].Password = ""
+			}
+		}
 		list[i].KeyFileReader = readKeyFile
 		list[i].HostFileReader = readKeyFile
+		list[i].HostKeyConfirm = confirmHostKey
+		list[i].HostKeyUpdated = SaveServers
 	}
 	SetStatusText(fmt.Sprintf(lang.X("data.serverlist.loaded", "Server list with %d entries was loaded"), len(list)), MsgInfo)
 
@@ -131,3 +152,60 @@ func loadServers(masterKey string) ([]vm.VmServer, error) {
 func SaveServers() {
 	saveServers(Data.GetServers(true), Gui.MasterPassword)
 }
+
+// the passwords are only encrypted again if they were changed
+func saveGuestConsoleSettings(settings map[string]*GuestConsoleSettings, masterKey string) error {
+	pass, err := crypt.Decrypt(crypt.InternPassword, masterKey)
+	if err != nil {
+		return err
+	}
+	list := make(map[string]GuestConsoleSettings, len(settings))
+	for uuid, item := range settings {
+		if item.encrypted == "" || item.encryptedOf != item.Password {
+			x, err := crypt.Encrypt(pass, item.Password)
+			if err != nil {
+				return err
+			}
+			item.encrypted = x
+			item.encryptedOf = item.Password
+		}
+		s := *item
+		s.Password = item.encrypted
+		list[uuid] = s
+	}
+	b, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	Gui.Settings.GuestConsole = string(b)
+	Gui.Settings.Store()
+	return nil
+}
+
+func loadGuestConsoleSettings(masterKey string) (map[string]*GuestConsoleSettings, error) {
+	settings := make(map[string]*GuestConsoleSettings)
+	pass, err := crypt.Decrypt(crypt.InternPassword, masterKey)
+	if err != nil {
+		return settings, err
+	}
+	if Gui.Settings.GuestConsole == "" {
+		return settings, nil
+	}
+	var list map[string]GuestConsoleSettings
+	err = json.Unmarshal([]byte(Gui.Settings.GuestConsole), &list)
+	if err != nil {
+		return settings, err
+	}
+	for uuid, item := range list {
+		x, err := crypt.Decrypt(pass, item.Password)
+		if err == nil {
+			item.encrypted = item.Password
+			item.Password = x
+			item.encryptedOf = x
+		} else {
+			item.Password = ""
+		}
+		settings[uuid] = &item
+	}
+	return settings, nil
+}
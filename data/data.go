@@ -62,6 +62,7 @@ func (v *VmData) LoadData(servers []vm.VmServer) {
 		vmNew.DvdImagesPath = item.DvdImagesPath
 		vmNew.HddImagesPath = item.HddImagesPath
 		vmNew.OvaPath = item.OvaPath
+		vmNew.SetCmdConfig(item.CmdConfig)
 		v.ServerList.Add(vmNew.UUID, &vmNew)
 		m := omap.NewOMap[string, *vm.VMachine](DEFAULT_NUMBER_OF_VMS_PER_SERVER)
 		v.VmList[vmNew.UUID] = &m
@@ -29,9 +29,11 @@ import (
 	"image/color"
 	"io"
 	"strconv"
+	"time"
 
 	"bytemystery-com/vboxssh/util"
 
+	"bytemystery-com/vboxssh/run"
 	"bytemystery-com/vboxssh/server"
 	"bytemystery-com/vboxssh/vm"
 
@@ -57,11 +59,20 @@ type ServerSshInfos struct {
 	user          *widget.Entry
 	host          *widget.Entry
 	port          *widget.Entry
+	maxSessions   *widget.Entry
 	pass          *widget.Entry
 	keyFile       *widget.Entry
 	keyFileBrowse *widget.Button
+	certFile      *widget.Entry
+	certBrowse    *widget.Button
+	certInfo      *widget.Label
 	apply         *widget.Button
 	hostKeyList   *widget.List
+	hostKey       *widget.Label
+	useAgent      *widget.Check
+	agentForward  *widget.Check
+	hostKeyForget *widget.Button
+	jumpHosts     *JumpHostEditor
 
 	tabItem *container.TabItem
 
@@ -84,18 +95,39 @@ func NewSshServerTab() *ServerSshInfos {
 	srv.port = widget.NewEntry()
 	srv.port.SetPlaceHolder(lang.X("details.srvssh.port_placeholder", "SSH port (22)"))
 	srv.port.OnChanged = util.GetNumberFilter(srv.port, nil)
+	srv.maxSessions = widget.NewEntry()
+	srv.maxSessions.SetPlaceHolder(fmt.Sprintf(lang.X("details.srvssh.maxsessions_placeholder", "Max. parallel sessions (%d)"), run.DEFAULT_MAX_SESSIONS))
+	srv.maxSessions.OnChanged = util.GetNumberFilter(srv.maxSessions, nil)
 
 	srv.pass = widget.NewPasswordEntry()
 	srv.pass.SetPlaceHolder(lang.X("details.srvssh.pass_placeholder", "SSH / key password"))
 	srv.keyFile = widget.NewEntry()
 	srv.keyFile.SetPlaceHolder(lang.X("details.srvssh.keyfile_placeholder", "SSH keyfile"))
 	srv.keyFileBrowse = widget.NewButton(lang.X("details.srvssh.browse", "Browse"), func() {
-		srv.browseKeyFile()
+		srv.browseFile(srv.keyFile)
 	})
+	srv.certFile = widget.NewEntry()
+	srv.certFile.SetPlaceHolder(lang.X("details.srvssh.certfile_placeholder", "SSH user certificate (*-cert.pub)"))
+	srv.certBrowse = widget.NewButton(lang.X("details.srvssh.browse", "Browse"), func() {
+		srv.browseFile(srv.certFile)
+	})
+	srv.certInfo = widget.NewLabel("")
+	srv.certInfo.Truncation = fyne.TextTruncateEllipsis
 	srv.apply = widget.NewButton(lang.X("details.srvssh.apply", "Apply"), func() {
 		srv.Apply()
 	})
 	srv.apply.Importance = widget.HighImportance
+	srv.useAgent = widget.NewCheck(lang.X("details.srvssh.useagent", "Use SSH agent"), func(b bool) {
+		if b && !server.IsAgentAvailable() {
+			SetStatusText(fmt.Sprintf(lang.X("status.server_agent_missing", "No SSH agent found (%s)."), server.AGENT_SOCK_ENV), MsgWarning)
+		}
+	})
+	srv.agentForward = widget.NewCheck(lang.X("details.srvssh.agentforward", "Agent forwarding"), nil)
+	srv.hostKey = widget.NewLabel("")
+	srv.hostKey.Truncation = fyne.TextTruncateEllipsis
+	srv.hostKeyForget = widget.NewButton(lang.X("details.srvssh.hostkey_forget", "Forget host key"), func() {
+		srv.forgetHostKey()
+	})
 
 	formWidth := util.GetFormWidth() / 2
 	labelWidth := util.GetDefaultTextWidth("XXXXXXXXXX")
@@ -110,7 +142,7 @@ func NewSshServerTab() *ServerSshInfos {
 		widget.NewLabel(lang.X("details.srvssh.user", "User")), srv.user)
 
 	grid2 := container.New(layout.NewFormLayout(),
-		dummy, dummy,
+		widget.NewLabel(lang.X("details.srvssh.maxsessions", "Sessions")), srv.maxSessions,
 		widget.NewLabel(lang.X("details.srvssh.port", "Port")), srv.port,
 		widget.NewLabel(lang.X("details.srvssh.password", "Password")), srv.pass)
 
@@ -119,6 +151,17 @@ func NewSshServerTab() *ServerSshInfos {
 			widget.NewLabel(lang.X("details.srvssh.keyfile", "Keyfile"))), srv.keyFile,
 	)
 
+	grid5 := container.New(layout.NewFormLayout(),
+		container.NewGridWrap(fyne.NewSize(labelWidth, 1),
+			widget.NewLabel(lang.X("details.srvssh.certfile", "Certificate"))), srv.certFile,
+		widget.NewLabel(""), srv.certInfo,
+	)
+
+	grid4 := container.New(layout.NewFormLayout(),
+		container.NewGridWrap(fyne.NewSize(labelWidth, 1),
+			widget.NewLabel(lang.X("details.srvssh.hostkey", "Host key"))), srv.hostKey,
+	)
+
 	srv.hostKeyList = widget.NewList(srv.listLength, srv.listCreate, srv.listUpdate)
 	srv.selectedHostFileIndex = -1
 	srv.hostKeyList.OnSelected = func(id widget.ListItemID) {
@@ -131,6 +174,8 @@ func NewSshServerTab() *ServerSshInfos {
 	i1 := container.NewGridWrap(fyne.NewSize(formWidth, grid1.MinSize().Height), grid1)
 	i2 := container.NewGridWrap(fyne.NewSize(formWidth, grid2.MinSize().Height), grid2)
 	i3 := container.NewGridWrap(fyne.NewSize(2*formWidth, grid3.MinSize().Height), grid3)
+	i4 := container.NewGridWrap(fyne.NewSize(2*formWidth, grid4.MinSize().Height), grid4)
+	i5 := container.NewGridWrap(fyne.NewSize(2*formWidth, grid5.MinSize().Height), grid5)
 
 	toolItemAdd := widget.NewToolbarAction(theme.ContentAddIcon(), func() {
 		diaHost := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
@@ -165,11 +210,18 @@ func NewSshServerTab() *ServerSshInfos {
 	toolBar := widget.NewToolbar(toolItemAdd, toolItemDel)
 
 	content := container.NewVBox(util.NewVFiller(0.5), container.NewHBox(i1, i2),
-		container.NewHBox(i3, srv.keyFileBrowse))
+		container.NewHBox(i3, srv.keyFileBrowse),
+		container.NewHBox(i5, container.NewVBox(srv.certBrowse)),
+		container.NewHBox(util.NewFiller(labelWidth, 0), srv.useAgent, srv.agentForward),
+		container.NewHBox(i4, srv.hostKeyForget))
+
+	srv.jumpHosts = NewJumpHostEditor()
+	hostFiles := container.NewBorder(container.NewVBox(widget.NewLabel(lang.X("details.srvssh.hostfiles", "Host key files:")), toolBar),
+		nil, nil, nil, srv.hostKeyList)
 
-	cl := container.NewBorder(container.NewVBox(content, widget.NewLabel(lang.X("details.srvssh.hostfiles", "Host key files:")), toolBar),
+	cl := container.NewBorder(content,
 		container.NewVBox(container.NewHBox(layout.NewSpacer(), srv.apply, util.NewFiller(32, 0)),
-			util.NewFiller(0, 16)), nil, nil, srv.hostKeyList)
+			util.NewFiller(0, 16)), nil, nil, container.NewGridWithColumns(2, hostFiles, srv.jumpHosts.content))
 
 	srv.tabItem = container.NewTabItem(lang.X("details.vm_info.tab.ssh", "SSH"), cl)
 
@@ -216,14 +268,25 @@ func (srv *ServerSshInfos) UpdateBySelect() {
 	srv.host.SetText(s.Host)
 	srv.user.SetText(s.User)
 	srv.port.SetText(strconv.Itoa(s.Port))
+	if s.MaxSessions > 0 {
+		srv.maxSessions.SetText(strconv.Itoa(s.MaxSessions))
+	} else {
+		srv.maxSessions.SetText("")
+	}
 	srv.pass.SetText(s.Password)
 	srv.keyFile.SetText(s.KeyFile)
+	srv.certFile.SetText(s.CertFile)
+	srv.updateCertInfo(s)
+	srv.useAgent.SetChecked(s.UseAgent)
+	srv.agentForward.SetChecked(s.AgentForward)
+	srv.updateHostKey(s)
 
 	srv.hostFiles = make([]string, 0, len(s.HostFiles))
 	for _, item := range s.HostFiles {
 		srv.hostFiles = append(srv.hostFiles, item)
 	}
 	srv.hostKeyList.Refresh()
+	srv.jumpHosts.Set(s.JumpHosts)
 }
 
 func (srv *ServerSshInfos) reset() {
@@ -232,12 +295,79 @@ func (srv *ServerSshInfos) reset() {
 	srv.pass.SetText("")
 	srv.host.SetText("")
 	srv.port.SetText("")
+	srv.maxSessions.SetText("")
 	srv.keyFile.SetText("")
+	srv.certFile.SetText("")
+	srv.updateCertInfo(nil)
+	srv.useAgent.SetChecked(false)
+	srv.agentForward.SetChecked(false)
+	srv.updateHostKey(nil)
 	srv.hostFiles = srv.hostFiles[:0]
 	srv.hostKeyList.Refresh()
+	srv.jumpHosts.Set(nil)
 }
 
-func (srv *ServerSshInfos) browseKeyFile() {
+func (srv *ServerSshInfos) updateHostKey(s *vm.VmServer) {
+	if s == nil || s.HostKey == "" {
+		srv.hostKey.SetText(lang.X("details.srvssh.hostkey_unknown", "not known yet"))
+		srv.hostKeyForget.Disable()
+		return
+	}
+	key, err := s.GetHostKey()
+	if err != nil {
+		srv.hostKey.SetText(err.Error())
+	} else {
+		srv.hostKey.SetText(server.FingerprintKey(key))
+	}
+	srv.hostKeyForget.Enable()
+}
+
+// Key rotation - the next connect asks for the new key
+func (srv *ServerSshInfos) forgetHostKey() {
+	s := Data.GetServer(Gui.ActiveItemServer, true)
+	if s == nil {
+		return
+	}
+	dialog.ShowConfirm(lang.X("details.srvssh.hostkey_forget.title", "Forget host key"),
+		fmt.Sprintf(lang.X("details.srvssh.hostkey_forget.msg", "Forget the host key of server '%s' ?\nThe entries in known_hosts will be removed as well\nand the next connect asks for the new key."), s.Name),
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			err := s.ForgetHostKey()
+			if err != nil {
+				SetStatusText(fmt.Sprintf(lang.X("status.server_hostkey_forget_error", "Unable to remove the host key of server '%s' from known_hosts. (%s)"), s.Name, err.Error()), MsgError)
+			} else {
+				SetStatusText(fmt.Sprintf(lang.X("status.server_hostkey_forget_ok", "Host key of server '%s' was removed."), s.Name), MsgInfo)
+			}
+			srv.updateHostKey(s)
+		}, Gui.MainWindow)
+}
+
+func (srv *ServerSshInfos) updateCertInfo(s *vm.VmServer) {
+	srv.certInfo.Importance = widget.MediumImportance
+	if s == nil {
+		srv.certInfo.SetText("")
+		return
+	}
+	cert, err := s.GetCertificate()
+	if err != nil {
+		if s.CertFile != "" || len(s.CertFileContent) > 0 {
+			srv.certInfo.Importance = widget.DangerImportance
+			srv.certInfo.SetText(err.Error())
+		} else {
+			srv.certInfo.SetText(lang.X("details.srvssh.cert_none", "no certificate"))
+		}
+		return
+	}
+	srv.certInfo.SetText(formatCertInfo(cert))
+	if server.CertExpiresSoon(cert, time.Now()) {
+		srv.certInfo.Importance = widget.WarningImportance
+	}
+	srv.certInfo.Refresh()
+}
+
+func (srv *ServerSshInfos) browseFile(entry *widget.Entry) {
 	dia := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
 		if err != nil {
 			return
@@ -246,10 +376,10 @@ func (srv *ServerSshInfos) browseKeyFile() {
 			return
 		}
 		r.Close()
-		srv.keyFile.SetText(r.URI().String())
+		entry.SetText(r.URI().String())
 	}, Gui.MainWindow)
 
-	u, err := storage.ParseURI(srv.keyFile.Text)
+	u, err := storage.ParseURI(entry.Text)
 	if err == nil {
 		parent, err := storage.Parent(u)
 		if err != nil {
@@ -269,6 +399,15 @@ func (srv *ServerSshInfos) browseKeyFile() {
 	dia.Show()
 }
 
+// 0 = default
+func (srv *ServerSshInfos) getMaxSessions() int {
+	n, err := strconv.Atoi(srv.maxSessions.Text)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
 func (srv *ServerSshInfos) add() {
 	var p int
 	var err error
@@ -279,13 +418,21 @@ func (srv *ServerSshInfos) add() {
 		return
 	}
 	s := server.Server{
-		Port:          p,
-		Name:          srv.name.Text,
-		Host:          srv.host.Text,
-		User:          srv.user.Text,
-		Password:      srv.pass.Text,
-		KeyFile:       srv.keyFile.Text,
-		KeyFileReader: readKeyFile,
+		Port:           p,
+		Name:           srv.name.Text,
+		Host:           srv.host.Text,
+		User:           srv.user.Text,
+		Password:       srv.pass.Text,
+		KeyFile:        srv.keyFile.Text,
+		CertFile:       srv.certFile.Text,
+		UseAgent:       srv.useAgent.Checked,
+		AgentForward:   srv.agentForward.Checked,
+		KeyFileReader:  readKeyFile,
+		HostFileReader: readKeyFile,
+		HostKeyConfirm: confirmHostKey,
+		HostKeyUpdated: SaveServers,
+		JumpHosts:      srv.jumpHosts.Get(),
+		MaxSessions:    srv.getMaxSessions(),
 	}
 	var vms *vm.VmServer
 	vms = Data.AddData(s, func() {
@@ -318,19 +465,28 @@ func (srv *ServerSshInfos) Apply() {
 		if err != nil {
 			return
 		}
+		// the stored host key belongs to the old address
+		if s.Host != srv.host.Text || s.Port != p {
+			s.HostKey = ""
+		}
 		s.Port = p
 		s.Name = srv.name.Text
 		s.Host = srv.host.Text
 		s.User = srv.user.Text
 		s.Password = srv.pass.Text
 		s.KeyFile = srv.keyFile.Text
+		s.CertFile = srv.certFile.Text
+		s.UseAgent = srv.useAgent.Checked
+		s.AgentForward = srv.agentForward.Checked
+		s.SetMaxSessions(srv.getMaxSessions())
 		s.HostFiles = make([]string, len(srv.hostFiles))
 		copy(s.HostFiles, srv.hostFiles)
+		s.JumpHosts = srv.jumpHosts.Get()
 		Gui.Tree.Refresh()
 		SaveServers()
 		SetStatusText(fmt.Sprintf(lang.X("status.server_add_ok", "Server '%s' where added."), s.Name), MsgInfo)
 		s.Disonnect(&s.Client.Client)
-		s.Connect(func() {
+		go s.Connect(func() {
 			setAfterConnectStatus(s, nil)
 		}, func(err error) {
 			setAfterConnectStatus(s, err)
@@ -355,9 +511,15 @@ func (srv *ServerSshInfos) DisableAll() {
 	srv.user.Disable()
 	srv.host.Disable()
 	srv.port.Disable()
+	srv.maxSessions.Disable()
 	srv.pass.Disable()
 	srv.keyFile.Disable()
 	srv.keyFileBrowse.Disable()
+	srv.certFile.Disable()
+	srv.certBrowse.Disable()
+	srv.useAgent.Disable()
+	srv.agentForward.Disable()
+	srv.hostKeyForget.Disable()
 	srv.apply.Disable()
 }
 
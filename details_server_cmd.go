@@ -0,0 +1,284 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"bytemystery-com/vboxssh/util"
+	"bytemystery-com/vboxssh/vm"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+type ServerCmdInfos struct {
+	vboxManage *widget.Entry
+	prefix     *widget.Select
+	prefixUser *widget.Entry
+	env        *widget.Entry
+	shell      *widget.Check
+	apply      *widget.Button
+
+	transcript       *widget.Select
+	transcriptFile   *widget.Entry
+	transcriptBrowse *widget.Button
+
+	tabItem *container.TabItem
+}
+
+var _ DetailsInterface = (*ServerCmdInfos)(nil)
+
+func NewServerCmdTab() *ServerCmdInfos {
+	srv := ServerCmdInfos{}
+
+	srv.vboxManage = widget.NewEntry()
+	srv.vboxManage.SetPlaceHolder(fmt.Sprintf(lang.X("details.srvcmd.vboxmanage_placeholder", "Path of VBoxManage (%s)"), vm.VBOXMANAGE_APP))
+	// same order as vm.PrefixType
+	srv.prefix = widget.NewSelect([]string{lang.X("details.srvcmd.prefix.none", "None"), "sudo", "doas"}, func(s string) {
+		if vm.PrefixType(srv.prefix.SelectedIndex()) == vm.Prefix_none {
+			srv.prefixUser.Disable()
+		} else {
+			srv.prefixUser.Enable()
+		}
+	})
+	prefixHint := widget.NewLabel(lang.X("details.srvcmd.prefix_hint",
+		"sudo gets the environment as VAR=value - sudoers must allow VBoxManage and the variables (env_keep or SETENV). doas runs env(1) - doas.conf must allow env."))
+	prefixHint.Wrapping = fyne.TextWrapWord
+	prefixHint.Importance = widget.LowImportance
+	prefixHint.SizeName = theme.SizeNameCaptionText
+	srv.prefixUser = widget.NewEntry()
+	srv.prefixUser.SetPlaceHolder(lang.X("details.srvcmd.prefixuser_placeholder", "Run as user (root)"))
+	srv.env = widget.NewMultiLineEntry()
+	srv.env.SetPlaceHolder(lang.X("details.srvcmd.env_placeholder", "KEY=value - one per line"))
+	srv.env.SetMinRowsVisible(5)
+	srv.shell = widget.NewCheck(lang.X("details.srvcmd.shell", "Persistent shell (lower latency, SSH only)"), nil)
+	// same order as vm.ExecutorType
+	srv.transcript = widget.NewSelect([]string{
+		lang.X("details.srvcmd.transcript.live", "Live"),
+		lang.X("details.srvcmd.transcript.record", "Record to transcript"),
+		lang.X("details.srvcmd.transcript.replay", "Replay transcript"),
+	}, func(s string) {
+		if vm.ExecutorType(srv.transcript.SelectedIndex()) == vm.Executor_live {
+			srv.transcriptFile.Disable()
+			srv.transcriptBrowse.Disable()
+		} else {
+			srv.transcriptFile.Enable()
+			srv.transcriptBrowse.Enable()
+		}
+	})
+	srv.transcriptFile = widget.NewEntry()
+	srv.transcriptFile.SetPlaceHolder(lang.X("details.srvcmd.transcriptfile_placeholder", "Transcript file (JSON lines)"))
+	srv.transcriptBrowse = widget.NewButton(lang.X("details.srvcmd.browse", "Browse"), func() {
+		srv.browseTranscript()
+	})
+	srv.apply = widget.NewButton(lang.X("details.srvcmd.apply", "Apply"), func() {
+		srv.Apply()
+	})
+	srv.apply.Importance = widget.HighImportance
+
+	formWidth := util.GetFormWidth()
+	labelWidth := util.GetDefaultTextWidth("XXXXXXXXXX")
+
+	grid := container.New(layout.NewFormLayout(),
+		container.NewGridWrap(fyne.NewSize(labelWidth, 1),
+			widget.NewLabel(lang.X("details.srvcmd.vboxmanage", "VBoxManage"))), srv.vboxManage,
+		widget.NewLabel(lang.X("details.srvcmd.prefix", "Prefix")), srv.prefix,
+		widget.NewLabel(""), prefixHint,
+		widget.NewLabel(lang.X("details.srvcmd.prefixuser", "Target user")), srv.prefixUser,
+		widget.NewLabel(lang.X("details.srvcmd.env", "Environment")), srv.env,
+		widget.NewLabel(""), srv.shell,
+		widget.NewLabel(lang.X("details.srvcmd.transcript", "Execution")), srv.transcript,
+		widget.NewLabel(lang.X("details.srvcmd.transcriptfile", "Transcript")),
+		container.NewBorder(nil, nil, nil, srv.transcriptBrowse, srv.transcriptFile),
+	)
+
+	content := container.NewVBox(util.NewVFiller(0.5),
+		container.NewGridWrap(fyne.NewSize(formWidth, grid.MinSize().Height), grid))
+
+	cl := container.NewBorder(content,
+		container.NewVBox(container.NewHBox(layout.NewSpacer(), srv.apply, util.NewFiller(32, 0)),
+			util.NewFiller(0, 16)), nil, nil)
+
+	srv.tabItem = container.NewTabItem(lang.X("details.vm_info.tab.cmd", "Commands"), cl)
+
+	return &srv
+}
+
+func (srv *ServerCmdInfos) UpdateBySelect() {
+	s := Data.GetServer(Gui.ActiveItemServer, true)
+	if s == nil {
+		srv.vboxManage.SetText("")
+		srv.prefix.SetSelectedIndex(int(vm.Prefix_none))
+		srv.prefixUser.SetText("")
+		srv.env.SetText("")
+		srv.shell.SetChecked(false)
+		srv.transcript.SetSelectedIndex(int(vm.Executor_live))
+		srv.transcriptFile.SetText("")
+		srv.DisableAll()
+		return
+	}
+	srv.vboxManage.Enable()
+	srv.prefix.Enable()
+	srv.env.Enable()
+	if s.IsLocal() {
+		srv.shell.Disable()
+	} else {
+		srv.shell.Enable()
+	}
+	srv.apply.Enable()
+	srv.vboxManage.SetText(s.CmdConfig.VBoxManage)
+	srv.prefixUser.SetText(s.CmdConfig.PrefixUser)
+	srv.prefix.SetSelectedIndex(int(s.CmdConfig.Prefix))
+	if s.CmdConfig.Prefix == vm.Prefix_none {
+		srv.prefixUser.Disable()
+	} else {
+		srv.prefixUser.Enable()
+	}
+	srv.env.SetText(strings.Join(s.CmdConfig.Env, "\n"))
+	srv.shell.SetChecked(s.CmdConfig.PersistentShell)
+
+	srv.transcript.Enable()
+	e := s.GetExecutor()
+	srv.transcript.SetSelectedIndex(int(e.Type()))
+	srv.transcriptFile.SetText(vm.ExecutorFile(e))
+	if e.Type() == vm.Executor_live {
+		srv.transcriptFile.Disable()
+		srv.transcriptBrowse.Disable()
+	} else {
+		srv.transcriptFile.Enable()
+		srv.transcriptBrowse.Enable()
+	}
+}
+
+func (srv *ServerCmdInfos) Apply() {
+	s := Data.GetServer(Gui.ActiveItemServer, true)
+	if s == nil {
+		return
+	}
+	c := vm.CmdConfig{
+		VBoxManage:      strings.TrimSpace(srv.vboxManage.Text),
+		Prefix:          vm.PrefixType(max(srv.prefix.SelectedIndex(), 0)),
+		PrefixUser:      strings.TrimSpace(srv.prefixUser.Text),
+		PersistentShell: srv.shell.Checked,
+	}
+	for _, line := range strings.Split(srv.env.Text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if k, _, ok := strings.Cut(line, "="); !ok || k == "" {
+			SetStatusText(fmt.Sprintf(lang.X("details.srvcmd.env_invalid", "Invalid environment entry '%s' (KEY=value)."), line), MsgError)
+			return
+		}
+		c.Env = append(c.Env, line)
+	}
+	if !srv.applyTranscript(s) {
+		return
+	}
+	s.SetCmdConfig(c)
+	SaveServers()
+	SetStatusText(fmt.Sprintf(lang.X("details.srvcmd.apply_ok", "Command settings of server '%s' were changed."), s.Name), MsgInfo)
+}
+
+func (srv *ServerCmdInfos) DisableAll() {
+	srv.vboxManage.Disable()
+	srv.prefix.Disable()
+	srv.prefixUser.Disable()
+	srv.env.Disable()
+	srv.shell.Disable()
+	srv.transcript.Disable()
+	srv.transcriptFile.Disable()
+	srv.transcriptBrowse.Disable()
+	srv.apply.Disable()
+}
+
+// the executor is not saved - after a restart the server is live again
+func (srv *ServerCmdInfos) applyTranscript(s *vm.VmServer) bool {
+	mode := vm.ExecutorType(max(srv.transcript.SelectedIndex(), 0))
+	file := strings.TrimSpace(srv.transcriptFile.Text)
+	old := s.GetExecutor()
+	if mode == old.Type() && file == vm.ExecutorFile(old) {
+		return true
+	}
+	if mode != vm.Executor_live && file == "" {
+		SetStatusText(lang.X("details.srvcmd.transcript_nofile", "A transcript file is needed."), MsgError)
+		return false
+	}
+	switch mode {
+	case vm.Executor_live:
+		s.SetExecutor(nil)
+	case vm.Executor_record:
+		s.SetExecutor(vm.NewRecordExecutor(file, nil))
+	case vm.Executor_replay:
+		e, err := vm.NewReplayExecutor(file)
+		if err != nil {
+			SetStatusText(fmt.Sprintf(lang.X("details.srvcmd.transcript_error", "Transcript '%s' could not be loaded: %s"), file, err.Error()), MsgError)
+			return false
+		}
+		s.SetExecutor(e)
+	}
+	return true
+}
+
+func (srv *ServerCmdInfos) browseTranscript() {
+	set := func(u fyne.URI) {
+		if u != nil {
+			srv.transcriptFile.SetText(u.Path())
+		}
+	}
+	var dia *dialog.FileDialog
+	if vm.ExecutorType(srv.transcript.SelectedIndex()) == vm.Executor_record {
+		dia = dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+			if err != nil || w == nil {
+				return
+			}
+			w.Close()
+			set(w.URI())
+		}, Gui.MainWindow)
+		dia.SetFileName("vboxssh-transcript.jsonl")
+	} else {
+		dia = dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+			if err != nil || r == nil {
+				return
+			}
+			r.Close()
+			set(r.URI())
+		}, Gui.MainWindow)
+	}
+	dia.SetView(dialog.ListView)
+	ms := Gui.MainWindow.Canvas().Size()
+	dia.Resize(fyne.NewSize(ms.Width*.8, ms.Height*.8))
+	dia.Show()
+}
+
+func (srv *ServerCmdInfos) UpdateByStatus() {
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"bytemystery-com/vboxssh/server"
+	"bytemystery-com/vboxssh/util"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Editor for the ordered jump host chain of a server
+type JumpHostEditor struct {
+	content  fyne.CanvasObject
+	list     *widget.List
+	hosts    []server.Server
+	selected int
+}
+
+func NewJumpHostEditor() *JumpHostEditor {
+	e := JumpHostEditor{selected: -1}
+
+	e.list = widget.NewList(func() int {
+		return len(e.hosts)
+	}, func() fyne.CanvasObject {
+		return widget.NewLabel("")
+	}, func(id widget.ListItemID, o fyne.CanvasObject) {
+		label, ok := o.(*widget.Label)
+		if !ok {
+			return
+		}
+		h := e.hosts[id]
+		text := fmt.Sprintf("%d. %s@%s:%d", id+1, h.User, h.Host, h.Port)
+		if h.UseAgent {
+			text += " (" + lang.X("details.srvssh.jump.agent", "agent") + ")"
+		}
+		label.SetText(text)
+	})
+	e.list.OnSelected = func(id widget.ListItemID) {
+		e.selected = id
+	}
+	e.list.OnUnselected = func(id widget.ListItemID) {
+		e.selected = -1
+	}
+
+	toolBar := widget.NewToolbar(
+		widget.NewToolbarAction(theme.ContentAddIcon(), func() {
+			e.edit(-1)
+		}),
+		widget.NewToolbarAction(theme.DocumentCreateIcon(), func() {
+			if e.selected >= 0 {
+				e.edit(e.selected)
+			}
+		}),
+		widget.NewToolbarAction(theme.ContentRemoveIcon(), func() {
+			if e.selected >= 0 {
+				e.hosts = append(e.hosts[:e.selected], e.hosts[e.selected+1:]...)
+				e.list.UnselectAll()
+				e.list.Refresh()
+			}
+		}),
+		widget.NewToolbarSeparator(),
+		widget.NewToolbarAction(theme.MoveUpIcon(), func() {
+			e.move(-1)
+		}),
+		widget.NewToolbarAction(theme.MoveDownIcon(), func() {
+			e.move(1)
+		}),
+	)
+
+	e.content = container.NewBorder(container.NewVBox(widget.NewLabel(lang.X("details.srvssh.jumphosts", "Jump hosts (in order):")), toolBar),
+		nil, nil, nil, e.list)
+	return &e
+}
+
+func (e *JumpHostEditor) Set(hosts []server.Server) {
+	e.hosts = make([]server.Server, len(hosts))
+	copy(e.hosts, hosts)
+	e.list.UnselectAll()
+	e.list.Refresh()
+}
+
+func (e *JumpHostEditor) Get() []server.Server {
+	if len(e.hosts) == 0 {
+		return nil
+	}
+	hosts := make([]server.Server, len(e.hosts))
+	copy(hosts, e.hosts)
+	return hosts
+}
+
+func (e *JumpHostEditor) move(delta int) {
+	n := e.selected + delta
+	if e.selected < 0 || n < 0 || n >= len(e.hosts) {
+		return
+	}
+	e.hosts[e.selected], e.hosts[n] = e.hosts[n], e.hosts[e.selected]
+	e.list.Select(n)
+	e.list.Refresh()
+}
+
+// index -1 adds a new jump host
+func (e *JumpHostEditor) edit(index int) {
+	var h server.Server
+	if index >= 0 {
+		h = e.hosts[index]
+	} else {
+		h.Port = 22
+	}
+
+	host := widget.NewEntry()
+	host.SetPlaceHolder(lang.X("details.srvssh.host_placeholder", "SSH host (xy.com)"))
+	host.SetText(h.Host)
+	port := widget.NewEntry()
+	port.SetPlaceHolder(lang.X("details.srvssh.port_placeholder", "SSH port (22)"))
+	port.SetText(strconv.Itoa(h.Port))
+	port.OnChanged = util.GetNumberFilter(port, nil)
+	user := widget.NewEntry()
+	user.SetPlaceHolder(lang.X("details.srvssh.user_placeholder", "SSH user"))
+	user.SetText(h.User)
+	pass := widget.NewPasswordEntry()
+	pass.SetPlaceHolder(lang.X("details.srvssh.pass_placeholder", "SSH / key password"))
+	pass.SetText(h.Password)
+	keyFile := widget.NewEntry()
+	keyFile.SetPlaceHolder(lang.X("details.srvssh.keyfile_placeholder", "SSH keyfile"))
+	keyFile.SetText(h.KeyFile)
+	browse := widget.NewButton(lang.X("details.srvssh.browse", "Browse"), func() {
+		dia := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+			if err != nil || r == nil {
+				return
+			}
+			r.Close()
+			keyFile.SetText(r.URI().String())
+		}, Gui.MainWindow)
+		dia.SetView(dialog.ListView)
+		ms := Gui.MainWindow.Canvas().Size()
+		dia.Resize(fyne.NewSize(ms.Width*.8, ms.Height*.8))
+		dia.Show()
+	})
+	useAgent := widget.NewCheck(lang.X("details.srvssh.useagent", "Use SSH agent"), nil)
+	useAgent.SetChecked(h.UseAgent)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem(lang.X("details.srvssh.host", "Host"), host),
+		widget.NewFormItem(lang.X("details.srvssh.port", "Port"), port),
+		widget.NewFormItem(lang.X("details.srvssh.user", "User"), user),
+		widget.NewFormItem(lang.X("details.srvssh.password", "Password"), pass),
+		widget.NewFormItem(lang.X("details.srvssh.keyfile", "Keyfile"), container.NewBorder(nil, nil, nil, browse, keyFile)),
+		widget.NewFormItem("", useAgent),
+	}
+	dia := dialog.NewForm(lang.X("details.srvssh.jump.title", "Jump host"), lang.X("ok", "Ok"), lang.X("cancel", "Cancel"), items, func(ok bool) {
+		if !ok || host.Text == "" {
+			return
+		}
+		p, err := strconv.Atoi(port.Text)
+		if err != nil || p <= 0 {
+			p = 22
+		}
+		// the stored host key belongs to the old address
+		if h.Host != host.Text || h.Port != p {
+			h.HostKey = ""
+		}
+		h.Host = host.Text
+		h.Port = p
+		h.User = user.Text
+		h.Password = pass.Text
+		h.KeyFile = keyFile.Text
+		h.UseAgent = useAgent.Checked
+		if index >= 0 {
+			e.hosts[index] = h
+		} else {
+			e.hosts = append(e.hosts, h)
+		}
+		e.list.Refresh()
+	}, Gui.MainWindow)
+	dia.Resize(fyne.NewSize(util.GetFormWidth(), dia.MinSize().Height))
+	dia.Show()
+}
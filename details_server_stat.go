@@ -28,6 +28,7 @@ import (
 	"fmt"
 	"time"
 
+	"bytemystery-com/vboxssh/run"
 	"bytemystery-com/vboxssh/util"
 
 	"fyne.io/fyne/v2"
@@ -43,6 +44,12 @@ type ServerStatInfos struct {
 	read    *widget.Label
 	write   *widget.Label
 
+	state      *widget.Label
+	rtt        *widget.Label
+	reconnects *widget.Label
+	sessions   *widget.Label
+	latency    *widget.Label
+
 	unitTotal *widget.Label
 	unitRead  *widget.Label
 	unitWrite *widget.Label
@@ -62,6 +69,11 @@ func NewServerStatTab() *ServerStatInfos {
 	srv.total.Importance = widget.HighImportance
 	srv.read = widget.NewLabel("")
 	srv.write = widget.NewLabel("")
+	srv.state = widget.NewLabel("")
+	srv.rtt = widget.NewLabel("")
+	srv.reconnects = widget.NewLabel("")
+	srv.sessions = widget.NewLabel("")
+	srv.latency = widget.NewLabel("")
 
 	labelTotal := widget.NewLabel(lang.X("details.srvstat.total", "Total"))
 	labelTotal.Importance = widget.HighImportance
@@ -80,9 +92,19 @@ func NewServerStatTab() *ServerStatInfos {
 		labelTotal, container.NewHBox(container.NewGridWrap(fieldSize, srv.total), srv.unitTotal),
 		labelRead, container.NewHBox(container.NewGridWrap(fieldSize, srv.read), srv.unitRead),
 		labelWrite, container.NewHBox(container.NewGridWrap(fieldSize, srv.write), srv.unitWrite),
+		widget.NewLabel(lang.X("details.srvstat.state", "Connection")), srv.state,
+		widget.NewLabel(lang.X("details.srvstat.rtt", "Keepalive")), srv.rtt,
+		widget.NewLabel(lang.X("details.srvstat.reconnects", "Reconnects")), srv.reconnects,
+		widget.NewLabel(lang.X("details.srvstat.sessions", "Sessions")), srv.sessions,
+		widget.NewLabel(lang.X("details.srvstat.latency", "Latency")), srv.latency,
 	)
 
-	content := container.NewGridWrap(fyne.NewSize(formWidth, c1.MinSize().Height), c1)
+	audit := widget.NewButton(lang.X("details.srvstat.audit", "Audit log"), func() {
+		showAuditLog(Data.GetServer(Gui.ActiveItemServer, true))
+	})
+
+	content := container.NewVBox(container.NewGridWrap(fyne.NewSize(formWidth, c1.MinSize().Height), c1),
+		container.NewHBox(audit))
 
 	srv.tabItem = container.NewTabItem(lang.X("details.vm_info.tab.stat", "Stat"), content)
 
@@ -125,8 +147,13 @@ func (srv *ServerStatInfos) UpdateDisplay() {
 		srv.unitWrite.SetText(t)
 		srv.unitRead.SetText(t)
 		srv.unitTotal.SetText(t)
+		srv.state.SetText(t)
+		srv.rtt.SetText(t)
+		srv.reconnects.SetText(t)
+		srv.sessions.SetText(t)
+		srv.latency.SetText(t)
 	} else {
-		r, w, _, _ := s.GetStatistic()
+		r, w, reconnects, _ := s.GetStatistic()
 		val, unit := srv.formatBytesDisplay(r)
 		srv.read.SetText(val)
 		srv.unitRead.SetText(unit)
@@ -138,6 +165,26 @@ func (srv *ServerStatInfos) UpdateDisplay() {
 		val, unit = srv.formatBytesDisplay(r + w)
 		srv.total.SetText(val)
 		srv.unitTotal.SetText(unit)
+
+		text, importance := connStateText(s.GetConnState())
+		rtt, retries, err := s.GetConnHealth()
+		if err != nil {
+			text += " (" + err.Error() + ")"
+		}
+		srv.state.SetText(text)
+		srv.state.Importance = importance
+		srv.state.Refresh()
+		srv.rtt.SetText(fmt.Sprintf("%d ms", rtt.Milliseconds()))
+		if retries > 0 {
+			srv.reconnects.SetText(fmt.Sprintf(lang.X("details.srvstat.reconnects_retry", "%d (retry %d)"), reconnects, retries))
+		} else {
+			srv.reconnects.SetText(fmt.Sprintf("%d", reconnects))
+		}
+		active, queued, max := s.GetSessionStats()
+		srv.sessions.SetText(fmt.Sprintf(lang.X("details.srvstat.sessions_value", "%d of %d active, %d queued"), active, max, queued))
+		session, shell := s.GetLatencyStats()
+		srv.latency.SetText(fmt.Sprintf(lang.X("details.srvstat.latency_value", "Session %s (%d), shell %s (%d)"),
+			formatLatency(session), session.Count, formatLatency(shell), shell.Count))
 	}
 }
 
@@ -164,3 +211,10 @@ func (srv *ServerStatInfos) formatBytesDisplay(v uint64) (string, string) {
 		return fmt.Sprintf("%.2f", float64(val/(1000.0*1000.0*1000.0))), "GByte"
 	}
 }
+
+func formatLatency(l run.LatencyStat) string {
+	if l.Count == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d ms", l.Average.Milliseconds())
+}
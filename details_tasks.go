@@ -25,11 +25,17 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"bytemystery-com/vboxssh/util"
+	"bytemystery-com/vboxssh/vm"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/lang"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
@@ -47,6 +53,13 @@ type TaskData struct {
 	name   string
 	status string
 	state  TaskStatusType
+	// nil if the task can not be cancelled
+	cancel context.CancelFunc
+	start  time.Time
+	end    time.Time
+	// -1 if the task reports no progress
+	percent   int
+	remaining time.Duration
 }
 
 type TasksInfos struct {
@@ -55,25 +68,49 @@ type TasksInfos struct {
 	tasks    []*TaskData
 	nameSize fyne.Size
 	iconSize fyne.Size
+	barSize  fyne.Size
+	timeSize fyne.Size
 }
 
 func NewTasksInfos() *TasksInfos {
 	t := TasksInfos{}
 	t.nameSize = util.GetDefaultTextSize("XXXXXXXXXXXXXXXXXXXXXXX")
 	t.iconSize = fyne.NewSize(16, 16)
+	t.barSize = util.GetDefaultTextSize("XXXXXXXXXXXX")
+	t.timeSize = util.GetDefaultTextSize("00:00:00 / ~00:00:00")
 	t.list = widget.NewList(t.listGetNumberOfItems, t.listCreateItem, t.listUpdateItem)
 	t.content = container.NewBorder(nil, nil, nil, nil, t.list)
 	t.tasks = make([]*TaskData, 0, Gui.Settings.TasksMaxEntries)
 
+	go t.timerProc()
+
 	return &t
 }
 
-func (t *TasksInfos) AddTask(uuid, name, status string) {
+// the elapsed time of running tasks
+func (t *TasksInfos) timerProc() {
+	for {
+		time.Sleep(time.Second)
+		fyne.Do(func() {
+			for _, item := range t.tasks {
+				if item.state == TaskStatus_running {
+					t.list.Refresh()
+					return
+				}
+			}
+		})
+	}
+}
+
+func (t *TasksInfos) AddTask(uuid, name, status string, cancel context.CancelFunc) {
 	task := TaskData{
-		uuid:   uuid,
-		name:   name,
-		status: status,
-		state:  TaskStatus_running,
+		uuid:    uuid,
+		name:    name,
+		status:  status,
+		state:   TaskStatus_running,
+		cancel:  cancel,
+		start:   time.Now(),
+		percent: -1,
 	}
 	t.tasks = append(t.tasks, &task)
 	t.checkTasks(false)
@@ -101,10 +138,41 @@ func (t *TasksInfos) setTaskStatus(uuid, status string, append bool, state TaskS
 			task.status = status
 		}
 		task.state = state
+		if state != TaskStatus_running {
+			task.end = time.Now()
+			task.cancel = nil
+		}
+		t.list.Refresh()
+	})
+}
+
+func (t *TasksInfos) UpdateTaskProgress(uuid string, ev vm.ProgressEvent) {
+	fyne.Do(func() {
+		task := t.findTask(uuid)
+		if task == nil {
+			return
+		}
+		task.percent = ev.Percent
+		task.remaining = ev.Remaining
+		switch ev.Type {
+		case vm.ProgressEvent_phase:
+			task.status = ev.Phase
+		case vm.ProgressEvent_error:
+			task.status = ev.Error
+		case vm.ProgressEvent_done:
+			task.remaining = 0
+		}
 		t.list.Refresh()
 	})
 }
 
+// Status writer for the vm functions which shows the progress of the task
+func (t *TasksInfos) NewProgressWriter(uuid string) *vm.ProgressWriter {
+	return vm.NewProgressWriter(func(ev vm.ProgressEvent) {
+		t.UpdateTaskProgress(uuid, ev)
+	})
+}
+
 func (t *TasksInfos) UpdateTaskStatus(uuid, status string, append bool) {
 	t.setTaskStatus(uuid, status, append, TaskStatus_running)
 }
@@ -117,6 +185,18 @@ func (t *TasksInfos) AbortTask(uuid, status string, append bool) {
 	t.setTaskStatus(uuid, status, append, TaskStatus_aborted)
 }
 
+// The task itself reports the abort when its command has ended
+func (t *TasksInfos) CancelTask(uuid string) {
+	task := t.findTask(uuid)
+	if task == nil || task.state != TaskStatus_running || task.cancel == nil {
+		return
+	}
+	task.cancel()
+	task.cancel = nil
+	task.status = lang.X("tasks.cancelling", "Cancelling ...")
+	t.list.Refresh()
+}
+
 func (t *TasksInfos) checkTasks(refresh bool) {
 	if len(t.tasks) <= Gui.Settings.TasksMaxEntries {
 		return
@@ -152,8 +232,19 @@ func (t *TasksInfos) listCreateItem() fyne.CanvasObject {
 	status := canvas.NewText("", theme.Color(theme.ColorNameForeground))
 	status.Refresh()
 
+	bar := widget.NewProgressBar()
+	bar.Max = 100
+
+	times := canvas.NewText("", theme.Color(theme.ColorNameForeground))
+	times.Alignment = fyne.TextAlignTrailing
+
+	cancel := widget.NewButtonWithIcon("", theme.CancelIcon(), nil)
+	cancel.Importance = widget.LowImportance
+
 	return container.NewBorder(nil, nil, container.NewHBox(icon,
-		container.NewGridWrap(t.nameSize, name)), nil, status)
+		container.NewGridWrap(t.nameSize, name)),
+		container.NewHBox(container.NewGridWrap(t.timeSize, times), cancel),
+		container.NewBorder(nil, nil, container.NewGridWrap(t.barSize, bar), nil, status))
 }
 
 func (t *TasksInfos) listUpdateItem(id widget.ListItemID, o fyne.CanvasObject) {
@@ -161,7 +252,35 @@ func (t *TasksInfos) listUpdateItem(id widget.ListItemID, o fyne.CanvasObject) {
 	if !ok {
 		return
 	}
-	status, ok := c.Objects[0].(*canvas.Text)
+	center, ok := c.Objects[0].(*fyne.Container)
+	if !ok {
+		return
+	}
+	status, ok := center.Objects[0].(*canvas.Text)
+	if !ok {
+		return
+	}
+	barWrap, ok := center.Objects[1].(*fyne.Container)
+	if !ok {
+		return
+	}
+	bar, ok := barWrap.Objects[0].(*widget.ProgressBar)
+	if !ok {
+		return
+	}
+	right, ok := c.Objects[2].(*fyne.Container)
+	if !ok {
+		return
+	}
+	timesWrap, ok := right.Objects[0].(*fyne.Container)
+	if !ok {
+		return
+	}
+	times, ok := timesWrap.Objects[0].(*canvas.Text)
+	if !ok {
+		return
+	}
+	cancel, ok := right.Objects[1].(*widget.Button)
 	if !ok {
 		return
 	}
@@ -187,6 +306,16 @@ func (t *TasksInfos) listUpdateItem(id widget.ListItemID, o fyne.CanvasObject) {
 	}
 
 	task := t.tasks[id]
+	if task.state == TaskStatus_running && task.cancel != nil {
+		uuid := task.uuid
+		cancel.OnTapped = func() {
+			t.CancelTask(uuid)
+		}
+		cancel.Show()
+	} else {
+		cancel.OnTapped = nil
+		cancel.Hide()
+	}
 	name.Text = util.TruncateText(task.name, t.nameSize.Width, name, util.Begin)
 
 	switch task.state {
@@ -203,10 +332,48 @@ func (t *TasksInfos) listUpdateItem(id widget.ListItemID, o fyne.CanvasObject) {
 	icon.FillMode = canvas.ImageFillContain
 	icon.SetMinSize(t.iconSize)
 
-	status.Text = util.TruncateText(task.status, t.list.Size().Width-t.nameSize.Width-t.iconSize.Width, status, util.Begin)
+	if task.percent >= 0 {
+		bar.SetValue(float64(task.percent))
+		barWrap.Show()
+	} else {
+		barWrap.Hide()
+	}
+	times.Text = t.formatTimes(task)
+	times.Color = theme.Color(theme.ColorNameForeground)
+	times.Refresh()
+
+	width := t.list.Size().Width - t.nameSize.Width - t.iconSize.Width - t.timeSize.Width - cancel.MinSize().Width
+	if task.percent >= 0 {
+		width -= t.barSize.Width
+	}
+	status.Text = util.TruncateText(task.status, width, status, util.Begin)
 	status.Color = theme.Color(theme.ColorNamePrimary)
 
 	status.Refresh()
 	name.Refresh()
 	icon.Refresh()
 }
+
+// elapsed / ~remaining
+func (t *TasksInfos) formatTimes(task *TaskData) string {
+	end := task.end
+	if end.IsZero() {
+		end = time.Now()
+	}
+	str := formatTaskDuration(end.Sub(task.start))
+	if task.state == TaskStatus_running && task.remaining > 0 {
+		str += " / ~" + formatTaskDuration(task.remaining)
+	}
+	return str
+}
+
+func formatTaskDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	s := int(d.Seconds()) % 60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%02d:%02d", m, s)
+}
@@ -73,7 +73,7 @@ var _ DetailsInterface = (*AudioTab)(nil)
 
 func NewAudioTab() *AudioTab {
 	audioTab := AudioTab{
-		hostDriverMapStringToIndex: map[string]int{"none": 0, "default": 1, "alsa": 2, "oss": 3, "pulseaudio": 4, "null": 5},
+		hostDriverMapStringToIndex: map[string]int{"none": 0, "default": 1, "alsa": 2, "oss": 3, "pulse": 4, "null": 5},
 		hostDriverMapIndexToType:   map[int]vm.AudioDriverType{0: vm.AudioDriver_none, 1: vm.AudioDriver_default, 2: vm.AudioDriver_alsa, 3: vm.AudioDriver_oss, 4: vm.AudioDriver_pulse, 5: vm.AudioDriver_null},
 		controllerMapStringToIndex: map[string]int{"ac97": 0, "hda": 1, "sb16": 2},
 		controllerMapIndexToType:   map[int]vm.AudioControllerType{0: vm.AudioController_ac97, 1: vm.AudioController_hda, 2: vm.AudioController_sb16},
@@ -163,7 +163,7 @@ func (audio *AudioTab) UpdateBySelect() {
 	util.CheckFromProperty(audio.out, v, "audio_in", "on", &audio.oldValues.in)
 
 	// Driver
-	util.SelectEntryFromProperty(audio.hostDriver, v, "audio_driver", audio.hostDriverMapStringToIndex, &audio.oldValues.driver)
+	util.SelectEntryFromProperty(audio.hostDriver, v, "audio", audio.hostDriverMapStringToIndex, &audio.oldValues.driver)
 
 	// Controller
 	util.SelectEntryFromProperty(audio.controller, v, "audio_controller", audio.controllerMapStringToIndex, &audio.oldValues.controller)
@@ -62,11 +62,13 @@ type CpuRamTab struct {
 	memoryEntry       *widget.Entry
 	pae               *widget.Check
 	nestedVT          *widget.Check
+	nestedVTHint      *CapabilityHint
 	x2Apic            *widget.Check
 	nestedPaging      *widget.Check
 	paraVirtInterface *widget.Select
 
 	apply   *widget.Button
+	preview *widget.Button
 	tabItem *container.TabItem
 
 	paraVirtMapStringToIndex map[string]int
@@ -91,6 +93,12 @@ func NewCpuRamTab() *CpuRamTab {
 		cpuRamTab.Apply()
 	})
 	cpuRamTab.apply.Importance = widget.HighImportance
+	cpuRamTab.preview = newPreviewButton(&cpuRamTab, func() func() {
+		old := cpuRamTab.oldValues
+		return func() {
+			cpuRamTab.oldValues = old
+		}
+	})
 
 	formWidth := util.GetFormWidth()
 	cpuRamTab.cpusEntry = widget.NewEntry()
@@ -162,6 +170,7 @@ func NewCpuRamTab() *CpuRamTab {
 	)
 	cpuRamTab.nestedPaging = widget.NewCheck(lang.X("details.vm_cpuram.nestedpage", "Nested paging"), nil)
 	cpuRamTab.nestedVT = widget.NewCheck(lang.X("details.vm_cpuram.nestedvt", "Nested VT"), nil)
+	cpuRamTab.nestedVTHint = NewCapabilityHint()
 	cpuRamTab.pae = widget.NewCheck(lang.X("details.vm_cpuram.pae", "PAE"), nil)
 	cpuRamTab.x2Apic = widget.NewCheck(lang.X("details.vm_cpuram.x2apic", "x2APIC"), nil)
 	cpuRamTab.paraVirtInterface = widget.NewSelect([]string{
@@ -174,7 +183,7 @@ func NewCpuRamTab() *CpuRamTab {
 	}, nil)
 
 	grid2 := container.New(layout.NewFormLayout(),
-		cpuRamTab.nestedPaging, cpuRamTab.nestedVT,
+		cpuRamTab.nestedPaging, container.NewHBox(cpuRamTab.nestedVT, cpuRamTab.nestedVTHint),
 		cpuRamTab.pae, cpuRamTab.x2Apic,
 
 		widget.NewLabel(lang.X("details.vm_cpuram.paravirt", "Paravirtualization interface")), cpuRamTab.paraVirtInterface,
@@ -185,7 +194,7 @@ func NewCpuRamTab() *CpuRamTab {
 	gridWrap := container.NewVBox(util.NewVFiller(0.5), gridWrap1, util.NewFiller(0, 10), widget.NewSeparator(), util.NewFiller(0, 10), gridWrap2)
 
 	c := container.NewVBox(container.NewHBox(gridWrap),
-		container.NewHBox(layout.NewSpacer(), cpuRamTab.apply, util.NewFiller(32, 0)))
+		container.NewHBox(layout.NewSpacer(), cpuRamTab.preview, cpuRamTab.apply, util.NewFiller(32, 0)))
 	cpuRamTab.tabItem = container.NewTabItem(lang.X("details.vm_info.tab.cpuram", "CPU/RAM"), c)
 	return &cpuRamTab
 }
@@ -198,6 +207,7 @@ func (cr *CpuRamTab) UpdateBySelect() {
 		return
 	}
 	cr.apply.Enable()
+	cr.preview.Enable()
 
 	hostInfos, err := s.GetHostInfos(false)
 	if err != nil {
@@ -275,34 +285,17 @@ func (cr *CpuRamTab) UpdateBySelect() {
 
 	// Values
 	// CPU
-	str, ok = v.Properties["cpus"]
-	if ok {
-		val, err := strconv.Atoi(str)
-		if err == nil {
-			cr.cpus.SetValue(float64(val))
-			cr.oldValues.cpus = val
-		}
-	}
+	config := v.Config()
+	cr.cpus.SetValue(float64(config.Cpu.Cpus))
+	cr.oldValues.cpus = config.Cpu.Cpus
 
 	// CPUcap
-	str, ok = v.Properties["cpuexecutioncap"]
-	if ok {
-		val, err := strconv.Atoi(str)
-		if err == nil {
-			cr.cpuCap.SetValue(float64(val))
-			cr.oldValues.cpuCap = val
-		}
-	}
+	cr.cpuCap.SetValue(float64(config.Cpu.ExecutionCap))
+	cr.oldValues.cpuCap = config.Cpu.ExecutionCap
 
 	// Memory
-	str, ok = v.Properties["memory"]
-	if ok {
-		val, err := strconv.Atoi(str)
-		if err == nil {
-			cr.memory.SetValue(float64(val))
-			cr.oldValues.ram = val
-		}
-	}
+	cr.memory.SetValue(float64(config.Cpu.Memory))
+	cr.oldValues.ram = config.Cpu.Memory
 
 	// Pae
 	util.CheckFromProperty(cr.pae, v, "pae", "on", &cr.oldValues.pae)
@@ -340,7 +333,7 @@ func (cr *CpuRamTab) UpdateBySelect() {
 
 // called from status updates
 func (cr *CpuRamTab) UpdateByStatus() {
-	_, v := getActiveServerAndVm()
+	s, v := getActiveServerAndVm()
 	if v != nil {
 		state, err := v.GetState()
 		if err != nil {
@@ -358,10 +351,12 @@ func (cr *CpuRamTab) UpdateByStatus() {
 			cr.memory.Enable()
 			cr.memoryEntry.Enable()
 			cr.apply.Enable()
+			cr.preview.Enable()
 			cr.pae.Enable()
 			cr.x2Apic.Enable()
 			cr.nestedPaging.Enable()
 			cr.nestedVT.Enable()
+			enableByCapability(cr.nestedVT, cr.nestedVTHint, s, vm.Capability_nestedHwVirt)
 			cr.paraVirtInterface.Enable()
 
 		default:
@@ -387,10 +382,15 @@ func (cr *CpuRamTab) DisableAll() {
 	cr.paraVirtInterface.Disable()
 
 	cr.apply.Disable()
+	cr.preview.Disable()
 }
 
 func (cr *CpuRamTab) Apply() {
 	s, v := getActiveServerAndVm()
+	cr.applyTo(s, v)
+}
+
+func (cr *CpuRamTab) applyTo(s *vm.VmServer, v *vm.VMachine) {
 	if v != nil {
 		ResetStatus()
 		if !cr.cpusEntry.Disabled() {
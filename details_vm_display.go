@@ -57,6 +57,7 @@ type DisplayTab struct {
 	startInWindow *widget.Select
 
 	apply   *widget.Button
+	preview *widget.Button
 	tabItem *container.TabItem
 
 	controllerMapStringToIndex map[string]int
@@ -83,6 +84,12 @@ func NewDisplayTab() *DisplayTab {
 		displayTab.Apply()
 	})
 	displayTab.apply.Importance = widget.HighImportance
+	displayTab.preview = newPreviewButton(&displayTab, func() func() {
+		old := displayTab.oldValues
+		return func() {
+			displayTab.oldValues = old
+		}
+	})
 
 	formWidth := util.GetFormWidth()
 	displayTab.ramEntry = widget.NewEntry()
@@ -140,7 +147,7 @@ func NewDisplayTab() *DisplayTab {
 	gridWrap := container.NewVBox(util.NewVFiller(0.5), gridWrap1, gridWrap2)
 
 	c := container.NewVBox(container.NewHBox(gridWrap),
-		container.NewHBox(layout.NewSpacer(), displayTab.apply, util.NewFiller(32, 0)))
+		container.NewHBox(layout.NewSpacer(), displayTab.preview, displayTab.apply, util.NewFiller(32, 0)))
 	displayTab.tabItem = container.NewTabItem(lang.X("details.vm_info.tab.display", "Display"), c)
 	return &displayTab
 }
@@ -153,6 +160,7 @@ func (display *DisplayTab) UpdateBySelect() {
 		return
 	}
 	display.apply.Enable()
+	display.preview.Enable()
 
 	sysprop, err := s.GetSystemProperties(false)
 	if err != nil {
@@ -188,14 +196,9 @@ func (display *DisplayTab) UpdateBySelect() {
 
 	// Values
 	// VGA RAM
-	str, ok = v.Properties["vram"]
-	if ok {
-		val, err := strconv.Atoi(str)
-		if err == nil {
-			display.ram.SetValue(float64(val))
-			display.oldValues.vgaRam = val
-		}
-	}
+	vram := v.Config().Display.Vram
+	display.ram.SetValue(float64(vram))
+	display.oldValues.vgaRam = vram
 
 	// 3D
 	util.CheckFromProperty(display.a3D, v, "accelerate3d", "on", &display.oldValues.accel3D)
@@ -246,10 +249,15 @@ func (display *DisplayTab) DisableAll() {
 	display.a3D.Disable()
 	display.controller.Disable()
 	display.apply.Disable()
+	display.preview.Disable()
 }
 
 func (display *DisplayTab) Apply() {
 	s, v := getActiveServerAndVm()
+	display.applyTo(s, v)
+}
+
+func (display *DisplayTab) applyTo(s *vm.VmServer, v *vm.VMachine) {
 	if v != nil {
 		ResetStatus()
 		if !display.ramEntry.Disabled() {
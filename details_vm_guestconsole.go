@@ -0,0 +1,499 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"image/color"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"bytemystery-com/vboxssh/util"
+
+	"bytemystery-com/vboxssh/vm"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	GUESTCONSOLE_MAX_HISTORY = 50
+	GUESTCONSOLE_MAX_LINES   = 5000
+	GUESTCONSOLE_HEIGHT      = 20 // lines of the output
+)
+
+// same order as the shell select - the command line is passed as one argument
+var guestConsoleShells = [][]string{
+	nil,
+	{"/bin/sh", "-c"},
+	{`C:\Windows\System32\cmd.exe`, "/c"},
+	{`C:\Windows\System32\WindowsPowerShell\v1.0\powershell.exe`, "-NoProfile", "-Command"},
+}
+
+const (
+	guestConsoleShell_none = iota
+	guestConsoleShell_sh
+	guestConsoleShell_cmd
+	guestConsoleShell_powershell
+)
+
+// Per VM - saved with the password encrypted by the master password
+type GuestConsoleSettings struct {
+	UserName string   `json:"user"`
+	Password string   `json:"password"`
+	Domain   string   `json:"domain,omitempty"`
+	WorkDir  string   `json:"cwd,omitempty"`
+	Env      []string `json:"env,omitempty"`
+	Shell    int      `json:"shell"`
+	Timeout  int      `json:"timeout,omitempty"` // sec
+	Remember bool     `json:"remember"`
+	// newest first
+	History []string `json:"history,omitempty"`
+
+	// saved form of Password
+	encrypted   string
+	encryptedOf string
+}
+
+type guestConsoleStyle struct {
+	color fyne.ThemeColorName
+	bold  bool
+}
+
+func (s *guestConsoleStyle) Style() fyne.TextStyle {
+	return fyne.TextStyle{Monospace: true, Bold: s.bold}
+}
+
+func (s *guestConsoleStyle) TextColor() color.Color {
+	return theme.Color(s.color)
+}
+
+func (s *guestConsoleStyle) BackgroundColor() color.Color {
+	return nil
+}
+
+var (
+	guestConsoleStyleCmd  = &guestConsoleStyle{color: theme.ColorNamePrimary, bold: true}
+	guestConsoleStyleErr  = &guestConsoleStyle{color: theme.ColorNameError}
+	guestConsoleStyleInfo = &guestConsoleStyle{color: theme.ColorNamePlaceHolder}
+)
+
+type GuestConsoleTab struct {
+	user     *widget.Entry
+	password *widget.Entry
+	domain   *widget.Entry
+	remember *widget.Check
+	workDir  *widget.Entry
+	env      *widget.Entry
+	shell    *widget.Select
+	timeout  *widget.Entry
+
+	command *widget.SelectEntry
+	run     *widget.Button
+	stop    *widget.Button
+	clear   *widget.Button
+	output  *widget.TextGrid
+
+	tabItem *container.TabItem
+
+	// VM UUID -> settings, nil until the data was loaded
+	settings map[string]*GuestConsoleSettings
+
+	// the VM the output belongs to
+	vmUUID    string
+	runCancel context.CancelFunc
+}
+
+var _ DetailsInterface = (*GuestConsoleTab)(nil)
+
+func NewGuestConsoleTab() *GuestConsoleTab {
+	gc := GuestConsoleTab{}
+
+	gc.user = widget.NewEntry()
+	gc.user.SetPlaceHolder(lang.X("details.vm_guestconsole.user", "User"))
+	gc.password = widget.NewPasswordEntry()
+	gc.password.SetPlaceHolder(lang.X("details.vm_guestconsole.password", "Password"))
+	gc.domain = widget.NewEntry()
+	gc.domain.SetPlaceHolder(lang.X("details.vm_guestconsole.domain", "Domain (Windows)"))
+	gc.remember = widget.NewCheck(lang.X("details.vm_guestconsole.remember", "Remember password"), nil)
+	gc.workDir = widget.NewEntry()
+	gc.workDir.SetPlaceHolder(lang.X("details.vm_guestconsole.workdir_placeholder", "Home directory of the user"))
+	gc.env = widget.NewEntry()
+	gc.env.SetPlaceHolder(lang.X("details.vm_guestconsole.env_placeholder", "KEY=value KEY2=value2"))
+	// same order as guestConsoleShells
+	gc.shell = widget.NewSelect([]string{
+		lang.X("details.vm_guestconsole.shell.none", "None (absolute path of the program)"),
+		"/bin/sh -c", "cmd.exe /c", "powershell -Command",
+	}, nil)
+	gc.timeout = widget.NewEntry()
+	gc.timeout.SetPlaceHolder(lang.X("details.vm_guestconsole.timeout_placeholder", "No limit"))
+	gc.timeout.OnChanged = util.GetNumberFilter(gc.timeout, nil)
+
+	gc.output = widget.NewTextGrid()
+
+	gc.command = widget.NewSelectEntry(nil)
+	gc.command.SetPlaceHolder(lang.X("details.vm_guestconsole.command_placeholder", "Command, e.g. df -h"))
+	gc.command.OnSubmitted = func(s string) {
+		gc.runCommand()
+	}
+	gc.run = widget.NewButtonWithIcon(lang.X("details.vm_guestconsole.run", "Run"), theme.MediaPlayIcon(), gc.runCommand)
+	gc.run.Importance = widget.HighImportance
+	gc.stop = widget.NewButtonWithIcon(lang.X("details.vm_guestconsole.stop", "Stop"), theme.MediaStopIcon(), gc.stopCommand)
+	gc.clear = widget.NewButtonWithIcon(lang.X("details.vm_guestconsole.clear", "Clear"), theme.ContentClearIcon(), func() {
+		gc.output.SetText("")
+	})
+
+	labelWidth := util.GetDefaultTextWidth("XXXXXXXXXX")
+	timeoutBox := container.NewBorder(nil, nil, nil, widget.NewLabel(lang.X("details.vm_guestconsole.sec", "sec")), gc.timeout)
+	grid := container.New(layout.NewFormLayout(),
+		container.NewGridWrap(fyne.NewSize(labelWidth, 1),
+			widget.NewLabel(lang.X("details.vm_guestconsole.login", "Login"))),
+		container.NewGridWithColumns(3, gc.user, gc.password, gc.domain),
+		widget.NewLabel(""), gc.remember,
+		widget.NewLabel(lang.X("details.vm_guestconsole.workdir", "Directory")), gc.workDir,
+		widget.NewLabel(lang.X("details.vm_guestconsole.env", "Environment")), gc.env,
+		widget.NewLabel(lang.X("details.vm_guestconsole.shell", "Shell")),
+		container.NewGridWithColumns(2, gc.shell,
+			container.NewBorder(nil, nil, widget.NewLabel(lang.X("details.vm_guestconsole.timeout", "Timeout")), nil, timeoutBox)),
+	)
+
+	outputHeight := util.GetDefaultTextHeight("X") * GUESTCONSOLE_HEIGHT
+	output := container.NewStack(util.NewFiller(0, outputHeight), gc.output)
+	commandLine := container.NewBorder(nil, nil, nil, container.NewHBox(gc.run, gc.stop, gc.clear), gc.command)
+
+	content := container.NewBorder(container.NewVBox(grid, commandLine), nil, nil, util.NewFiller(32, 0), output)
+
+	gc.tabItem = container.NewTabItem(lang.X("details.vm_info.tab.guestconsole", "Guest console"), content)
+	gc.DisableAll()
+	return &gc
+}
+
+// called after the master password was checked
+func (gc *GuestConsoleTab) LoadSettings() {
+	settings, err := loadGuestConsoleSettings(Gui.MasterPassword)
+	if err != nil {
+		SetStatusText(fmt.Sprintf(lang.X("details.vm_guestconsole.load.error", "Loading the guest console settings failed with: %s"), err.Error()), MsgError)
+	}
+	gc.settings = settings
+	gc.UpdateBySelect()
+}
+
+func (gc *GuestConsoleTab) saveSettings() {
+	if gc.settings == nil {
+		return
+	}
+	err := saveGuestConsoleSettings(gc.settings, Gui.MasterPassword)
+	if err != nil {
+		SetStatusText(fmt.Sprintf(lang.X("details.vm_guestconsole.save.error", "Saving the guest console settings failed with: %s"), err.Error()), MsgError)
+	}
+}
+
+// the values of the form - the password is only kept if it should be remembered
+func (gc *GuestConsoleTab) formSettings(history []string) *GuestConsoleSettings {
+	timeout, _ := strconv.Atoi(gc.timeout.Text)
+	s := GuestConsoleSettings{
+		UserName: strings.TrimSpace(gc.user.Text),
+		Domain:   strings.TrimSpace(gc.domain.Text),
+		WorkDir:  strings.TrimSpace(gc.workDir.Text),
+		Env:      strings.Fields(gc.env.Text),
+		Shell:    max(gc.shell.SelectedIndex(), 0),
+		Timeout:  timeout,
+		Remember: gc.remember.Checked,
+		History:  history,
+	}
+	if s.Remember {
+		s.Password = gc.password.Text
+	}
+	return &s
+}
+
+func (gc *GuestConsoleTab) setForm(s *GuestConsoleSettings) {
+	gc.user.SetText(s.UserName)
+	gc.password.SetText(s.Password)
+	gc.domain.SetText(s.Domain)
+	gc.remember.SetChecked(s.Remember)
+	gc.workDir.SetText(s.WorkDir)
+	gc.env.SetText(strings.Join(s.Env, " "))
+	gc.shell.SetSelectedIndex(s.Shell)
+	if s.Timeout > 0 {
+		gc.timeout.SetText(strconv.Itoa(s.Timeout))
+	} else {
+		gc.timeout.SetText("")
+	}
+	gc.command.SetOptions(s.History)
+}
+
+// the settings of a VM without a saved entry
+func defaultGuestConsoleSettings(v *vm.VMachine) *GuestConsoleSettings {
+	s := GuestConsoleSettings{
+		Shell:    guestConsoleShell_sh,
+		Remember: true,
+	}
+	if strings.HasPrefix(strings.ToLower(v.Config().General.OsType), "win") {
+		s.Shell = guestConsoleShell_cmd
+	}
+	return &s
+}
+
+func (gc *GuestConsoleTab) runCommand() {
+	s, v := getActiveServerAndVm()
+	if s == nil || v == nil || gc.runCancel != nil || gc.settings == nil {
+		return
+	}
+	line := strings.TrimSpace(gc.command.Text)
+	if line == "" {
+		return
+	}
+	ResetStatus()
+	old := gc.settings[v.UUID]
+	if old == nil {
+		old = &GuestConsoleSettings{}
+	}
+	history := append([]string{line}, slices.DeleteFunc(slices.Clone(old.History), func(item string) bool {
+		return item == line
+	})...)
+	if len(history) > GUESTCONSOLE_MAX_HISTORY {
+		history = history[:GUESTCONSOLE_MAX_HISTORY]
+	}
+	settings := gc.formSettings(history)
+
+	opt := vm.GuestRunOptions{
+		UserName: settings.UserName,
+		Password: gc.password.Text,
+		Domain:   settings.Domain,
+		Env:      settings.Env,
+		WorkDir:  settings.WorkDir,
+		Timeout:  time.Duration(settings.Timeout) * time.Second,
+	}
+	if shell := guestConsoleShells[settings.Shell]; shell != nil {
+		opt.Exe = shell[0]
+		opt.Args = append(slices.Clone(shell[1:]), line)
+	} else {
+		args, err := vm.SplitCommandLine(line)
+		if err != nil {
+			SetStatusText(fmt.Sprintf(lang.X("details.vm_guestconsole.command.invalid", "Invalid command line: %s"), err.Error()), MsgError)
+			return
+		}
+		opt.Exe = args[0]
+		opt.Args = args[1:]
+	}
+	if opt.UserName == "" {
+		SetStatusText(lang.X("details.vm_guestconsole.user.missing", "A user of the guest is needed."), MsgError)
+		return
+	}
+	for _, env := range opt.Env {
+		if k, _, ok := strings.Cut(env, "="); !ok || k == "" {
+			SetStatusText(fmt.Sprintf(lang.X("details.srvcmd.env_invalid", "Invalid environment entry '%s' (KEY=value)."), env), MsgError)
+			return
+		}
+	}
+
+	// the password is only encrypted again if it was changed
+	settings.encrypted = old.encrypted
+	settings.encryptedOf = old.encryptedOf
+	gc.settings[v.UUID] = settings
+	gc.saveSettings()
+	gc.command.SetOptions(history)
+	gc.command.SetText("")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	gc.runCancel = cancel
+	gc.updateButtons()
+	gc.appendOutput(v.UUID, "$ "+line, guestConsoleStyleCmd)
+
+	stdout := &guestConsoleWriter{gc: gc, uuid: v.UUID}
+	stderr := &guestConsoleWriter{gc: gc, uuid: v.UUID, style: guestConsoleStyleErr}
+	go func() {
+		defer cancel()
+		start := time.Now()
+		_, exit, err := v.RunInGuest(ctx, s, &opt, stdout, stderr)
+		stdout.flush()
+		stderr.flush()
+		info := fmt.Sprintf(lang.X("details.vm_guestconsole.exit", "exit code %d after %s"), exit, formatTaskDuration(time.Since(start)))
+		switch {
+		case ctx.Err() == context.Canceled:
+			info = lang.X("details.vm_guestconsole.stopped", "stopped")
+		case err != nil && exit < 0:
+			SetStatusText(fmt.Sprintf(lang.X("details.vm_guestconsole.run.error", "Running '%s' in VM '%s' failed with: %s"), line, v.Name, err.Error()), MsgError)
+			info = err.Error()
+		}
+		gc.appendOutput(v.UUID, "["+info+"]", guestConsoleStyleInfo)
+		fyne.Do(func() {
+			if gc.vmUUID == v.UUID {
+				gc.runCancel = nil
+				gc.updateButtons()
+			}
+		})
+	}()
+}
+
+func (gc *GuestConsoleTab) stopCommand() {
+	if gc.runCancel != nil {
+		gc.runCancel()
+	}
+}
+
+// may be called from any go routine - ignored if another VM was selected
+func (gc *GuestConsoleTab) appendOutput(uuid, text string, style widget.TextGridStyle) {
+	fyne.Do(func() {
+		if gc.vmUUID != uuid {
+			return
+		}
+		for _, line := range strings.Split(text, "\n") {
+			row := widget.TextGridRow{Style: style}
+			for _, r := range line {
+				row.Cells = append(row.Cells, widget.TextGridCell{Rune: r})
+			}
+			gc.output.Rows = append(gc.output.Rows, row)
+		}
+		if n := len(gc.output.Rows) - GUESTCONSOLE_MAX_LINES; n > 0 {
+			gc.output.Rows = slices.Delete(gc.output.Rows, 0, n)
+		}
+		gc.output.Refresh()
+		gc.output.ScrollToBottom()
+	})
+}
+
+// Passes the output of one stream line by line to the console
+type guestConsoleWriter struct {
+	lock  sync.Mutex
+	buf   string
+	gc    *GuestConsoleTab
+	uuid  string
+	style widget.TextGridStyle
+}
+
+func (w *guestConsoleWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.buf += strings.ReplaceAll(string(p), "\r\n", "\n")
+	if index := strings.LastIndexByte(w.buf, '\n'); index >= 0 {
+		w.gc.appendOutput(w.uuid, w.buf[:index], w.style)
+		w.buf = w.buf[index+1:]
+	}
+	return len(p), nil
+}
+
+// the last line without line end
+func (w *guestConsoleWriter) flush() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.buf != "" {
+		w.gc.appendOutput(w.uuid, w.buf, w.style)
+		w.buf = ""
+	}
+}
+
+// calles by selection change
+func (gc *GuestConsoleTab) UpdateBySelect() {
+	_, v := getActiveServerAndVm()
+	if v != nil && v.UUID == gc.vmUUID && gc.runCancel != nil {
+		// keep the running command
+		gc.updateButtons()
+		return
+	}
+	gc.stopCommand()
+	gc.runCancel = nil
+	gc.output.SetText("")
+	gc.command.SetText("")
+
+	if v == nil || gc.settings == nil {
+		gc.vmUUID = ""
+		gc.setForm(&GuestConsoleSettings{})
+		gc.DisableAll()
+		return
+	}
+	gc.vmUUID = v.UUID
+	settings := gc.settings[v.UUID]
+	if settings == nil {
+		settings = defaultGuestConsoleSettings(v)
+	}
+	gc.setForm(settings)
+	gc.updateButtons()
+}
+
+// called from status updates
+func (gc *GuestConsoleTab) UpdateByStatus() {
+	gc.updateButtons()
+}
+
+func (gc *GuestConsoleTab) DisableAll() {
+	gc.user.Disable()
+	gc.password.Disable()
+	gc.domain.Disable()
+	gc.remember.Disable()
+	gc.workDir.Disable()
+	gc.env.Disable()
+	gc.shell.Disable()
+	gc.timeout.Disable()
+	gc.command.Disable()
+	gc.run.Disable()
+	gc.stop.Disable()
+	gc.clear.Disable()
+}
+
+func (gc *GuestConsoleTab) updateButtons() {
+	_, v := getActiveServerAndVm()
+	if v == nil || gc.settings == nil {
+		gc.DisableAll()
+		return
+	}
+	gc.user.Enable()
+	gc.password.Enable()
+	gc.domain.Enable()
+	gc.remember.Enable()
+	gc.workDir.Enable()
+	gc.env.Enable()
+	gc.shell.Enable()
+	gc.timeout.Enable()
+	gc.clear.Enable()
+
+	if gc.runCancel != nil {
+		gc.command.Disable()
+		gc.run.Disable()
+		gc.stop.Enable()
+		return
+	}
+	gc.stop.Disable()
+	// guestcontrol needs the guest additions
+	if v.GuestInfoAvailable() {
+		gc.command.Enable()
+		gc.run.Enable()
+	} else {
+		gc.command.Disable()
+		gc.run.Disable()
+	}
+}
+
+func (gc *GuestConsoleTab) Apply() {
+}
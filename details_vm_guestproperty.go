@@ -0,0 +1,474 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"bytemystery-com/vboxssh/util"
+
+	"bytemystery-com/vboxssh/vm"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	// guestproperty wait gives the session back after this time
+	GUESTPROPERTY_WAIT_INTERVAL = 10 * time.Second
+	GUESTPROPERTY_MAX_EVENTS    = 200
+)
+
+type GuestPropertyTab struct {
+	list    *widget.List
+	events  *widget.List
+	tabItem *container.TabItem
+
+	toolAdd     *widget.ToolbarAction
+	toolEdit    *widget.ToolbarAction
+	toolDelete  *widget.ToolbarAction
+	toolRefresh *widget.ToolbarAction
+
+	toolBar *widget.Toolbar
+
+	pattern *widget.Entry
+	watch   *widget.Check
+
+	properties []vm.GuestProperty
+	eventLines []string
+	selected   int
+
+	// the VM the list belongs to
+	vmUUID      string
+	watchCancel context.CancelFunc
+}
+
+var _ DetailsInterface = (*GuestPropertyTab)(nil)
+
+func NewGuestPropertyTab() *GuestPropertyTab {
+	gp := GuestPropertyTab{
+		selected: -1,
+	}
+
+	gp.list = widget.NewList(gp.listLength, gp.listCreateItem, gp.listUpdateItem)
+	gp.list.OnSelected = func(id widget.ListItemID) {
+		gp.selected = id
+		gp.updateToolbarButtons()
+	}
+	gp.list.OnUnselected = func(id widget.ListItemID) {
+		gp.selected = -1
+		gp.updateToolbarButtons()
+	}
+
+	gp.events = widget.NewList(func() int {
+		return len(gp.eventLines)
+	}, func() fyne.CanvasObject {
+		return widget.NewLabel("")
+	}, func(id widget.ListItemID, o fyne.CanvasObject) {
+		label, ok := o.(*widget.Label)
+		if ok && id < len(gp.eventLines) {
+			label.SetText(gp.eventLines[id])
+		}
+	})
+
+	gp.toolAdd = widget.NewToolbarAction(theme.ContentAddIcon(), func() { gp.editProperty(nil) })
+	gp.toolEdit = widget.NewToolbarAction(theme.DocumentCreateIcon(), func() {
+		if gp.selected >= 0 && gp.selected < len(gp.properties) {
+			p := gp.properties[gp.selected]
+			gp.editProperty(&p)
+		}
+	})
+	gp.toolDelete = widget.NewToolbarAction(theme.DeleteIcon(), gp.deleteProperty)
+	gp.toolRefresh = widget.NewToolbarAction(theme.ViewRefreshIcon(), gp.reload)
+
+	gp.toolBar = widget.NewToolbar(gp.toolAdd, gp.toolEdit, gp.toolDelete, widget.NewToolbarSeparator(), gp.toolRefresh)
+
+	gp.pattern = widget.NewEntry()
+	gp.pattern.SetPlaceHolder(lang.X("details.vm_guestproperty.pattern.placeholder", "e.g. /VirtualBox/GuestInfo/Net/*"))
+	gp.pattern.OnSubmitted = func(s string) {
+		gp.reload()
+		if gp.watch.Checked {
+			gp.startWatch()
+		}
+	}
+	gp.watch = widget.NewCheck(lang.X("details.vm_guestproperty.watch", "Watch"), func(checked bool) {
+		if checked {
+			gp.startWatch()
+		} else {
+			gp.stopWatch()
+		}
+	})
+
+	filter := container.NewBorder(nil, nil, widget.NewLabel(lang.X("details.vm_guestproperty.pattern", "Pattern")), gp.watch, gp.pattern)
+
+	split := container.NewVSplit(gp.list, container.NewBorder(widget.NewLabel(lang.X("details.vm_guestproperty.changes", "Changes")), nil, nil, nil, gp.events))
+	split.SetOffset(0.7)
+
+	gridWrap := container.NewBorder(container.NewVBox(gp.toolBar, filter), nil, nil, util.NewFiller(32, 0), split)
+
+	gp.tabItem = container.NewTabItem(lang.X("details.vm_info.tab.guestproperty", "Guest properties"), gridWrap)
+	gp.updateToolbarButtons()
+	return &gp
+}
+
+func (gp *GuestPropertyTab) listLength() int {
+	return len(gp.properties)
+}
+
+func (gp *GuestPropertyTab) listCreateItem() fyne.CanvasObject {
+	name := widget.NewLabel("")
+	name.TextStyle = fyne.TextStyle{Bold: true}
+	name.Truncation = fyne.TextTruncateEllipsis
+	value := widget.NewLabel("")
+	value.Truncation = fyne.TextTruncateEllipsis
+	flags := widget.NewLabel("")
+	flags.Importance = widget.LowImportance
+	return container.NewBorder(nil, nil, nil, flags, container.NewGridWithColumns(2, name, value))
+}
+
+func (gp *GuestPropertyTab) listUpdateItem(id widget.ListItemID, o fyne.CanvasObject) {
+	cont, ok := o.(*fyne.Container)
+	if !ok || id >= len(gp.properties) || len(cont.Objects) != 2 {
+		return
+	}
+	grid, ok := cont.Objects[0].(*fyne.Container)
+	if !ok || len(grid.Objects) != 2 {
+		return
+	}
+	name, ok1 := grid.Objects[0].(*widget.Label)
+	value, ok2 := grid.Objects[1].(*widget.Label)
+	flags, ok3 := cont.Objects[1].(*widget.Label)
+	if !ok1 || !ok2 || !ok3 {
+		return
+	}
+	p := gp.properties[id]
+	name.SetText(p.Name)
+	value.SetText(p.Value)
+	var info []string
+	if !p.TimeStamp.IsZero() {
+		info = append(info, p.TimeStamp.Local().Format(time.DateTime))
+	}
+	if len(p.Flags) > 0 {
+		info = append(info, vm.FormatGuestPropertyFlags(p.Flags))
+	}
+	flags.SetText(strings.Join(info, "  "))
+}
+
+func (gp *GuestPropertyTab) getPatterns() []string {
+	var list []string
+	for _, item := range strings.Split(gp.pattern.Text, "|") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// enumerate in the background
+func (gp *GuestPropertyTab) reload() {
+	s, v := getActiveServerAndVm()
+	if s == nil || v == nil {
+		return
+	}
+	patterns := gp.getPatterns()
+	go func() {
+		list, err := v.EnumerateGuestProperties(s, patterns...)
+		if err != nil {
+			SetStatusText(fmt.Sprintf(lang.X("details.vm_guestproperty.list.error", "Reading guest properties of VM '%s' failed with: %s"), v.Name, err.Error()), MsgError)
+			return
+		}
+		slices.SortFunc(list, func(a, b vm.GuestProperty) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+		fyne.Do(func() {
+			if gp.vmUUID != v.UUID {
+				return
+			}
+			gp.properties = list
+			gp.selected = -1
+			gp.list.UnselectAll()
+			gp.list.Refresh()
+			gp.updateToolbarButtons()
+		})
+	}()
+}
+
+func (gp *GuestPropertyTab) startWatch() {
+	gp.stopWatch()
+	s, v := getActiveServerAndVm()
+	if s == nil || v == nil {
+		return
+	}
+	pattern := strings.Join(gp.getPatterns(), "|")
+	if pattern == "" {
+		pattern = "*"
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	gp.watchCancel = cancel
+	go func() {
+		err := v.WatchGuestProperties(ctx, s, pattern, GUESTPROPERTY_WAIT_INTERVAL, func(p vm.GuestProperty) {
+			fyne.Do(func() {
+				if gp.vmUUID == v.UUID && ctx.Err() == nil {
+					gp.applyChange(p)
+				}
+			})
+		})
+		if err != nil {
+			SetStatusText(fmt.Sprintf(lang.X("details.vm_guestproperty.watch.error", "Watching guest properties of VM '%s' failed with: %s"), v.Name, err.Error()), MsgError)
+			fyne.Do(func() {
+				if gp.vmUUID == v.UUID && ctx.Err() == nil {
+					gp.watch.SetChecked(false)
+				}
+			})
+		}
+	}()
+}
+
+func (gp *GuestPropertyTab) stopWatch() {
+	if gp.watchCancel != nil {
+		gp.watchCancel()
+		gp.watchCancel = nil
+	}
+}
+
+// a change reported by wait - an empty value was deleted
+func (gp *GuestPropertyTab) applyChange(p vm.GuestProperty) {
+	index := slices.IndexFunc(gp.properties, func(item vm.GuestProperty) bool {
+		return item.Name == p.Name
+	})
+	switch {
+	case p.Value == "" && index >= 0:
+		gp.properties = slices.Delete(gp.properties, index, index+1)
+	case p.Value == "":
+	case index >= 0:
+		gp.properties[index] = p
+	default:
+		index, _ = slices.BinarySearchFunc(gp.properties, p.Name, func(item vm.GuestProperty, name string) int {
+			return strings.Compare(item.Name, name)
+		})
+		gp.properties = slices.Insert(gp.properties, index, p)
+	}
+	line := fmt.Sprintf("%s  %s = '%s'", p.TimeStamp.Format(time.TimeOnly), p.Name, p.Value)
+	if len(p.Flags) > 0 {
+		line += "  [" + vm.FormatGuestPropertyFlags(p.Flags) + "]"
+	}
+	gp.eventLines = append([]string{line}, gp.eventLines...)
+	if len(gp.eventLines) > GUESTPROPERTY_MAX_EVENTS {
+		gp.eventLines = gp.eventLines[:GUESTPROPERTY_MAX_EVENTS]
+	}
+	gp.selected = -1
+	gp.list.UnselectAll()
+	gp.list.Refresh()
+	gp.events.Refresh()
+	gp.updateToolbarButtons()
+}
+
+var guestPropertyFlagNames = []struct {
+	flag vm.GuestPropertyFlagType
+	name string
+}{
+	{vm.GuestPropertyFlag_transient, "TRANSIENT"},
+	{vm.GuestPropertyFlag_transreset, "TRANSRESET"},
+	{vm.GuestPropertyFlag_rdonlyguest, "RDONLYGUEST"},
+	{vm.GuestPropertyFlag_rdonlyhost, "RDONLYHOST"},
+	{vm.GuestPropertyFlag_readonly, "READONLY"},
+}
+
+// nil adds a new property
+func (gp *GuestPropertyTab) editProperty(old *vm.GuestProperty) {
+	s, v := getActiveServerAndVm()
+	if s == nil || v == nil {
+		return
+	}
+	name := widget.NewEntry()
+	value := widget.NewEntry()
+	checks := make([]*widget.Check, 0, len(guestPropertyFlagNames))
+	flagBox := container.NewGridWithColumns(3)
+	for _, item := range guestPropertyFlagNames {
+		check := widget.NewCheck(item.name, nil)
+		if old != nil {
+			check.SetChecked(old.HasFlag(item.flag))
+		}
+		checks = append(checks, check)
+		flagBox.Add(check)
+	}
+	if old != nil {
+		name.SetText(old.Name)
+		name.Disable()
+		value.SetText(old.Value)
+	} else {
+		name.SetPlaceHolder("/Provisioning/Done")
+	}
+
+	c := container.New(layout.NewFormLayout(),
+		widget.NewLabel(lang.X("details.vm_guestproperty.name", "Name")), name,
+		widget.NewLabel(lang.X("details.vm_guestproperty.value", "Value")), value,
+		widget.NewLabel(lang.X("details.vm_guestproperty.flags", "Flags")), flagBox,
+	)
+	title := lang.X("details.vm_guestproperty.add.title", "Add guest property")
+	if old != nil {
+		title = lang.X("details.vm_guestproperty.edit.title", "Edit guest property")
+	}
+	dia := dialog.NewCustomConfirm(title, lang.X("import.ok", "Ok"), lang.X("import.cancel", "Cancel"), c,
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			var flags []vm.GuestPropertyFlagType
+			for i, check := range checks {
+				if check.Checked {
+					flags = append(flags, guestPropertyFlagNames[i].flag)
+				}
+			}
+			propName := strings.TrimSpace(name.Text)
+			ResetStatus()
+			go func() {
+				err := v.SetGuestProperty(&s.Client, propName, value.Text, flags, VMStatusUpdateCallBack)
+				if err != nil {
+					SetStatusText(fmt.Sprintf(lang.X("details.vm_guestproperty.set.error", "Setting guest property '%s' of VM '%s' failed with: %s"), propName, v.Name, err.Error()), MsgError)
+				}
+				fyne.Do(gp.reload)
+			}()
+		}, Gui.MainWindow)
+	si := Gui.MainWindow.Canvas().Size()
+	var windowScale float32 = 0.5
+	dia.Resize(fyne.NewSize(si.Width*windowScale, dia.MinSize().Height*1.1))
+	dia.Show()
+	if old != nil {
+		Gui.MainWindow.Canvas().Focus(value)
+	} else {
+		Gui.MainWindow.Canvas().Focus(name)
+	}
+}
+
+func (gp *GuestPropertyTab) deleteProperty() {
+	s, v := getActiveServerAndVm()
+	if s == nil || v == nil || gp.selected < 0 || gp.selected >= len(gp.properties) {
+		return
+	}
+	p := gp.properties[gp.selected]
+	dialog.ShowConfirm(lang.X("details.vm_guestproperty.delete.title", "Delete guest property"),
+		fmt.Sprintf(lang.X("details.vm_guestproperty.delete.msg", "Do you really want to delete the guest property '%s' of '%s' ?"), p.Name, v.Name),
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			ResetStatus()
+			go func() {
+				err := v.UnsetGuestProperty(&s.Client, p.Name, VMStatusUpdateCallBack)
+				if err != nil {
+					SetStatusText(fmt.Sprintf(lang.X("details.vm_guestproperty.delete.error", "Deleting guest property '%s' of VM '%s' failed with: %s"), p.Name, v.Name, err.Error()), MsgError)
+				}
+				fyne.Do(gp.reload)
+			}()
+		}, Gui.MainWindow)
+}
+
+// calles by selection change
+func (gp *GuestPropertyTab) UpdateBySelect() {
+	s, v := getActiveServerAndVm()
+
+	gp.stopWatch()
+	gp.watch.SetChecked(false)
+	gp.properties = nil
+	gp.eventLines = nil
+	gp.selected = -1
+	gp.list.UnselectAll()
+	gp.list.Refresh()
+	gp.events.Refresh()
+
+	if s == nil || v == nil {
+		gp.vmUUID = ""
+		gp.DisableAll()
+		return
+	}
+	gp.vmUUID = v.UUID
+	gp.reload()
+	gp.updateToolbarButtons()
+}
+
+// called from status updates
+func (gp *GuestPropertyTab) UpdateByStatus() {
+	gp.updateToolbarButtons()
+}
+
+func (gp *GuestPropertyTab) DisableAll() {
+	gp.toolAdd.Disable()
+	gp.toolEdit.Disable()
+	gp.toolDelete.Disable()
+	gp.toolRefresh.Disable()
+	gp.pattern.Disable()
+	gp.watch.Disable()
+}
+
+func (gp *GuestPropertyTab) updateToolbarButtons() {
+	_, v := getActiveServerAndVm()
+	if v == nil {
+		gp.DisableAll()
+		return
+	}
+	state, err := v.GetState()
+	if err != nil {
+		return
+	}
+	switch state {
+	case vm.RunState_unknown, vm.RunState_meditation:
+		gp.DisableAll()
+		return
+	}
+	gp.toolAdd.Enable()
+	gp.toolRefresh.Enable()
+	gp.pattern.Enable()
+	if gp.selected >= 0 && gp.selected < len(gp.properties) {
+		gp.toolEdit.Enable()
+		gp.toolDelete.Enable()
+	} else {
+		gp.toolEdit.Disable()
+		gp.toolDelete.Disable()
+	}
+
+	// changes come from a running guest
+	if state == vm.RunState_running || state == vm.RunState_paused {
+		gp.watch.Enable()
+	} else {
+		if gp.watch.Checked {
+			gp.watch.SetChecked(false)
+		}
+		gp.watch.Disable()
+	}
+}
+
+func (gp *GuestPropertyTab) Apply() {
+}
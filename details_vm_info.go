@@ -26,6 +26,9 @@ package main
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"bytemystery-com/vboxssh/util"
 
@@ -39,6 +42,9 @@ import (
 	"github.com/bytemystery-com/colorlabel"
 )
 
+// the info tab reads the guest infos not more often
+const GUESTINFO_MIN_AGE = 10 * time.Second
+
 type oldInfoType struct {
 	name        string
 	osversion   string
@@ -55,6 +61,13 @@ type InfoTab struct {
 	osVersion      *widget.Select
 	description    *widget.Entry
 
+	// published by the guest additions
+	guestIPv4    *fyne.Container
+	guestIPv6    *fyne.Container
+	guestOs      *CopyLabel
+	guestUsers   *widget.Label
+	guestLoading bool
+
 	apply   *widget.Button
 	tabItem *container.TabItem
 }
@@ -80,6 +93,12 @@ func NewInfoTab() *InfoTab {
 	infoTab.description = widget.NewMultiLineEntry()
 	infoTab.description.SetPlaceHolder(lang.X("details.vm_info.description_placeholder", "Description of VM"))
 	infoTab.description.SetMinRowsVisible(7)
+	infoTab.guestIPv4 = container.NewHBox()
+	infoTab.guestIPv6 = container.NewHBox()
+	infoTab.guestOs = NewCopyLabel("")
+	infoTab.guestUsers = widget.NewLabel("")
+	infoTab.setGuestInfo(nil)
+
 	infoTab.apply = widget.NewButton(lang.X("details.vm_info", "Apply"), func() {
 		infoTab.Apply()
 	})
@@ -90,6 +109,10 @@ func NewInfoTab() *InfoTab {
 	grid := container.New(layout.NewFormLayout(),
 		widget.NewLabel(lang.X("details.vm_info.version", "VM Version")), infoTab.version,
 		widget.NewLabel(lang.X("details.vm_info.guestadditions", "Guest additions")), infoTab.guestAdditions,
+		widget.NewLabel(lang.X("details.vm_info.guestipv4", "IPv4 addresses")), infoTab.guestIPv4,
+		widget.NewLabel(lang.X("details.vm_info.guestipv6", "IPv6 addresses")), infoTab.guestIPv6,
+		widget.NewLabel(lang.X("details.vm_info.guestos", "Guest OS")), infoTab.guestOs,
+		widget.NewLabel(lang.X("details.vm_info.guestusers", "Logged in users")), infoTab.guestUsers,
 		widget.NewLabel(lang.X("details.vm_info.cfglocation", "Location")), infoTab.cfgLocation,
 		widget.NewLabel(lang.X("details.vm_info.name", "Name")), infoTab.name,
 		widget.NewLabel(lang.X("details.vm_info.os", "Operating system")), infoTab.os,
@@ -193,18 +216,18 @@ func (info *InfoTab) UpdateBySelect() {
 
 	info.setOsTypes(s, v)
 	info.setVersionTypes(s, v)
-	location := v.Properties["CfgFile"]
-	info.cfgLocation.SetText(location)
+	general := v.Config().General
+	info.cfgLocation.SetText(general.CfgFile)
 
 	info.updateGuestAdditionsInfo()
+	info.setGuestInfo(v.GuestInfo())
+	info.updateGuestInfo(s, v)
 
-	name := v.Properties["name"]
-	info.name.SetText(name)
-	info.oldValues.name = name
+	info.name.SetText(general.Name)
+	info.oldValues.name = general.Name
 
-	description := v.Properties["description"]
-	info.description.SetText(description)
-	info.oldValues.description = description
+	info.description.SetText(general.Description)
+	info.oldValues.description = general.Description
 }
 
 func (info *InfoTab) updateGuestAdditionsInfo() {
@@ -213,19 +236,83 @@ func (info *InfoTab) updateGuestAdditionsInfo() {
 		return
 	}
 
-	gav := v.Properties["GuestAdditionsVersion"]
-	gar := v.Properties["GuestAdditionsRunLevel"]
-	if gav != "" {
-		info.guestAdditions.SetText(fmt.Sprintf(lang.X("details.vm_info.guestadditions.template", "Version: %s, RunLevel: %s"), gav, gar))
+	general := v.Config().General
+	if general.GuestAdditionsVersion != "" {
+		info.guestAdditions.SetText(fmt.Sprintf(lang.X("details.vm_info.guestadditions.template", "Version: %s, RunLevel: %s"), general.GuestAdditionsVersion, strconv.Itoa(general.GuestAdditionsRunLevel)))
 	} else {
 		info.guestAdditions.SetText("-------")
 	}
 }
 
+// reads the infos in the background - at most every GUESTINFO_MIN_AGE
+func (info *InfoTab) updateGuestInfo(s *vm.VmServer, v *vm.VMachine) {
+	if !v.GuestInfoAvailable() {
+		info.setGuestInfo(nil)
+		return
+	}
+	g := v.GuestInfo()
+	if info.guestLoading || (g != nil && time.Since(g.Time) < GUESTINFO_MIN_AGE) {
+		return
+	}
+	info.guestLoading = true
+	go func() {
+		g, err := v.UpdateGuestInfo(s, false)
+		fyne.Do(func() {
+			info.guestLoading = false
+			if _, active := getActiveServerAndVm(); active != v || err != nil {
+				return
+			}
+			info.setGuestInfo(g)
+		})
+	}()
+}
+
+func (info *InfoTab) setGuestInfo(g *vm.GuestInfo) {
+	var v4, v6 []string
+	if g != nil {
+		for _, a := range g.Addresses {
+			if a.IPv4 != "" {
+				v4 = append(v4, a.IPv4)
+			}
+			if a.IPv6 != "" {
+				v6 = append(v6, a.IPv6)
+			}
+		}
+	}
+	setCopyLabels(info.guestIPv4, v4)
+	setCopyLabels(info.guestIPv6, v6)
+	if g == nil {
+		info.guestOs.SetText("")
+		info.guestUsers.SetText("")
+		return
+	}
+	info.guestOs.SetText(strings.TrimSpace(g.OsProduct + " " + g.OsRelease))
+	switch {
+	case g.LoggedInUsers < 0:
+		info.guestUsers.SetText("")
+	case g.LoggedInUsersList != "":
+		info.guestUsers.SetText(fmt.Sprintf("%d (%s)", g.LoggedInUsers, g.LoggedInUsersList))
+	default:
+		info.guestUsers.SetText(strconv.Itoa(g.LoggedInUsers))
+	}
+}
+
+// one label per address - each is copied on its own
+func setCopyLabels(c *fyne.Container, list []string) {
+	c.RemoveAll()
+	if len(list) == 0 {
+		c.Add(widget.NewLabel("-------"))
+	}
+	for _, item := range list {
+		c.Add(NewCopyLabel(item))
+	}
+}
+
 // called from status updates
 func (info *InfoTab) UpdateByStatus() {
-	_, v := getActiveServerAndVm()
+	s, v := getActiveServerAndVm()
 	if v != nil {
+		info.updateGuestInfo(s, v)
 		state, err := v.GetState()
 		if err != nil {
 			return
@@ -28,6 +28,9 @@ import (
 	"crypto/rand"
 	"fmt"
 	"math/big"
+	"slices"
+	"strconv"
+	"strings"
 
 	"bytemystery-com/vboxssh/util"
 
@@ -35,6 +38,7 @@ import (
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/lang"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
@@ -42,13 +46,14 @@ import (
 )
 
 type oldNetworkType struct {
-	enabled     bool
-	network     int
-	name        string
-	adapter     int
-	promiscuous int
-	mac         string
-	connected   bool
+	enabled        bool
+	network        int
+	name           string
+	adapter        int
+	promiscuous    int
+	mac            string
+	connected      bool
+	bandwidthGroup string
 }
 
 type NetworkTab struct {
@@ -56,6 +61,8 @@ type NetworkTab struct {
 	oldValues      oldNetworkType
 	enabled        *widget.Check
 	network        *widget.Select
+	networkOptions []string
+	cloudHint      *CapabilityHint
 	name           *widget.Select
 	nameEntry      *widget.SelectEntry
 	adapter        *widget.Select
@@ -64,7 +71,23 @@ type NetworkTab struct {
 	newMac         *widget.Button
 	cableConnected *widget.Check
 
+	bandwidthGroup  *widget.Select
+	bandwidthManage *widget.Button
+	bandwidthGroups []vm.BandwidthGroup
+
+	// NAT port forwarding
+	natBox        *fyne.Container
+	natList       *widget.List
+	natAdd        *widget.Button
+	natEdit       *widget.Button
+	natDelete     *widget.Button
+	natCollisions *widget.Label
+	natRules      []vm.NatRule
+	natConflicts  map[int][]vm.NatRuleUse
+	natSelected   int
+
 	apply   *widget.Button
+	preview *widget.Button
 	tabItem *container.TabItem
 
 	networkMapStringToIndex     map[string]int
@@ -80,18 +103,25 @@ var _ DetailsInterface = (*NetworkTab)(nil)
 func NewNetworkTab(index int) *NetworkTab {
 	netTab := NetworkTab{
 		number:                      index,
-		networkMapStringToIndex:     map[string]int{"none": -1, "nat": 0, "bridged": 1, "intnet": 2, "hostonly": 3, "generic": 4, "natnetwork": 5, "cloudnetwork": 6, "null": 7},
-		networkMapIndexToType:       map[int]vm.NetType{0: vm.Net_nat, 1: vm.Net_bridged, 2: vm.Net_intnet, 3: vm.Net_hostonly, 4: vm.Net_generic, 5: vm.Net_natnetwork, 6: vm.Net_cloudnetwork, 7: vm.Net_null},
+		networkMapStringToIndex:     map[string]int{"none": -1, "nat": 0, "bridged": 1, "intnet": 2, "hostonly": 3, "generic": 4, "natnetwork": 5, "null": 6, "cloudnetwork": 7},
+		networkMapIndexToType:       map[int]vm.NetType{0: vm.Net_nat, 1: vm.Net_bridged, 2: vm.Net_intnet, 3: vm.Net_hostonly, 4: vm.Net_generic, 5: vm.Net_natnetwork, 6: vm.Net_null, 7: vm.Net_cloudnetwork},
 		adapterMapStringToIndex:     map[string]int{"am79c970a": 0, "am79c973": 1, "82540em": 2, "82543gc": 3, "82545em": 4, "82583v": 5, "virtio": 6, "usbnet": 7},
 		adapterMapIndexToType:       map[int]vm.NicType{0: vm.Nic_amdpcnetpcii, 1: vm.Nic_amdpcnetfastiii, 2: vm.Nic_intelpro1000mtdesktop, 3: vm.Nic_intelpro1000tserver, 4: vm.Nic_intelpro1000mtserver, 5: vm.Nic_intel82583Vgigabit, 6: vm.Nic_virtio, 7: vm.Nic_usbnet},
 		promiscuousMapStringToIndex: map[string]int{"deny": 0, "allow-vms": 1, "allow-all": 2},
 		promiscuousMapIndexToType:   map[int]vm.PromiscType{0: vm.Promisc_deny, 1: vm.Promisc_allowvms, 2: vm.Promisc_allowall},
+		natSelected:                 -1,
 	}
 
 	netTab.apply = widget.NewButton(lang.X("details.vm_network.apply", "Apply"), func() {
 		netTab.Apply()
 	})
 	netTab.apply.Importance = widget.HighImportance
+	netTab.preview = newPreviewButton(&netTab, func() func() {
+		old := netTab.oldValues
+		return func() {
+			netTab.oldValues = old
+		}
+	})
 
 	netTab.enabled = widget.NewCheck(lang.X("details.vm_network.enabled", "Enabled"), func(checked bool) {
 		netTab.adjustNameField()
@@ -103,12 +133,15 @@ func NewNetworkTab(index int) *NetworkTab {
 		lang.X("details.vm_network.attach.hostonly", "Host only"),
 		lang.X("details.vm_network.attach.generic", "Generic"),
 		lang.X("details.vm_network.attach.natnetwork", "NAT network"),
-		lang.X("details.vm_network.attach.cloud", "Cloud"),
 		lang.X("details.vm_network.attach.notattached", "Not attached"),
+		// last - removed if not supported
+		lang.X("details.vm_network.attach.cloud", "Cloud"),
 	}, func(s string) {
 		netTab.adjustNameField()
 		netTab.UpdateByStatus()
 	})
+	netTab.networkOptions = netTab.network.Options
+	netTab.cloudHint = NewCapabilityHint()
 
 	netTab.nameEntry = widget.NewSelectEntry([]string{})
 	netTab.name = widget.NewSelect([]string{}, nil)
@@ -138,27 +171,72 @@ func NewNetworkTab(index int) *NetworkTab {
 
 	netTab.cableConnected = widget.NewCheck(lang.X("details.vm_network.connected", "Cable connected"), nil)
 
+	netTab.bandwidthGroup = widget.NewSelect(bandwidthGroupOptions(nil, vm.BandwidthGroup_network), nil)
+	netTab.bandwidthManage = widget.NewButtonWithIcon("", theme.SettingsIcon(), func() {
+		s, v := getActiveServerAndVm()
+		if s != nil && v != nil {
+			showBandwidthGroupsDialog(s, v, func(groups []vm.BandwidthGroup) {
+				setBandwidthGroups(v, groups)
+			})
+		}
+	})
+
 	grid1 := container.New(layout.NewFormLayout(),
 		netTab.enabled, util.NewFiller(0, 0),
 	)
 
 	grid2 := container.New(layout.NewFormLayout(),
-		widget.NewLabel(lang.X("details.vm_network.attached", "Attached to")), netTab.network,
+		widget.NewLabel(lang.X("details.vm_network.attached", "Attached to")), container.NewBorder(nil, nil, nil, netTab.cloudHint, netTab.network),
 		widget.NewLabel(lang.X("details.vm_network.name", "Name")), container.NewStack(netTab.name, netTab.nameEntry),
 		widget.NewLabel(lang.X("details.vm_network.adapter", "Adapter")), netTab.adapter,
 		widget.NewLabel(lang.X("details.vm_network.promiscuous", "Promiscuous Mode")), netTab.promiscuous,
 		widget.NewLabel(lang.X("details.vm_network.mac", "MAC address")), container.NewBorder(nil, nil, nil, netTab.newMac, netTab.mac),
 		util.NewFiller(0, 0), netTab.cableConnected,
+		widget.NewLabel(lang.X("details.vm_network.bandwidthgroup", "Bandwidth group")), container.NewBorder(nil, nil, nil, netTab.bandwidthManage, netTab.bandwidthGroup),
 	)
 
 	formWidth := util.GetFormWidth()
 	gridWrap1 := container.NewGridWrap(fyne.NewSize(formWidth, grid1.MinSize().Height), grid1)
 	gridWrap2 := container.NewGridWrap(fyne.NewSize(formWidth, grid2.MinSize().Height), grid2)
 
-	gridWrap := container.NewVBox(util.NewVFiller(0.5), gridWrap1, gridWrap2)
+	netTab.natList = widget.NewList(netTab.natListLength, netTab.natListCreate, netTab.natListUpdate)
+	netTab.natList.OnSelected = func(id widget.ListItemID) {
+		netTab.natSelected = id
+		netTab.updateNatButtons()
+	}
+	netTab.natList.OnUnselected = func(id widget.ListItemID) {
+		netTab.natSelected = -1
+		netTab.updateNatButtons()
+	}
+	netTab.natAdd = widget.NewButtonWithIcon("", theme.ContentAddIcon(), func() {
+		netTab.editNatRule(nil)
+	})
+	netTab.natEdit = widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), func() {
+		if netTab.natSelected >= 0 && netTab.natSelected < len(netTab.natRules) {
+			rule := netTab.natRules[netTab.natSelected]
+			netTab.editNatRule(&rule)
+		}
+	})
+	netTab.natDelete = widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		netTab.deleteNatRule()
+	})
+	netTab.natCollisions = widget.NewLabel("")
+	netTab.natCollisions.Importance = widget.WarningImportance
+	netTab.natCollisions.Wrapping = fyne.TextWrapWord
+	netTab.natCollisions.Hide()
+
+	netTab.natBox = container.NewVBox(
+		container.NewHBox(widget.NewLabel(lang.X("details.vm_network.natpf", "Port forwarding")), layout.NewSpacer(),
+			netTab.natAdd, netTab.natEdit, netTab.natDelete),
+		container.NewGridWrap(fyne.NewSize(formWidth, 150), netTab.natList),
+		netTab.natCollisions,
+	)
+	netTab.natBox.Hide()
+
+	gridWrap := container.NewVBox(util.NewVFiller(0.5), gridWrap1, gridWrap2, netTab.natBox)
 
 	c := container.NewVBox(container.NewHBox(gridWrap),
-		container.NewHBox(layout.NewSpacer(), netTab.apply, util.NewFiller(32, 0)))
+		container.NewHBox(layout.NewSpacer(), netTab.preview, netTab.apply, util.NewFiller(32, 0)))
 
 	netTab.tabItem = container.NewTabItem(lang.X("details.vm_network.tab",
 		fmt.Sprintf(lang.X("details.vm_network.tab.header", "Adapter %d"), index+1)), c)
@@ -169,6 +247,15 @@ func (n *NetworkTab) geNicName() string {
 	return fmt.Sprintf("nic%d", n.number+1)
 }
 
+func (n *NetworkTab) getNicConfig(v *vm.VMachine) vm.ConfigNic {
+	for _, nic := range v.Config().Nics {
+		if nic.Index == n.number+1 {
+			return nic
+		}
+	}
+	return vm.ConfigNic{Index: n.number + 1}
+}
+
 func (n *NetworkTab) UpdateBySelect() {
 	s, v := getActiveServerAndVm()
 
@@ -177,6 +264,13 @@ func (n *NetworkTab) UpdateBySelect() {
 		return
 	}
 	n.apply.Enable()
+	n.preview.Enable()
+
+	if n.cloudHint.Update(s, vm.Capability_cloudNetwork) {
+		n.network.SetOptions(n.networkOptions)
+	} else {
+		n.network.SetOptions(n.networkOptions[:len(n.networkOptions)-1])
+	}
 
 	nicName := n.geNicName()
 
@@ -184,6 +278,7 @@ func (n *NetworkTab) UpdateBySelect() {
 		n.enabled.SetChecked(false)
 		n.adjustNameField()
 		n.oldValues.enabled = false
+		n.oldValues.bandwidthGroup = ""
 	} else {
 		util.SelectEntryFromProperty(n.network, v, nicName, n.networkMapStringToIndex, &n.oldValues.network)
 		n.oldValues.enabled = true
@@ -191,8 +286,9 @@ func (n *NetworkTab) UpdateBySelect() {
 
 		n.adjustNameField()
 
+		nic := n.getNicConfig(v)
 		index := n.network.SelectedIndex()
-		name := v.Properties[nicName+"_name"]
+		name := nic.Name
 		if index == 2 || index == 4 {
 			n.nameEntry.SetText(name)
 		} else {
@@ -204,19 +300,34 @@ func (n *NetworkTab) UpdateBySelect() {
 
 		util.SelectEntryFromProperty(n.adapter, v, fmt.Sprintf("nictype%d", n.number+1), n.adapterMapStringToIndex, &n.oldValues.adapter)
 
-		util.SelectEntryFromProperty(n.promiscuous, v, nicName+"_promiscuous", n.promiscuousMapStringToIndex, &n.oldValues.promiscuous)
+		util.SelectEntryFromValue(n.promiscuous, nic.Promiscuous, n.promiscuousMapStringToIndex, &n.oldValues.promiscuous)
 
-		n.mac.SetText(v.Properties[nicName+"_mac"])
+		n.mac.SetText(nic.Mac)
 		n.oldValues.mac = n.mac.Text
 
-		util.CheckFromProperty(n.cableConnected, v, nicName+"_connected", "on", &n.oldValues.connected)
+		n.cableConnected.SetChecked(nic.CableConnected)
+		n.oldValues.connected = nic.CableConnected
+
+		n.oldValues.bandwidthGroup = nic.BandwidthGroup
+		n.setBandwidthGroups(n.bandwidthGroups)
 	}
+	// the groups are the same for all adapters
+	if n.number == 0 {
+		loadBandwidthGroups(s, v, func(groups []vm.BandwidthGroup) {
+			setBandwidthGroups(v, groups)
+		})
+	}
+	n.loadNatRules(s, v)
 	n.UpdateByStatus()
 }
 
 func (n *NetworkTab) UpdateByStatus() {
-	_, v := getActiveServerAndVm()
+	s, v := getActiveServerAndVm()
 	if v != nil {
+		// rules changed by a command or outside
+		if s != nil && !slices.Equal(n.natRules, v.GetNatRules(n.number+1)) {
+			n.loadNatRules(s, v)
+		}
 		state, err := v.GetState()
 		if err != nil {
 			return
@@ -227,10 +338,18 @@ func (n *NetworkTab) UpdateByStatus() {
 
 		case vm.RunState_running, vm.RunState_paused, vm.RunState_saved:
 			n.adjustEnable(true)
+			// limits can be changed live
+			n.bandwidthManage.Enable()
+			if state == vm.RunState_saved {
+				n.natAdd.Disable()
+				n.natEdit.Disable()
+				n.natDelete.Disable()
+			}
 
 		case vm.RunState_off, vm.RunState_aborted:
 			n.enabled.Enable()
 			n.adjustEnable(false)
+			n.bandwidthManage.Enable()
 
 		default:
 			SetStatusText(lang.X("status.unknown_vm_state", "!!! Unknown VM state !!!"), MsgError)
@@ -261,7 +380,7 @@ func (n *NetworkTab) adjustNameField() {
 		adapters, err = s.GetHostAdapters(false)
 	case 5:
 		adapters, err = s.GetNatAdapters(false)
-	case 6:
+	case 7:
 		adapters, err = s.GetCloudAdapters(false)
 	}
 	if err != nil {
@@ -304,11 +423,13 @@ func (n *NetworkTab) adjustEnable(running bool) {
 			n.adapter.Disable()
 			n.mac.Disable()
 			n.newMac.Disable()
+			n.bandwidthGroup.Disable()
 		} else {
 			n.enabled.Enable()
 			n.adapter.Enable()
 			n.mac.Enable()
 			n.newMac.Enable()
+			n.bandwidthGroup.Enable()
 		}
 		n.network.Enable()
 		n.name.Enable()
@@ -318,18 +439,26 @@ func (n *NetworkTab) adjustEnable(running bool) {
 
 		index := n.network.SelectedIndex()
 
-		if index == 0 || index == 7 || index == 4 {
+		// the rules belong to the active NAT attachment
+		if index == 0 && n.oldValues.enabled && n.oldValues.network == 0 {
+			n.natBox.Show()
+			n.updateNatButtons()
+		} else {
+			n.natBox.Hide()
+		}
+
+		if index == 0 || index == 6 || index == 4 {
 			n.promiscuous.Disable()
 		}
 		switch index {
-		case 0, 7:
+		case 0, 6:
 			n.nameEntry.Hide()
 			n.name.Show()
 			n.name.Disable()
-			if index == 7 {
+			if index == 6 {
 				n.promiscuous.Disable()
 			}
-		case 1, 3, 5, 6:
+		case 1, 3, 5, 7:
 			n.nameEntry.Hide()
 			n.name.Show()
 			n.name.Enable()
@@ -351,6 +480,8 @@ func (n *NetworkTab) adjustEnable(running bool) {
 		n.mac.Disable()
 		n.newMac.Disable()
 		n.cableConnected.Disable()
+		n.bandwidthGroup.Disable()
+		n.natBox.Hide()
 	}
 }
 
@@ -364,7 +495,13 @@ func (n *NetworkTab) DisableAll() {
 	n.mac.Disable()
 	n.newMac.Disable()
 	n.cableConnected.Disable()
+	n.bandwidthGroup.Disable()
+	n.bandwidthManage.Disable()
+	n.natAdd.Disable()
+	n.natEdit.Disable()
+	n.natDelete.Disable()
 	n.apply.Disable()
+	n.preview.Disable()
 }
 
 func (n *NetworkTab) NewMac() {
@@ -382,6 +519,10 @@ func (n *NetworkTab) NewMac() {
 
 func (n *NetworkTab) Apply() {
 	s, v := getActiveServerAndVm()
+	n.applyTo(s, v)
+}
+
+func (n *NetworkTab) applyTo(s *vm.VmServer, v *vm.VMachine) {
 	if v != nil {
 		ResetStatus()
 		if !n.enabled.Disabled() {
@@ -389,7 +530,7 @@ func (n *NetworkTab) Apply() {
 				var err error
 				if !n.enabled.Checked {
 					go func() {
-						err = v.SetNetType(&s.Client, n.number+1, vm.Net_none, VMStatusUpdateCallBack)
+						err = v.SetNetType(s, n.number+1, vm.Net_none, VMStatusUpdateCallBack)
 						if err != nil {
 							SetStatusText(fmt.Sprintf(lang.X("details.vm_net.disable.error", "Disable network for VM '%s' failed with: %s"), v.Name, err.Error()), MsgError)
 						} else {
@@ -406,7 +547,7 @@ func (n *NetworkTab) Apply() {
 					val, ok := n.networkMapIndexToType[index]
 					if ok {
 						go func() {
-							err := v.SetNetType(&s.Client, n.number+1, val, VMStatusUpdateCallBack)
+							err := v.SetNetType(s, n.number+1, val, VMStatusUpdateCallBack)
 							if err != nil {
 								SetStatusText(fmt.Sprintf(lang.X("details.vm_net.network.error", "Set network for VM '%s' failed with: %s"), v.Name, err.Error()), MsgError)
 							} else {
@@ -431,9 +572,9 @@ func (n *NetworkTab) Apply() {
 						if val != "" {
 							err = v.SetNatAdapter(&s.Client, n.number+1, val, VMStatusUpdateCallBack)
 						}
-					case 6: // CloudNetwork
+					case 7: // CloudNetwork
 						if val != "" {
-							err = v.SetCloudNetworkName(&s.Client, n.number+1, val, VMStatusUpdateCallBack)
+							err = v.SetCloudNetworkName(s, n.number+1, val, VMStatusUpdateCallBack)
 						}
 					default:
 						fmt.Println("!!! Unhandeld")
@@ -524,5 +665,235 @@ func (n *NetworkTab) Apply() {
 				}()
 			}
 		}
+		if !n.bandwidthGroup.Disabled() {
+			val := selectedBandwidthGroup(n.bandwidthGroup)
+			if val != n.oldValues.bandwidthGroup {
+				go func() {
+					err := v.SetNicBandwidthGroup(s, n.number+1, val, VMStatusUpdateCallBack)
+					if err != nil {
+						SetStatusText(fmt.Sprintf(lang.X("details.vm_net.bandwidthgroup.error", "Set bandwidth group for VM '%s' failed with: %s"), v.Name, err.Error()), MsgError)
+					} else {
+						n.oldValues.bandwidthGroup = val
+					}
+				}()
+			}
+		}
+	}
+}
+
+func (n *NetworkTab) setBandwidthGroups(groups []vm.BandwidthGroup) {
+	n.bandwidthGroups = groups
+	options := bandwidthGroupOptions(groups, vm.BandwidthGroup_network)
+	if n.oldValues.bandwidthGroup != "" && !slices.Contains(options, n.oldValues.bandwidthGroup) {
+		options = append(options, n.oldValues.bandwidthGroup)
+	}
+	n.bandwidthGroup.SetOptions(options)
+	selectBandwidthGroup(n.bandwidthGroup, n.oldValues.bandwidthGroup)
+}
+
+func (n *NetworkTab) loadNatRules(s *vm.VmServer, v *vm.VMachine) {
+	n.natRules = v.GetNatRules(n.number + 1)
+	n.natConflicts = make(map[int][]vm.NatRuleUse, len(n.natRules))
+	vms := Data.GetVms(s.UUID, true)
+	var lines []string
+	for i, rule := range n.natRules {
+		uses := vm.FindNatCollisions(vms, v, n.number+1, rule)
+		if len(uses) == 0 {
+			continue
+		}
+		n.natConflicts[i] = uses
+		for _, use := range uses {
+			lines = append(lines, fmt.Sprintf(lang.X("details.vm_network.natpf.collision", "Host port %d/%s of '%s' is also used by '%s' (adapter %d, rule '%s')"),
+				rule.HostPort, natProtocolName(rule.Protocol), rule.Name, use.VmName, use.IfNumber, use.Rule.Name))
+		}
+	}
+	if len(lines) > 0 {
+		n.natCollisions.SetText(strings.Join(lines, "\n"))
+		n.natCollisions.Show()
+	} else {
+		n.natCollisions.Hide()
+	}
+	n.natSelected = -1
+	n.natList.UnselectAll()
+	n.natList.Refresh()
+	n.updateNatButtons()
+}
+
+func (n *NetworkTab) updateNatButtons() {
+	n.natAdd.Enable()
+	if n.natSelected >= 0 && n.natSelected < len(n.natRules) {
+		n.natEdit.Enable()
+		n.natDelete.Enable()
+	} else {
+		n.natEdit.Disable()
+		n.natDelete.Disable()
 	}
 }
+
+func natProtocolName(p vm.NatProtocolType) string {
+	if p == vm.NatProtocol_udp {
+		return "udp"
+	}
+	return "tcp"
+}
+
+func (n *NetworkTab) natListLength() int {
+	return len(n.natRules)
+}
+
+func (n *NetworkTab) natListCreate() fyne.CanvasObject {
+	icon := widget.NewIcon(nil)
+	return container.NewBorder(nil, nil, icon, nil, widget.NewLabel(""))
+}
+
+func (n *NetworkTab) natListUpdate(id widget.ListItemID, o fyne.CanvasObject) {
+	cont, ok := o.(*fyne.Container)
+	if !ok || id >= len(n.natRules) {
+		return
+	}
+	var label *widget.Label
+	var icon *widget.Icon
+	for _, item := range cont.Objects {
+		switch w := item.(type) {
+		case *widget.Label:
+			label = w
+		case *widget.Icon:
+			icon = w
+		}
+	}
+	if label == nil || icon == nil {
+		return
+	}
+	rule := n.natRules[id]
+	label.SetText(fmt.Sprintf("%s - %s  %s:%d -> %s:%d", rule.Name, strings.ToUpper(natProtocolName(rule.Protocol)),
+		rule.HostIP, rule.HostPort, rule.GuestIP, rule.GuestPort))
+	if len(n.natConflicts[id]) > 0 {
+		icon.SetResource(theme.WarningIcon())
+	} else {
+		icon.SetResource(nil)
+	}
+}
+
+// nil adds a new rule
+func (n *NetworkTab) editNatRule(old *vm.NatRule) {
+	s, v := getActiveServerAndVm()
+	if s == nil || v == nil {
+		return
+	}
+	nic := n.number + 1
+
+	name := widget.NewEntry()
+	protocol := widget.NewSelect([]string{"TCP", "UDP"}, nil)
+	protocol.SetSelectedIndex(0)
+	hostIP := widget.NewEntry()
+	hostIP.SetPlaceHolder(lang.X("details.vm_network.natpf.anyaddress", "any address"))
+	hostPort := widget.NewEntry()
+	hostPort.OnChanged = util.GetNumberFilter(hostPort, nil)
+	guestIP := widget.NewEntry()
+	guestIP.SetPlaceHolder(lang.X("details.vm_network.natpf.anyaddress", "any address"))
+	guestPort := widget.NewEntry()
+	guestPort.OnChanged = util.GetNumberFilter(guestPort, nil)
+	if old != nil {
+		name.SetText(old.Name)
+		if old.Protocol == vm.NatProtocol_udp {
+			protocol.SetSelectedIndex(1)
+		}
+		hostIP.SetText(old.HostIP)
+		hostPort.SetText(strconv.Itoa(old.HostPort))
+		guestIP.SetText(old.GuestIP)
+		guestPort.SetText(strconv.Itoa(old.GuestPort))
+	}
+
+	c := container.New(layout.NewFormLayout(),
+		widget.NewLabel(lang.X("details.vm_network.natpf.name", "Name")), name,
+		widget.NewLabel(lang.X("details.vm_network.natpf.protocol", "Protocol")), protocol,
+		widget.NewLabel(lang.X("details.vm_network.natpf.hostip", "Host IP")), hostIP,
+		widget.NewLabel(lang.X("details.vm_network.natpf.hostport", "Host port")), hostPort,
+		widget.NewLabel(lang.X("details.vm_network.natpf.guestip", "Guest IP")), guestIP,
+		widget.NewLabel(lang.X("details.vm_network.natpf.guestport", "Guest port")), guestPort,
+	)
+	title := lang.X("details.vm_network.natpf.add.title", "Add port forwarding rule")
+	if old != nil {
+		title = lang.X("details.vm_network.natpf.edit.title", "Edit port forwarding rule")
+	}
+	dia := dialog.NewCustomConfirm(title, lang.X("import.ok", "Ok"), lang.X("import.cancel", "Cancel"), c,
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			rule := vm.NatRule{
+				Name:    name.Text,
+				HostIP:  hostIP.Text,
+				GuestIP: guestIP.Text,
+			}
+			if protocol.SelectedIndex() == 1 {
+				rule.Protocol = vm.NatProtocol_udp
+			}
+			rule.HostPort, _ = strconv.Atoi(hostPort.Text)
+			rule.GuestPort, _ = strconv.Atoi(guestPort.Text)
+			err := rule.Validate()
+			if err != nil {
+				SetStatusText(fmt.Sprintf(lang.X("details.vm_network.natpf.invalid", "Invalid port forwarding rule: %s"), err.Error()), MsgError)
+				return
+			}
+			doIt := func() {
+				go func() {
+					if old != nil {
+						err := v.DeleteNatRule(&s.Client, nic, old.Name, nil)
+						if err != nil {
+							SetStatusText(fmt.Sprintf(lang.X("details.vm_network.natpf.delete.error", "Deleting port forwarding rule '%s' of VM '%s' failed with: %s"), old.Name, v.Name, err.Error()), MsgError)
+							return
+						}
+					}
+					err := v.AddNatRule(&s.Client, nic, rule, VMStatusUpdateCallBack)
+					if err != nil {
+						SetStatusText(fmt.Sprintf(lang.X("details.vm_network.natpf.add.error", "Adding port forwarding rule '%s' to VM '%s' failed with: %s"), rule.Name, v.Name, err.Error()), MsgError)
+					}
+				}()
+			}
+			uses := vm.FindNatCollisions(Data.GetVms(s.UUID, true), v, nic, rule)
+			if old != nil {
+				uses = slices.DeleteFunc(uses, func(use vm.NatRuleUse) bool {
+					return use.VmUUID == v.UUID && use.IfNumber == nic && use.Rule.Name == old.Name
+				})
+			}
+			if len(uses) == 0 {
+				doIt()
+				return
+			}
+			dialog.ShowConfirm(lang.X("details.vm_network.natpf.collision.title", "Host port already in use"),
+				fmt.Sprintf(lang.X("details.vm_network.natpf.collision.msg", "Host port %d/%s is already used by '%s' (adapter %d).\nAdd the rule anyway ?"),
+					rule.HostPort, natProtocolName(rule.Protocol), uses[0].VmName, uses[0].IfNumber),
+				func(ok bool) {
+					if ok {
+						doIt()
+					}
+				}, Gui.MainWindow)
+		}, Gui.MainWindow)
+	si := Gui.MainWindow.Canvas().Size()
+	var windowScale float32 = 0.5
+	dia.Resize(fyne.NewSize(si.Width*windowScale, dia.MinSize().Height*1.1))
+	dia.Show()
+	Gui.MainWindow.Canvas().Focus(name)
+}
+
+func (n *NetworkTab) deleteNatRule() {
+	s, v := getActiveServerAndVm()
+	if s == nil || v == nil || n.natSelected < 0 || n.natSelected >= len(n.natRules) {
+		return
+	}
+	rule := n.natRules[n.natSelected]
+	dialog.ShowConfirm(lang.X("details.vm_network.natpf.delete.title", "Delete port forwarding rule"),
+		fmt.Sprintf(lang.X("details.vm_network.natpf.delete.msg", "Do you really want to delete the rule '%s' of '%s' ?"), rule.Name, v.Name),
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			go func() {
+				err := v.DeleteNatRule(&s.Client, n.number+1, rule.Name, VMStatusUpdateCallBack)
+				if err != nil {
+					SetStatusText(fmt.Sprintf(lang.X("details.vm_network.natpf.delete.error", "Deleting port forwarding rule '%s' of VM '%s' failed with: %s"), rule.Name, v.Name, err.Error()), MsgError)
+				}
+			}()
+		}, Gui.MainWindow)
+}
@@ -26,7 +26,6 @@ package main
 
 import (
 	"fmt"
-	"strconv"
 
 	"bytemystery-com/vboxssh/util"
 
@@ -131,16 +130,11 @@ func NewRdpTab() *RdpTab {
 
 func (rdp *RdpTab) setUsedPort(v *vm.VMachine) {
 	// Used port
-	str, ok := v.Properties["vrdeport"]
-	if ok {
-		port, err := strconv.Atoi(str)
-		if err == nil {
-			if port == -1 {
-				rdp.usedPort.SetText(lang.X("details.vm_rdp.used_port.unused", "-----"))
-			} else {
-				rdp.usedPort.SetText(fmt.Sprintf("%d", port))
-			}
-		}
+	port := v.Config().Vrde.Port
+	if port == -1 {
+		rdp.usedPort.SetText(lang.X("details.vm_rdp.used_port.unused", "-----"))
+	} else {
+		rdp.usedPort.SetText(fmt.Sprintf("%d", port))
 	}
 }
 
@@ -174,11 +168,9 @@ func (rdp *RdpTab) UpdateBySelect() {
 	util.SelectEntryFromProperty(rdp.auth, v, "vrdeauthtype", rdp.authMapStringToIndex, &rdp.oldValues.auth)
 
 	// Ports
-	str, ok := v.Properties["vrdeports"]
-	if ok {
-		rdp.ports.SetText(str)
-		rdp.oldValues.ports = str
-	}
+	ports := v.Config().Vrde.Ports
+	rdp.ports.SetText(ports)
+	rdp.oldValues.ports = ports
 
 	rdp.setUsedPort(v)
 }
@@ -33,6 +33,7 @@ import (
 
 	"bytemystery-com/vboxssh/filebrowser"
 	"bytemystery-com/vboxssh/util"
+	"bytemystery-com/vboxssh/vm"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
@@ -67,12 +68,13 @@ type SharedFolderTab struct {
 	toolBarItemEdit   *widget.ToolbarAction
 
 	apply   *widget.Button
+	preview *widget.Button
 	tabItem *container.TabItem
 
 	ssfData []*ssfDataType
 
 	selectedItem *ssfDataType
-	isV6         bool
+	noGlobal     bool
 }
 
 var _ DetailsInterface = (*SharedFolderTab)(nil)
@@ -84,6 +86,12 @@ func NewSharedFolderTab() *SharedFolderTab {
 		ssfTab.Apply()
 	})
 	ssfTab.apply.Importance = widget.HighImportance
+	ssfTab.preview = newPreviewButton(&ssfTab, func() func() {
+		old := ssfTab.oldValues
+		return func() {
+			ssfTab.oldValues = old
+		}
+	})
 
 	ssfTab.toolBarItemAdd = widget.NewToolbarAction(theme.ContentAddIcon(), func() { ssfTab.onNewSharedFolder() })
 	ssfTab.toolBarItemRemove = widget.NewToolbarAction(theme.ContentRemoveIcon(), func() { ssfTab.onRemoveSharedFolder() })
@@ -94,7 +102,7 @@ func NewSharedFolderTab() *SharedFolderTab {
 	ssfTab.list.OnSelected = ssfTab.listOnSelected
 	ssfTab.list.OnUnselected = ssfTab.listOnUnSelected
 
-	c := container.NewBorder(ssfTab.toolBar, nil, nil, container.NewHBox(container.NewVBox(layout.NewSpacer(), ssfTab.apply, layout.NewSpacer()), util.NewFiller(32, 0)), ssfTab.list)
+	c := container.NewBorder(ssfTab.toolBar, nil, nil, container.NewHBox(container.NewVBox(layout.NewSpacer(), ssfTab.preview, ssfTab.apply, layout.NewSpacer()), util.NewFiller(32, 0)), ssfTab.list)
 
 	ssfTab.tabItem = container.NewTabItem(lang.X("details.vm_info.tab.ssf", "Shared folder"), c)
 	return &ssfTab
@@ -180,7 +188,7 @@ func (ssf *SharedFolderTab) doEditSharedFolder(sd *ssfDataType, fOk func()) {
 	}
 
 	hostPathBrowse := widget.NewButtonWithIcon(lang.X("details.vm_ssf.hostpath.browse", "Browse..."), theme.SearchIcon(), func() {
-		sftp := filebrowser.NewSftpBrowser(s.Client.Client, hostPath.Text, nil,
+		sftp := filebrowser.NewSftpBrowser(s.SshClient(), hostPath.Text, nil,
 			lang.X("details.vm_ssf.hostpath.browse.title", "Select folder for sharing"), filebrowser.SftpFileBrowserMode_selectdir)
 		sftp.Show(Gui.MainWindow, 0.75, func(file string, fi os.FileInfo, dir string) {
 			hostPath.SetText(file)
@@ -215,12 +223,9 @@ func (ssf *SharedFolderTab) doEditSharedFolder(sd *ssfDataType, fOk func()) {
 		global.SetChecked(true)
 	}
 
-	var hb *fyne.Container
-	if ssf.isV6 {
-		hb = container.NewHBox(autoMount)
-	} else {
-		hb = container.NewHBox(autoMount, global)
-	}
+	globalHint := NewCapabilityHint()
+	enableByCapability(global, globalHint, s, vm.Capability_globalSharedFolder)
+	hb := container.NewHBox(autoMount, global, globalHint)
 
 	c := container.New(layout.NewFormLayout(),
 		widget.NewLabel(lang.X("details.vm_ssf.name", "Name")), name,
@@ -290,7 +295,7 @@ func (ssf *SharedFolderTab) listCreateObject() fyne.CanvasObject {
 	text := canvas.NewText("", theme.Color(theme.ColorNameForeground))
 	text.Refresh()
 
-	if ssf.isV6 {
+	if ssf.noGlobal {
 		return container.NewHBox(icon1, icon2, text)
 	} else {
 		return container.NewHBox(icon1, icon2, icon3, text)
@@ -306,7 +311,7 @@ func (ssf *SharedFolderTab) listUpdateItem(id widget.ListItemID, o fyne.CanvasOb
 	imap := make(map[string]*canvas.Image)
 
 	i := 0
-	if !ssf.isV6 {
+	if !ssf.noGlobal {
 		icon, ok := cont.Objects[i].(*canvas.Image)
 		if !ok {
 			return
@@ -336,7 +341,7 @@ func (ssf *SharedFolderTab) listUpdateItem(id widget.ListItemID, o fyne.CanvasOb
 	i++
 
 	item := ssf.ssfData[id]
-	if !ssf.isV6 {
+	if !ssf.noGlobal {
 		if item.global {
 			imap["global"].Resource = Gui.IconGlobal
 		} else {
@@ -374,40 +379,22 @@ func (ssf *SharedFolderTab) UpdateBySelect() {
 	}
 
 	ssf.apply.Enable()
+	ssf.preview.Enable()
 	v.UpdateStatusEx(&s.Client)
-	maj, _ := s.GetVmMajorVersion()
-	if maj == 6 {
-		ssf.isV6 = true
-	} else {
-		ssf.isV6 = false
-	}
+	ssf.noGlobal = !s.HasCapability(vm.Capability_globalSharedFolder)
 
 	ssf.ssfData = ssf.ssfData[:0]
-	if v.Properties["ssf"] == "yes" {
-		index := 1
-		for {
-			name, ok := v.Properties[fmt.Sprintf("ssfName%d", index)]
-			if !ok {
-				break
-			}
-			s := ssfDataType{}
-			s.name = name
-			s.hostPath = v.Properties[fmt.Sprintf("ssfHostPath%d", index)]
-			if v.Properties[fmt.Sprintf("ssfMapping%d", index)] == "global" {
-				s.global = true
-			}
-			if v.Properties[fmt.Sprintf("ssfReadOnly%d", index)] == "true" {
-				s.readOnly = true
-			}
-			if v.Properties[fmt.Sprintf("ssfAutoMount%d", index)] == "true" {
-				s.autoMount = true
-			}
-			s.mountPoint = v.Properties[fmt.Sprintf("ssfMountPoint%d", index)]
-			ssf.ssfData = append(ssf.ssfData, &s)
-			index++
-		}
-		ssf.sort()
+	for _, item := range v.Config().SharedFolders {
+		ssf.ssfData = append(ssf.ssfData, &ssfDataType{
+			name:       item.Name,
+			hostPath:   item.HostPath,
+			mountPoint: item.MountPoint,
+			global:     item.Global,
+			readOnly:   item.ReadOnly,
+			autoMount:  item.AutoMount,
+		})
 	}
+	ssf.sort()
 	ssf.list.UnselectAll()
 	ssf.list.Refresh()
 	if len(ssf.ssfData) > 0 {
@@ -509,6 +496,7 @@ func (ssf *SharedFolderTab) UpdateByStatus() {
 
 func (ssf *SharedFolderTab) DisableAll() {
 	ssf.apply.Disable()
+	ssf.preview.Disable()
 }
 
 func (ssf *SharedFolderTab) saveOldSharedFolderConfig() {
@@ -540,6 +528,10 @@ func (ssf *SharedFolderTab) updateTaskBarButtons() {
 
 func (ssf *SharedFolderTab) Apply() {
 	s, v := getActiveServerAndVm()
+	ssf.applyTo(s, v)
+}
+
+func (ssf *SharedFolderTab) applyTo(s *vm.VmServer, v *vm.VMachine) {
 	if v != nil {
 		ResetStatus()
 	}
@@ -25,7 +25,10 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"bytemystery-com/vboxssh/util"
 
@@ -43,11 +46,11 @@ import (
 )
 
 type SnapshotItem struct {
-	uuid        string
-	name        string
-	description string
-	childs      []*SnapshotItem
-	isCurrent   bool
+	uuid      string
+	name      string
+	node      *vm.SnapshotNode
+	childs    []*SnapshotItem
+	isCurrent bool
 }
 
 type SnapshotTab struct {
@@ -55,11 +58,13 @@ type SnapshotTab struct {
 	tabItem *container.TabItem
 
 	toolTake    *widget.ToolbarAction
+	toolEdit    *widget.ToolbarAction
 	toolDelete  *widget.ToolbarAction
 	toolRestore *widget.ToolbarAction
 
 	toolBar *widget.Toolbar
 
+	snapTree    *vm.SnapshotTree
 	snapshots   []*SnapshotItem
 	snapshotMap map[string]*SnapshotItem
 
@@ -78,10 +83,11 @@ func NewSnapshotTab() *SnapshotTab {
 	// snapshot.tree.OnUnselected = snapshot.treeUnselected
 
 	snapshot.toolTake = widget.NewToolbarAction(theme.MediaPhotoIcon(), snapshot.take)
+	snapshot.toolEdit = widget.NewToolbarAction(theme.DocumentCreateIcon(), snapshot.edit)
 	snapshot.toolDelete = widget.NewToolbarAction(theme.DeleteIcon(), snapshot.delete)
 	snapshot.toolRestore = widget.NewToolbarAction(theme.ContentUndoIcon(), snapshot.restore)
 
-	snapshot.toolBar = widget.NewToolbar(snapshot.toolTake, snapshot.toolRestore, snapshot.toolDelete)
+	snapshot.toolBar = widget.NewToolbar(snapshot.toolTake, snapshot.toolEdit, snapshot.toolRestore, snapshot.toolDelete)
 
 	gridWrap := container.NewBorder(snapshot.toolBar, nil, nil, util.NewFiller(32, 0), snapshot.tree)
 
@@ -116,14 +122,13 @@ func (snap *SnapshotTab) take() {
 			go func() {
 				uuid := uuid.NewString()
 				name := fmt.Sprintf(lang.X("snapshot.take.msg", "Take snapshot of '%s'"), v.Name)
-				Gui.TasksInfos.AddTask(uuid, name, "")
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				Gui.TasksInfos.AddTask(uuid, name, "", cancel)
 				OpenTaskDetails()
 				ResetStatus()
 
-				err := v.TakeSnapshot(&s.Client, nameEntry.Text, "", false, util.WriterFunc(func(p []byte) (int, error) {
-					Gui.TasksInfos.UpdateTaskStatus(uuid, string(p), true)
-					return len(p), nil
-				}))
+				err := v.TakeSnapshot(s.Client.WithContext(ctx), nameEntry.Text, "", false, Gui.TasksInfos.NewProgressWriter(uuid))
 				if err != nil {
 					t := fmt.Sprintf(lang.X("snapshot.take.done.error", "Snapshot '%s' of '%s' failed"), nameEntry.Text, v.Name)
 					SetStatusText(t, MsgError)
@@ -143,28 +148,77 @@ func (snap *SnapshotTab) take() {
 	Gui.MainWindow.Canvas().Focus(nameEntry)
 }
 
+func (snap *SnapshotTab) edit() {
+	s, v := getActiveServerAndVm()
+	if s == nil || v == nil || snap.selectedItem == nil || snap.selectedItem.node == nil {
+		return
+	}
+	node := snap.selectedItem.node
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(node.Name)
+	nameEntry.Validator = func(str string) error {
+		if str == "" {
+			return errors.New("name is empty")
+		}
+		return nil
+	}
+	descriptionEntry := widget.NewMultiLineEntry()
+	descriptionEntry.SetText(node.Description)
+	descriptionEntry.SetMinRowsVisible(4)
+	dia := dialog.NewCustomConfirm(lang.X("snapshot.edit.title", "Edit snapshot"),
+		lang.X("snapshot.take.ok", "Ok"),
+		lang.X("snapshot.take.cancel", "Cancel"),
+		container.New(layout.NewFormLayout(),
+			widget.NewLabel(lang.X("snapshot.take.name", "Name")), nameEntry,
+			widget.NewLabel(lang.X("snapshot.edit.description", "Description")), descriptionEntry,
+		), func(ok bool) {
+			if !ok || nameEntry.Validate() != nil {
+				return
+			}
+			if nameEntry.Text == node.Name && descriptionEntry.Text == node.Description {
+				return
+			}
+			go func() {
+				err := v.EditSnapshot(&s.Client, node.UUID, nameEntry.Text, descriptionEntry.Text)
+				if err != nil {
+					SetStatusText(fmt.Sprintf(lang.X("snapshot.edit.error", "Editing snapshot '%s' of '%s' failed with: %s"), node.Name, v.Name, err.Error()), MsgError)
+				} else {
+					snap.updateAfterSnapshotAction(s, v)
+				}
+			}()
+		}, Gui.MainWindow)
+	si := Gui.MainWindow.Canvas().Size()
+	var windowScale float32 = 0.5
+	dia.Resize(fyne.NewSize(si.Width*windowScale, dia.MinSize().Height*1.1))
+	dia.Show()
+	Gui.MainWindow.Canvas().Focus(nameEntry)
+}
+
 func (snap *SnapshotTab) restore() {
 	s, v := getActiveServerAndVm()
-	if s == nil || v == nil || snap.selectedItem == nil || snap.selectedItem.isCurrent {
+	if s == nil || v == nil || snap.selectedItem == nil {
+		return
+	}
+	if snap.selectedItem.isCurrent {
+		snap.restoreCurrent(s, v)
 		return
 	}
 	dialog.ShowConfirm(lang.X("snapsot.restore.title", "Restore snapshot"),
 		fmt.Sprintf(lang.X("snapsot.restore.msg", "Do you really want to restore to the snapshot\n'%s' for the virtual machine\n'%s' on the server '%s' ?"),
-			snap.selectedItem.name, v.Name, util.GetServerAddressAsString(s.Client.Client)), func(oK bool) {
+			snap.selectedItem.name, v.Name, util.GetServerAddressAsString(s.SshClient())), func(oK bool) {
 			if !oK {
 				return
 			}
 			go func() {
 				uuid := uuid.NewString()
 				name := fmt.Sprintf(lang.X("snapshot.restore.msg", "Restore to snapshot '%s'"), snap.selectedItem.name)
-				Gui.TasksInfos.AddTask(uuid, name, "")
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				Gui.TasksInfos.AddTask(uuid, name, "", cancel)
 				OpenTaskDetails()
 				ResetStatus()
 
-				err := v.RestoreSnapshot(&s.Client, snap.selectedItem.uuid, util.WriterFunc(func(p []byte) (int, error) {
-					Gui.TasksInfos.UpdateTaskStatus(uuid, string(p), true)
-					return len(p), nil
-				}))
+				err := v.RestoreSnapshot(s.Client.WithContext(ctx), snap.selectedItem.uuid, Gui.TasksInfos.NewProgressWriter(uuid))
 				if err != nil {
 					t := fmt.Sprintf(lang.X("snapshot.restore.done.error", "Restoring to snapshot '%s' of '%s' failed"), snap.selectedItem, v.Name)
 					SetStatusText(t, MsgError)
@@ -179,35 +233,105 @@ func (snap *SnapshotTab) restore() {
 		}, Gui.MainWindow)
 }
 
+// Discards the current state
+func (snap *SnapshotTab) restoreCurrent(s *vm.VmServer, v *vm.VMachine) {
+	if snap.snapTree == nil || snap.snapTree.Current == nil {
+		return
+	}
+	current := snap.snapTree.Current
+	dialog.ShowConfirm(lang.X("snapsot.restorecurrent.title", "Discard current state"),
+		fmt.Sprintf(lang.X("snapsot.restorecurrent.msg", "Do you really want to discard the current state and restore the snapshot\n'%s' for the virtual machine\n'%s' on the server '%s' ?"),
+			current.Name, v.Name, util.GetServerAddressAsString(s.SshClient())), func(oK bool) {
+			if !oK {
+				return
+			}
+			go func() {
+				uuid := uuid.NewString()
+				name := fmt.Sprintf(lang.X("snapshot.restore.msg", "Restore to snapshot '%s'"), current.Name)
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				Gui.TasksInfos.AddTask(uuid, name, "", cancel)
+				OpenTaskDetails()
+				ResetStatus()
+
+				err := v.RestoreCurrentSnapshot(s.Client.WithContext(ctx), Gui.TasksInfos.NewProgressWriter(uuid))
+				if err != nil {
+					t := fmt.Sprintf(lang.X("snapshot.restore.done.error", "Restoring to snapshot '%s' of '%s' failed"), current.Name, v.Name)
+					SetStatusText(t, MsgError)
+					Gui.TasksInfos.AbortTask(uuid, t, false)
+				} else {
+					t := fmt.Sprintf(lang.X("snapshot.restore.done.ok", "Restored to snapshot '%s' of '%s'"), current.Name, v.Name)
+					Gui.TasksInfos.FinishTask(uuid, t, false)
+					SendNotification(lang.X("snapsot.restore.notification.title", "Snapshot restored"), t)
+					snap.updateAfterSnapshotAction(s, v)
+				}
+			}()
+		}, Gui.MainWindow)
+}
+
+// the selected snapshot and its descendants which can be deleted together with it
+func (snap *SnapshotTab) deleteCandidates(node *vm.SnapshotNode) []*vm.SnapshotNode {
+	list := []*vm.SnapshotNode{node}
+	for len(node.Children) == 1 {
+		node = node.Children[0]
+		if len(node.Children) > 1 {
+			break
+		}
+		list = append(list, node)
+	}
+	return list
+}
+
 func (snap *SnapshotTab) delete() {
 	s, v := getActiveServerAndVm()
-	if s == nil || v == nil || snap.selectedItem == nil || snap.selectedItem.isCurrent {
+	if s == nil || v == nil || snap.selectedItem == nil || snap.selectedItem.node == nil {
 		return
 	}
+	tree := snap.snapTree
+	from := snap.selectedItem.node
+	candidates := snap.deleteCandidates(from)
+
+	names := make([]string, 0, len(candidates))
+	for _, item := range candidates {
+		names = append(names, item.Name)
+	}
+	upTo := widget.NewSelect(names, nil)
+	upTo.SetSelectedIndex(0)
+
+	c := container.NewVBox(widget.NewLabel(fmt.Sprintf(lang.X("snapsot.delete.msg", "Do you really want to delete the snapshot\n'%s' tof the virtual machine\n'%s' on the server '%s' ?"),
+		from.Name, v.Name, util.GetServerAddressAsString(s.SshClient()))))
+	if len(candidates) > 1 {
+		c.Add(container.New(layout.NewFormLayout(),
+			widget.NewLabel(lang.X("snapsot.delete.upto", "Delete up to")), upTo))
+	}
 
-	dialog.ShowConfirm(lang.X("snapsot.delete.title", "Delete snapshot"),
-		fmt.Sprintf(lang.X("snapsot.delete.msg", "Do you really want to delete the snapshot\n'%s' tof the virtual machine\n'%s' on the server '%s' ?"),
-			snap.selectedItem.name, v.Name, util.GetServerAddressAsString(s.Client.Client)), func(oK bool) {
+	dialog.ShowCustomConfirm(lang.X("snapsot.delete.title", "Delete snapshot"),
+		lang.X("snapshot.take.ok", "Ok"),
+		lang.X("snapshot.take.cancel", "Cancel"), c, func(oK bool) {
 			if !oK {
 				return
 			}
+			to := candidates[max(upTo.SelectedIndex(), 0)]
 			go func() {
 				uuid := uuid.NewString()
-				name := fmt.Sprintf(lang.X("snapshot.delete.msg", "Delete snapshot '%s'"), snap.selectedItem.name)
-				Gui.TasksInfos.AddTask(uuid, name, "")
+				name := fmt.Sprintf(lang.X("snapshot.delete.msg", "Delete snapshot '%s'"), from.Name)
+				if to != from {
+					name = fmt.Sprintf(lang.X("snapshot.delete.range.msg", "Delete snapshots '%s' to '%s'"), from.Name, to.Name)
+				}
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				Gui.TasksInfos.AddTask(uuid, name, "", cancel)
 				OpenTaskDetails()
 				ResetStatus()
 
-				err := v.DeleteSnapshot(&s.Client, snap.selectedItem.uuid, util.WriterFunc(func(p []byte) (int, error) {
-					Gui.TasksInfos.UpdateTaskStatus(uuid, string(p), true)
-					return len(p), nil
-				}))
+				err := v.DeleteSnapshotRange(s.Client.WithContext(ctx), tree, from.UUID, to.UUID, Gui.TasksInfos.NewProgressWriter(uuid))
 				if err != nil {
-					t := fmt.Sprintf(lang.X("snapshot.delete.done.error", "Deleting snapshot '%s' of '%s' failed"), snap.selectedItem, name, v.Name)
-					SetStatusText(t, MsgError)
+					t := fmt.Sprintf(lang.X("snapshot.delete.done.error", "Deleting snapshot '%s' of '%s' failed"), from.Name, v.Name)
+					SetStatusText(t+": "+err.Error(), MsgError)
 					Gui.TasksInfos.AbortTask(uuid, t, false)
+					snap.updateAfterSnapshotAction(s, v)
 				} else {
-					t := fmt.Sprintf(lang.X("snapshot.delete.done.ok", "Snapshot '%s' was deletd from '%s'"), snap.selectedItem.name, v.Name)
+					t := fmt.Sprintf(lang.X("snapshot.delete.done.ok", "Snapshot '%s' was deletd from '%s'"), from.Name, v.Name)
 					Gui.TasksInfos.FinishTask(uuid, t, false)
 					SendNotification(lang.X("snapsot.delete.notification.title", "Snapshot deleted"), t)
 					snap.updateAfterSnapshotAction(s, v)
@@ -277,7 +401,7 @@ func (snap *SnapshotTab) treeUpdate(id widget.TreeNodeID, branch bool, o fyne.Ca
 		return
 	}
 
-	text.Text = s.name
+	text.Text = snap.itemLabel(s)
 	if s.isCurrent {
 		text.Color = theme.Color(theme.ColorNamePrimary)
 		text.TextStyle = fyne.TextStyle{
@@ -291,32 +415,36 @@ func (snap *SnapshotTab) treeUpdate(id widget.TreeNodeID, branch bool, o fyne.Ca
 	text.Refresh()
 }
 
-func (snap *SnapshotTab) getChilds(v *vm.VMachine, token string, snapMap map[string]*SnapshotItem) []*SnapshotItem {
-	index := 1
-	list := make([]*SnapshotItem, 0, 10)
-	for {
-		token2 := fmt.Sprintf("%s-%d", token, index)
-		str, ok := v.Properties["SnapshotName"+token2]
-		if !ok {
-			break
+// name with time stamp and online flag if known
+func (snap *SnapshotTab) itemLabel(s *SnapshotItem) string {
+	var infos []string
+	if s.node != nil {
+		if !s.node.TimeStamp.IsZero() {
+			infos = append(infos, s.node.TimeStamp.Local().Format("2006-01-02 15:04:05"))
 		}
-		uuid, ok := v.Properties["SnapshotUUID"+token2]
-		if !ok {
-			break
+		if s.node.Online {
+			infos = append(infos, lang.X("details.vm_snapshot.online", "online"))
 		}
-		description := v.Properties["SnapshotDescription"+token2]
+	} else if s.isCurrent && snap.snapTree != nil && snap.snapTree.CurrentStateModified {
+		infos = append(infos, lang.X("details.vm_snapshot.modified", "modified"))
+	}
+	if len(infos) == 0 {
+		return s.name
+	}
+	return s.name + "  (" + strings.Join(infos, ", ") + ")"
+}
 
-		childs := snap.getChilds(v, token2, snapMap)
+func (snap *SnapshotTab) getChilds(nodes []*vm.SnapshotNode, snapMap map[string]*SnapshotItem) []*SnapshotItem {
+	list := make([]*SnapshotItem, 0, len(nodes))
+	for _, item := range nodes {
 		newItem := SnapshotItem{
-			name:        str,
-			description: description,
-			uuid:        uuid,
-			childs:      childs,
+			name:   item.Name,
+			uuid:   item.UUID,
+			node:   item,
+			childs: snap.getChilds(item.Children, snapMap),
 		}
 		list = append(list, &newItem)
-		snapMap[uuid] = &newItem
-
-		index++
+		snapMap[newItem.uuid] = &newItem
 	}
 	return list
 }
@@ -327,27 +455,56 @@ func (snap *SnapshotTab) UpdateBySelect() {
 	if s == nil || v == nil {
 		return
 	}
+	tree := vm.NewSnapshotTree(v.Config().Snapshots)
+	snap.setTree(tree)
+
+	// time stamps and online flags need the settings file - they are loaded
+	// into a new tree which replaces the shown one
+	if tree.Root != nil {
+		go func() {
+			loaded, err := v.SnapshotTree(&s.Client)
+			fyne.Do(func() {
+				if snap.snapTree != tree {
+					return
+				}
+				if err != nil {
+					SetStatusText(fmt.Sprintf(lang.X("snapshot.details.error", "Reading the snapshot details of '%s' failed with: %s"), v.Name, err.Error()), MsgError)
+					return
+				}
+				var selected string
+				if snap.selectedItem != nil && !snap.selectedItem.isCurrent {
+					selected = snap.selectedItem.uuid
+				}
+				snap.setTree(loaded)
+				if snap.snapshotMap[selected] != nil {
+					snap.tree.Select(selected)
+				}
+			})
+		}()
+	}
+}
+
+func (snap *SnapshotTab) setTree(tree *vm.SnapshotTree) {
+	snap.snapTree = tree
+	snap.selectedItem = nil
+	snap.tree.UnselectAll()
+
 	ss := SnapshotItem{}
 	ss.name = lang.X("details.vm_snapshot.current", "Current state")
-	ss.uuid = v.Properties["CurrentSnapshotUUID"]
+	ss.uuid = uuid.NewString()
 	ss.isCurrent = true
 
 	snap.snapshots = snap.snapshots[:0]
 	clear(snap.snapshotMap)
 
-	str, ok := v.Properties["SnapshotName"]
-	if ok {
-		newItem := SnapshotItem{
-			name: str,
-			uuid: v.Properties["SnapshotUUID"],
-		}
-		newItem.childs = snap.getChilds(v, "", snap.snapshotMap)
-		snap.snapshots = append(snap.snapshots, &newItem)
-		snap.snapshotMap[newItem.uuid] = &newItem
+	if tree.Root != nil {
+		snap.snapshots = snap.getChilds([]*vm.SnapshotNode{tree.Root}, snap.snapshotMap)
 	}
 
-	p := snap.snapshotMap[ss.uuid]
-	ss.uuid = uuid.NewString()
+	var p *SnapshotItem
+	if tree.Current != nil {
+		p = snap.snapshotMap[tree.Current.UUID]
+	}
 	if p != nil {
 		p.childs = append(p.childs, &ss)
 	} else {
@@ -368,6 +525,7 @@ func (snap *SnapshotTab) UpdateByStatus() {
 
 func (snap *SnapshotTab) DisableAll() {
 	snap.toolTake.Disable()
+	snap.toolEdit.Disable()
 	snap.toolRestore.Disable()
 	snap.toolDelete.Disable()
 }
@@ -378,13 +536,21 @@ func (snap *SnapshotTab) updateToolbarButtons() {
 		snap.toolDelete.Disable()
 		snap.toolRestore.Disable()
 		snap.toolTake.Disable()
+		snap.toolEdit.Disable()
 		return
 	}
 	snap.toolTake.Enable()
+	if snap.selectedItem != nil && snap.selectedItem.node != nil {
+		snap.toolEdit.Enable()
+	} else {
+		snap.toolEdit.Disable()
+	}
+	// restoring the current state goes back to the current snapshot
+	hasCurrent := snap.snapTree != nil && snap.snapTree.Current != nil
 	state, err := v.GetState()
 	if err == nil {
 		if (state == vm.RunState_aborted || state == vm.RunState_off) &&
-			snap.selectedItem != nil && !snap.selectedItem.isCurrent {
+			snap.selectedItem != nil && (!snap.selectedItem.isCurrent || hasCurrent) {
 			snap.toolRestore.Enable()
 		} else {
 			snap.toolRestore.Disable()
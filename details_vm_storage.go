@@ -67,6 +67,8 @@ type StorageMedium struct {
 	hotpluggable  bool
 	discard       bool
 	isLive        bool
+	// empty if none - not part of isEqual, the group is changed without a new attach
+	bandwidthGroup string
 
 	// will be used only by Apply
 	state StorageMediumStateType
@@ -160,12 +162,17 @@ type StorageContent struct {
 	storageContent *container.Split
 	tabItem        *container.TabItem
 	apply          *widget.Button
+	preview        *widget.Button
 
 	ssd    *widget.Check
 	isLive *widget.Check
 	device *widget.Entry
 	port   *widget.Entry
 
+	bandwidthGroup  *widget.Select
+	bandwidthManage *widget.Button
+	bandwidthGroups []vm.BandwidthGroup
+
 	name     *widget.Entry
 	bootable *widget.Check
 
@@ -217,6 +224,13 @@ func NewStorageContent() *StorageContent {
 		st.Apply()
 	})
 	st.apply.Importance = widget.HighImportance
+	st.preview = newPreviewButton(&st, func() func() {
+		// apply stores a copy of the new controllers in the same array
+		old := slices.Clone(st.oldValues.storageControllers)
+		return func() {
+			st.oldValues.storageControllers = old
+		}
+	})
 
 	grid1 := container.New(layout.NewFormLayout())
 
@@ -228,10 +242,20 @@ func NewStorageContent() *StorageContent {
 	st.device.OnChanged = util.GetNumberFilter(st.device, st.onDeviceChanged)
 	st.port = widget.NewEntry()
 	st.port.OnChanged = util.GetNumberFilter(st.port, st.onPortChanged)
+	st.bandwidthGroup = widget.NewSelect(bandwidthGroupOptions(nil, vm.BandwidthGroup_disk), st.onBandwidthGroupChanged)
+	st.bandwidthManage = widget.NewButtonWithIcon("", theme.SettingsIcon(), func() {
+		s, v := getActiveServerAndVm()
+		if s != nil && v != nil {
+			showBandwidthGroupsDialog(s, v, func(groups []vm.BandwidthGroup) {
+				setBandwidthGroups(v, groups)
+			})
+		}
+	})
 	grid2 := container.New(layout.NewFormLayout(),
 		widget.NewLabel(lang.X("details.vm_storage.port", "Port")), st.port,
 		widget.NewLabel(lang.X("details.vm_storage.device", "Device")), st.device,
 		st.ssd, st.isLive,
+		widget.NewLabel(lang.X("details.vm_storage.bandwidthgroup", "Bandwidth group")), container.NewBorder(nil, nil, nil, st.bandwidthManage, st.bandwidthGroup),
 	)
 	gridWrap1 := container.NewGridWrap(fyne.NewSize(formWidth, grid1.MinSize().Height), grid1)
 	gridWrap2 := container.NewGridWrap(fyne.NewSize(formWidth, grid2.MinSize().Height), grid2)
@@ -239,7 +263,7 @@ func NewStorageContent() *StorageContent {
 	gridWrap := container.NewVBox(util.NewVFiller(0.5), gridWrap1, gridWrap2)
 
 	st.formMedium = container.NewVBox(container.NewHBox(gridWrap),
-		container.NewHBox(layout.NewSpacer(), st.apply, util.NewFiller(32, 0)))
+		container.NewHBox(layout.NewSpacer(), st.preview, st.apply, util.NewFiller(32, 0)))
 
 	grid1 = container.New(layout.NewFormLayout())
 
@@ -258,7 +282,7 @@ func NewStorageContent() *StorageContent {
 	gridWrap = container.NewVBox(util.NewVFiller(0.5), gridWrap1, gridWrap2)
 
 	st.formController = container.NewVBox(container.NewHBox(gridWrap),
-		container.NewHBox(layout.NewSpacer(), st.apply, util.NewFiller(32, 0)))
+		container.NewHBox(layout.NewSpacer(), st.preview, st.apply, util.NewFiller(32, 0)))
 
 	// Empty
 	grid1 = container.New(layout.NewFormLayout())
@@ -270,7 +294,7 @@ func NewStorageContent() *StorageContent {
 	gridWrap = container.NewVBox(util.NewVFiller(0.5), gridWrap1, gridWrap2)
 
 	st.formEmpty = container.NewVBox(container.NewHBox(gridWrap),
-		container.NewHBox(layout.NewSpacer(), st.apply, util.NewFiller(32, 0)))
+		container.NewHBox(layout.NewSpacer(), st.preview, st.apply, util.NewFiller(32, 0)))
 
 	st.tree = widget.NewTree(st.treeGetChilds, st.treeIsBranche, st.createCanvasObject, st.treeUpdateItem)
 	st.tree.OnSelected = st.treeOnSelected
@@ -713,6 +737,49 @@ func (st *StorageContent) onIsLiveChanged(checked bool) {
 	st.tree.Refresh()
 }
 
+func (st *StorageContent) onBandwidthGroupChanged(s string) {
+	_, m := st.getActiveControllerAndMedium()
+	if m == nil {
+		return
+	}
+	m.bandwidthGroup = selectedBandwidthGroup(st.bandwidthGroup)
+	st.tree.Refresh()
+}
+
+func (st *StorageContent) setBandwidthGroups(groups []vm.BandwidthGroup) {
+	st.bandwidthGroups = groups
+	st.updateBandwidthGroupSelect()
+}
+
+// groups which are assigned but not (yet) listed are kept selectable
+func (st *StorageContent) updateBandwidthGroupSelect() {
+	options := bandwidthGroupOptions(st.bandwidthGroups, vm.BandwidthGroup_disk)
+	_, m := st.getActiveControllerAndMedium()
+	if m != nil && m.bandwidthGroup != "" && !slices.Contains(options, m.bandwidthGroup) {
+		options = append(options, m.bandwidthGroup)
+	}
+	st.bandwidthGroup.SetOptions(options)
+	if m != nil {
+		selectBandwidthGroup(st.bandwidthGroup, m.bandwidthGroup)
+	}
+}
+
+// the groups of the attachments are read from the settings file
+func (st *StorageContent) applyAttachmentBandwidthGroups(list []vm.AttachmentBandwidthGroup) {
+	for _, ctrls := range [][]*StorageController{st.storageControllers, st.oldValues.storageControllers} {
+		for _, c := range ctrls {
+			for _, m := range c.mediums {
+				for _, item := range list {
+					if item.Controller == c.name && item.Port == m.port && item.Device == m.device {
+						m.bandwidthGroup = item.Group
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
 func (st *StorageContent) buildStorageMediumID(cUuid, mUuid string) string {
 	if mUuid == "" {
 		return cUuid
@@ -898,6 +965,9 @@ func (st *StorageContent) treeUpdateItem(id widget.TreeNodeID, branch bool, o fy
 		}
 
 		t, isSnapshot := st.buildText(*medium)
+		if medium.bandwidthGroup != "" {
+			t = fmt.Sprintf("%s  [%s]", t, medium.bandwidthGroup)
+		}
 		text.Text = t
 
 		icon.Resource = Gui.IconUnknown
@@ -1010,6 +1080,8 @@ func (st *StorageContent) updateBySelect() {
 		st.device.SetText(strconv.Itoa(m.device))
 		st.ssd.SetChecked(m.nonrotational)
 		st.isLive.SetChecked(m.isLive)
+		st.updateBandwidthGroupSelect()
+		st.bandwidthManage.Enable()
 		st.port.Enable()
 		if c.busType == vm.StorageBus_ide {
 			st.device.Enable()
@@ -1021,9 +1093,16 @@ func (st *StorageContent) updateBySelect() {
 			st.isLive.Disable()
 			st.isLive.SetChecked(false)
 			st.port.Disable()
+			st.bandwidthGroup.Disable()
 		} else {
 			st.port.Enable()
 			st.ssd.Enable()
+			// only hard disks are throttled
+			if m.isImage() {
+				st.bandwidthGroup.Disable()
+			} else {
+				st.bandwidthGroup.Enable()
+			}
 			if m.isImage() {
 				st.isLive.Enable()
 			} else {
@@ -1054,103 +1133,64 @@ func (st *StorageContent) UpdateBySelect() {
 		return
 	}
 	st.apply.Enable()
+	st.preview.Enable()
 
 	// Chipset
-	index := 0
 	st.storageControllers = st.storageControllers[:0]
-	for {
-		str, ok := v.Properties[fmt.Sprintf("storagecontrollername%d", index)]
+	for _, item := range v.Config().Storage {
+		ctrl := new(StorageController)
+		ctrl.name = item.Name
+		ctrl.uuid = uuid.NewString()
+		chip, ok := st.chipsetMapStringToType[item.Type]
 		if ok {
-			ctrl := new(StorageController)
-			ctrl.name = str
-			ctrl.uuid = uuid.NewString()
-			str, ok := v.Properties[fmt.Sprintf("storagecontrollertype%d", index)]
+			ctrl.controllerType = chip
+			bus, ok := st.busMapcontrollerTypeToType[ctrl.controllerType]
 			if ok {
-				chip, ok := st.chipsetMapStringToType[str]
-				if ok {
-					ctrl.controllerType = chip
-					bus, ok := st.busMapcontrollerTypeToType[ctrl.controllerType]
-					if ok {
-						ctrl.busType = bus
-					}
-				}
-			}
-
-			str, ok = v.Properties[fmt.Sprintf("storagecontrollerbootable%d", index)]
-			if ok {
-				if str == "on" {
-					ctrl.bootable = true
-				}
+				ctrl.busType = bus
 			}
+		}
+		ctrl.bootable = item.Bootable
 
-			maxPorts := -1
-			str, ok = v.Properties[fmt.Sprintf("storagecontrollerportcount%d", index)]
-			if ok {
-				val, err := strconv.Atoi(str)
-				if err == nil {
-					maxPorts = val
-				}
-			}
-			maxDevice := -1
-			switch ctrl.busType {
-			case vm.StorageBus_ide:
-				maxDevice = 2
-			default:
-				maxDevice = 1
+		for _, attachment := range item.Attachments {
+			if attachment.IsEmpty() {
+				continue
 			}
-
-			for port := range maxPorts {
-				for device := range maxDevice {
-					str, ok := v.Properties[fmt.Sprintf("\"%s-%d-%d\"", ctrl.name, port, device)]
-					if ok && str != "none" && str != "emptydrive" {
-						medium := new(StorageMedium)
-						medium.device = device
-						medium.port = port
-						medium.file = str
-						str, ok := v.Properties[fmt.Sprintf("\"%s-ImageUUID-%d-%d\"", ctrl.name, port, device)]
-						if ok {
-							medium.uuid = str
-						}
-						str, ok = v.Properties[fmt.Sprintf("\"%s-nonrotational-%d-%d\"", ctrl.name, port, device)]
-						if ok {
-							if str == "on" {
-								medium.nonrotational = true
-							}
-						}
-						str, ok = v.Properties[fmt.Sprintf("\"%s-hot-pluggable-%d-%d\"", ctrl.name, port, device)]
-						if ok {
-							if str == "on" {
-								medium.hotpluggable = true
-							}
-						}
-						str, ok = v.Properties[fmt.Sprintf("\"%s-discard-%d-%d\"", ctrl.name, port, device)]
-						if ok {
-							if str == "on" {
-								medium.discard = true
-							}
-						}
-						str, ok = v.Properties[fmt.Sprintf("\"%s-tempeject-%d-%d\"", ctrl.name, port, device)]
-						if ok {
-							if str == "on" {
-								medium.isLive = true
-							}
-						}
-						ctrl.mediums = append(ctrl.mediums, medium)
-
-					}
-				}
+			// only IDE has a second device
+			if attachment.Device > 0 && ctrl.busType != vm.StorageBus_ide {
+				continue
 			}
-			st.storageControllers = append(st.storageControllers, ctrl)
-		} else {
-			break
+			ctrl.mediums = append(ctrl.mediums, &StorageMedium{
+				device:        attachment.Device,
+				port:          attachment.Port,
+				file:          attachment.Medium,
+				uuid:          attachment.ImageUUID,
+				nonrotational: attachment.NonRotational,
+				hotpluggable:  attachment.HotPluggable,
+				discard:       attachment.Discard,
+				isLive:        attachment.TempEject,
+			})
 		}
-
-		index += 1
+		st.storageControllers = append(st.storageControllers, ctrl)
 	}
 	st.saveOldStorageConfig()
 	st.tree.Refresh()
 	st.updateBySelect()
 	st.UpdateByStatus()
+
+	go func() {
+		list, err := v.GetAttachmentBandwidthGroups(&s.Client)
+		if err != nil {
+			return
+		}
+		fyne.Do(func() {
+			if _, active := getActiveServerAndVm(); active != v {
+				return
+			}
+			st.applyAttachmentBandwidthGroups(list)
+			st.tree.Refresh()
+			st.UpdateByStatus()
+		})
+	}()
 }
 
 // called from status updates
@@ -1177,6 +1217,7 @@ func (st *StorageContent) UpdateByStatus() {
 		st.port.Disable()
 		st.device.Disable()
 		st.ssd.Disable()
+		st.bandwidthGroup.Disable()
 
 	case vm.RunState_saved:
 		st.toolBarItemAddCtrl.Disable()
@@ -1186,6 +1227,7 @@ func (st *StorageContent) UpdateByStatus() {
 		st.port.Disable()
 		st.device.Disable()
 		st.ssd.Disable()
+		st.bandwidthGroup.Disable()
 
 	case vm.RunState_off, vm.RunState_aborted:
 		st.updateBySelect()
@@ -1197,11 +1239,14 @@ func (st *StorageContent) UpdateByStatus() {
 
 func (st *StorageContent) DisableAll() {
 	st.apply.Disable()
+	st.preview.Disable()
 
 	st.ssd.Disable()
 	st.isLive.Disable()
 	st.device.Disable()
 	st.port.Disable()
+	st.bandwidthGroup.Disable()
+	st.bandwidthManage.Disable()
 
 	st.name.Disable()
 	st.bootable.Disable()
@@ -1214,6 +1259,10 @@ func (st *StorageContent) DisableAll() {
 
 func (st *StorageContent) Apply() {
 	s, v := getActiveServerAndVm()
+	st.applyTo(s, v)
+}
+
+func (st *StorageContent) applyTo(s *vm.VmServer, v *vm.VMachine) {
 	if s == nil || v == nil {
 		return
 	}
@@ -1399,6 +1448,28 @@ func (st *StorageContent) Apply() {
 				}
 			}
 		}
+		// Bandwidth groups
+		for _, itemNew := range st.storageControllers {
+			for _, medium := range itemNew.mediums {
+				// a new or again attached medium has no group
+				old := ""
+				if itemNew.state != StorageControllerState_new && itemNew.oldItem != nil && medium.state == StorageMediumState_unchanged {
+					for _, mediumOld := range itemNew.oldItem.mediums {
+						if mediumOld.uuid == medium.uuid {
+							old = mediumOld.bandwidthGroup
+							break
+						}
+					}
+				}
+				if medium.bandwidthGroup == old {
+					continue
+				}
+				err := v.SetStorageBandwidthGroup(&s.Client, itemNew.name, medium.port, medium.device, medium.bandwidthGroup, VMStatusUpdateCallBack)
+				if err != nil {
+					SetStatusText(fmt.Sprintf(lang.X("details.vm_storage.bandwidthgroup.error", "Set bandwidth group for storage controller '%s' for VM '%s' failed with: %s"), itemNew.name, v.Name, err.Error()), MsgError)
+				}
+			}
+		}
 		st.saveOldStorageConfig()
 	}()
 }
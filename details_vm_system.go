@@ -74,6 +74,7 @@ type SystemTab struct {
 	clockInUtc      *widget.Check
 	firmware        *widget.Select
 	secureBoot      *widget.Check
+	secureBootHint  *CapabilityHint
 	biosTimeOffset  *widget.Entry
 	bootUp          *widget.Button
 	bootDown        *widget.Button
@@ -81,6 +82,7 @@ type SystemTab struct {
 	bootListOverlay *widget.Button
 
 	apply   *widget.Button
+	preview *widget.Button
 	tabItem *container.TabItem
 
 	bootEntries       []*bootEntry
@@ -113,6 +115,12 @@ func NewSystemTab() *SystemTab {
 		sysTab.Apply()
 	})
 	sysTab.apply.Importance = widget.HighImportance
+	sysTab.preview = newPreviewButton(&sysTab, func() func() {
+		old := sysTab.oldValues
+		return func() {
+			sysTab.oldValues = old
+		}
+	})
 
 	formWidth := util.GetFormWidth()
 	sysTab.chipset = widget.NewSelect([]string{
@@ -147,6 +155,7 @@ func NewSystemTab() *SystemTab {
 		sysTab.setEnableDisableBootOptions()
 	})
 	sysTab.secureBoot = widget.NewCheck(lang.X("details.vm_system.secureboot", "Secure Boot"), nil)
+	sysTab.secureBootHint = NewCapabilityHint()
 	sysTab.biosTimeOffset = widget.NewEntry()
 	sysTab.biosTimeOffset.SetPlaceHolder(lang.X("details.vm_system.biostiemoffset_placeholder", "> 0 guest VM time runs ahead"))
 	sysTab.biosTimeOffset.OnChanged = util.GetNumberFilterPlusMinus(sysTab.biosTimeOffset, nil)
@@ -158,7 +167,7 @@ func NewSystemTab() *SystemTab {
 		widget.NewLabel(lang.X("details.vm_system.firmware", "Firmware")), sysTab.firmware,
 		sysTab.acpi, sysTab.hpet,
 		sysTab.ioApic, sysTab.clockInUtc,
-		sysTab.secureBoot, util.NewFiller(0, 0),
+		container.NewHBox(sysTab.secureBoot, sysTab.secureBootHint), util.NewFiller(0, 0),
 	)
 
 	sysTab.bootUp = widget.NewButtonWithIcon("", theme.MoveUpIcon(), func() {
@@ -238,7 +247,7 @@ func NewSystemTab() *SystemTab {
 	gridWrap := container.NewVBox(util.NewVFiller(0.5), gridWrap1, gridWrap2)
 
 	c := container.NewVBox(container.NewHBox(gridWrap),
-		container.NewHBox(layout.NewSpacer(), sysTab.apply, util.NewFiller(32, 0)))
+		container.NewHBox(layout.NewSpacer(), sysTab.preview, sysTab.apply, util.NewFiller(32, 0)))
 
 	sysTab.tabItem = container.NewTabItem(lang.X("details.vm_info.tab.system", "System"), c)
 
@@ -258,6 +267,7 @@ func (sys *SystemTab) UpdateBySelect() {
 		return
 	}
 	sys.apply.Enable()
+	sys.preview.Enable()
 
 	// Chipset
 	util.SelectEntryFromProperty(sys.chipset, v, "chipset", sys.chipsetMapStringToIndex, &sys.oldValues.chipset)
@@ -287,18 +297,18 @@ func (sys *SystemTab) UpdateBySelect() {
 	util.CheckFromProperty(sys.secureBoot, v, "SecureBoot", "on", &sys.oldValues.secureboot)
 
 	// Time offset
-	str, ok := v.Properties["biossystemtimeoffset"]
-	if ok {
-		sys.biosTimeOffset.SetText(str)
-		sys.oldValues.biosTimeOffset, _ = strconv.Atoi(str)
-	}
+	config := v.Config()
+	sys.biosTimeOffset.SetText(strconv.Itoa(config.System.BiosTimeOffset))
+	sys.oldValues.biosTimeOffset = config.System.BiosTimeOffset
 
 	for _, item := range sys.bootEntries {
 		item.checked = false
 	}
 
-	for i := range len(sys.bootEntries) {
-		str, ok = v.Properties[fmt.Sprintf("boot%d", i+1)]
+	for i, str := range config.System.BootOrder {
+		if i >= len(sys.bootEntries) {
+			break
+		}
 		switch str {
 		case "dvd":
 			sys.setBootEntry(vm.Boot_dvd, i)
@@ -362,7 +372,7 @@ func (sys *SystemTab) setBootEntry(t vm.BootType, index int) {
 
 // called from status updates
 func (sys *SystemTab) UpdateByStatus() {
-	_, v := getActiveServerAndVm()
+	s, v := getActiveServerAndVm()
 	if v != nil {
 		state, err := v.GetState()
 		if err != nil {
@@ -398,6 +408,7 @@ func (sys *SystemTab) UpdateByStatus() {
 			sys.firmware.Enable()
 			if sys.firmware.SelectedIndex() > 0 {
 				sys.secureBoot.Enable()
+				enableByCapability(sys.secureBoot, sys.secureBootHint, s, vm.Capability_secureBoot)
 			} else {
 				sys.secureBoot.Disable()
 			}
@@ -426,6 +437,7 @@ func (sys *SystemTab) DisableAll() {
 	sys.bootListOverlay.Show()
 
 	sys.apply.Disable()
+	sys.preview.Disable()
 }
 
 func (sys *SystemTab) setEnableDisableBootOptions() {
@@ -448,6 +460,10 @@ func (sys *SystemTab) setEnableDisableBootOptions() {
 
 func (sys *SystemTab) Apply() {
 	s, v := getActiveServerAndVm()
+	sys.applyTo(s, v)
+}
+
+func (sys *SystemTab) applyTo(s *vm.VmServer, v *vm.VMachine) {
 	if v != nil {
 		ResetStatus()
 
@@ -575,7 +591,7 @@ func (sys *SystemTab) Apply() {
 					val, ok := sys.firmwareMapIndexToType[index]
 					if ok {
 						go func() {
-							if val != vm.Firmware_bios {
+							if val != vm.Firmware_bios && s.HasCapability(vm.Capability_tpm) {
 								err := v.SetTpm(&s.Client, vm.Tpm_20, VMStatusUpdateCallBack)
 								if err != nil {
 									SetStatusText(fmt.Sprintf(lang.X("details.vm_system.tpm.error", "Set TPM for VM '%s' failed with: %s"), v.Name, err.Error()), MsgError)
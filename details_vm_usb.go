@@ -68,6 +68,7 @@ type UsbTab struct {
 	down    *widget.Button
 
 	apply   *widget.Button
+	preview *widget.Button
 	tabItem *container.TabItem
 
 	toolNew    *widget.ToolbarAction
@@ -97,6 +98,12 @@ func NewUsbTab() *UsbTab {
 		usbTab.Apply()
 	})
 	usbTab.apply.Importance = widget.HighImportance
+	usbTab.preview = newPreviewButton(&usbTab, func() func() {
+		old := usbTab.oldValues
+		return func() {
+			usbTab.oldValues = old
+		}
+	})
 
 	formWidth := util.GetFormWidth()
 	usbTab.enabled = widget.NewCheck(lang.X("details.vm_usb.enabled", "Enable USB"), func(checked bool) {
@@ -131,7 +138,7 @@ func NewUsbTab() *UsbTab {
 	usbTab.label = colorlabel.NewColorLabel(lang.X("details.vm_usb.filters.title", "USB device filters"), theme.ColorNamePrimary, nil, 1.0)
 
 	gridWrap := container.NewBorder(container.NewVBox(gridWrap1, usbTab.label, usbTab.toolBar),
-		nil, nil, container.NewHBox(container.NewVBox(layout.NewSpacer(), usbTab.up, usbTab.down, layout.NewSpacer(), usbTab.apply, layout.NewSpacer(), layout.NewSpacer()), util.NewFiller(32, 0)), usbTab.list)
+		nil, nil, container.NewHBox(container.NewVBox(layout.NewSpacer(), usbTab.up, usbTab.down, layout.NewSpacer(), usbTab.preview, usbTab.apply, layout.NewSpacer(), layout.NewSpacer()), util.NewFiller(32, 0)), usbTab.list)
 
 	usbTab.tabItem = container.NewTabItem(lang.X("details.vm_info.tab.usb", "USB"), gridWrap)
 	usbTab.updateToolbarButtons()
@@ -368,38 +375,25 @@ func (usb *UsbTab) UpdateBySelect() {
 		return
 	}
 	usb.apply.Enable()
+	usb.preview.Enable()
 
 	err := v.UpdateStatusEx(&s.Client)
 	if err != nil {
 		return
 	}
 
-	var usb1 bool
-	var usb2 bool
-	var usb3 bool
 	var usbEnabled bool
 	var usbType int
 
-	str := v.Properties["usb1"]
-	if str == "on" {
-		usb1 = true
-	}
-	str = v.Properties["usb2"]
-	if str == "on" {
-		usb2 = true
-	}
-	str = v.Properties["usb3"]
-	if str == "on" {
-		usb3 = true
-	}
-
-	usbEnabled = usb1 || usb2 || usb3
+	config := v.Config()
+	usbEnabled = config.Usb.Type() != vm.Usb_none
 	usbType = -1
-	if usb3 {
+	switch config.Usb.Type() {
+	case vm.Usb_3:
 		usbType = 2
-	} else if usb2 {
+	case vm.Usb_2:
 		usbType = 1
-	} else if usb1 {
+	case vm.Usb_1:
 		usbType = 0
 	}
 
@@ -424,24 +418,17 @@ func (usb *UsbTab) UpdateBySelect() {
 	// Filters
 	usb.selectedItem = nil
 	usb.list.UnselectAll()
-	index := 1
 	usb.filters = usb.filters[:0]
-	for {
-		str, ok := v.Properties[fmt.Sprintf("USBFilterActive%d", index)]
-
-		if !ok {
-			break
-		}
-		filter := UsbFilter{}
-		filter.isChecked = (str == "on")
-		filter.name = v.Properties[fmt.Sprintf("USBFilterName%d", index)]
-		filter.productId = v.Properties[fmt.Sprintf("USBFilterProductId%d", index)]
-		filter.vendorId = v.Properties[fmt.Sprintf("USBFilterVendorId%d", index)]
-		filter.serialNumber = v.Properties[fmt.Sprintf("USBFilterSerialNumber%d", index)]
-		filter.product = v.Properties[fmt.Sprintf("USBFilterProduct%d", index)]
-		filter.manufacturer = v.Properties[fmt.Sprintf("USBFilterManufacturer%d", index)]
-		usb.filters = append(usb.filters, &filter)
-		index++
+	for _, item := range config.Usb.Filters {
+		usb.filters = append(usb.filters, &UsbFilter{
+			isChecked:    item.Active,
+			name:         item.Name,
+			productId:    item.ProductId,
+			vendorId:     item.VendorId,
+			serialNumber: item.SerialNumber,
+			product:      item.Product,
+			manufacturer: item.Manufacturer,
+		})
 	}
 	usb.saveOldFilterConfig()
 
@@ -492,6 +479,7 @@ func (usb *UsbTab) DisableAll() {
 	usb.usbType.Disable()
 
 	usb.apply.Disable()
+	usb.preview.Disable()
 }
 
 func (usb *UsbTab) saveOldFilterConfig() {
@@ -562,6 +550,10 @@ func (usb *UsbTab) updateToolbarButtons() {
 
 func (usb *UsbTab) Apply() {
 	s, v := getActiveServerAndVm()
+	usb.applyTo(s, v)
+}
+
+func (usb *UsbTab) applyTo(s *vm.VmServer, v *vm.VMachine) {
 	if s != nil && v != nil {
 		ResetStatus()
 		if !usb.enabled.Disabled() {
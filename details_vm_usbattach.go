@@ -142,17 +142,11 @@ func (usb *UsbAttachTab) updateDevices() int {
 		usb.devices = append(usb.devices, &usbItem)
 	}
 
-	index := 1
-	for {
-		str, ok := v.Properties[fmt.Sprintf("USBAttachActive%d", index)]
-		if !ok {
-			break
-		}
-		item := usb.getItem(str)
+	for _, uuid := range v.Config().Usb.Attached {
+		item := usb.getItem(uuid)
 		if item != nil {
 			item.isAttached = true
 		}
-		index++
 	}
 	return selectedIndex
 }
@@ -235,23 +229,7 @@ func (usb *UsbAttachTab) UpdateBySelect() {
 		return
 	}
 
-	var usb1 bool
-	var usb2 bool
-	var usb3 bool
-
-	str := v.Properties["usb1"]
-	if str == "on" {
-		usb1 = true
-	}
-	str = v.Properties["usb2"]
-	if str == "on" {
-		usb2 = true
-	}
-	str = v.Properties["usb3"]
-	if str == "on" {
-		usb3 = true
-	}
-	usb.usbEnabled = usb1 || usb2 || usb3
+	usb.usbEnabled = v.Config().Usb.Type() != vm.Usb_none
 	if !usb.usbEnabled {
 		usb.DisableAll()
 		return
@@ -25,11 +25,11 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"regexp"
-	"strings"
 
 	"bytemystery-com/vboxssh/filebrowser"
 	"bytemystery-com/vboxssh/util"
@@ -152,31 +152,31 @@ func (e *ExportHelper) buildVsys() []string {
 			vsys = append(vsys, fmt.Sprintf("--vsys=%d", index))
 			etab := e.tabs[index]
 			if etab.name.Text != "" {
-				vsys = append(vsys, fmt.Sprintf("--vmname='%s'", etab.name.Text))
+				vsys = append(vsys, "--vmname="+etab.name.Text)
 			}
 			if etab.product.Text != "" {
-				vsys = append(vsys, fmt.Sprintf("--product='%s'", etab.product.Text))
+				vsys = append(vsys, "--product="+etab.product.Text)
 			}
 			if etab.productURL.Text != "" {
-				vsys = append(vsys, fmt.Sprintf("--producturl='%s'", etab.productURL.Text))
+				vsys = append(vsys, "--producturl="+etab.productURL.Text)
 			}
 			if etab.productURL.Text != "" {
-				vsys = append(vsys, fmt.Sprintf("--producturl='%s'", etab.productURL.Text))
+				vsys = append(vsys, "--producturl="+etab.productURL.Text)
 			}
 			if etab.vendor.Text != "" {
-				vsys = append(vsys, fmt.Sprintf("--vendor='%s'", etab.vendor.Text))
+				vsys = append(vsys, "--vendor="+etab.vendor.Text)
 			}
 			if etab.vendorURL.Text != "" {
-				vsys = append(vsys, fmt.Sprintf("--vendorurl='%s'", etab.vendorURL.Text))
+				vsys = append(vsys, "--vendorurl="+etab.vendorURL.Text)
 			}
 			if etab.version.Text != "" {
-				vsys = append(vsys, fmt.Sprintf("--version='%s'", etab.version.Text))
+				vsys = append(vsys, "--version="+etab.version.Text)
 			}
 			if etab.description.Text != "" {
-				vsys = append(vsys, fmt.Sprintf("--description='%s'", etab.description.Text))
+				vsys = append(vsys, "--description="+etab.description.Text)
 			}
 			if etab.license.Text != "" {
-				vsys = append(vsys, fmt.Sprintf("--eula='%s'", etab.license.Text))
+				vsys = append(vsys, "--eula="+etab.license.Text)
 			}
 			index++
 		}
@@ -196,16 +196,15 @@ func (e *ExportHelper) doOvaExport() {
 		uuid := uuid.NewString()
 
 		name := fmt.Sprintf(lang.X("export.task.name", "Export OVA %s"), util.GetFilename(e.file.Text))
-		Gui.TasksInfos.AddTask(uuid, name, "")
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		Gui.TasksInfos.AddTask(uuid, name, "", cancel)
 		OpenTaskDetails()
 		ResetStatus()
 
-		err := e.vmServer.ExportOva(&e.vmServer.Client, machines,
+		err := e.vmServer.ExportOva(e.vmServer.Client.WithContext(ctx), machines,
 			e.formatMapIndexToType[e.format.SelectedIndex()], e.mnifest.Checked, e.iso.Checked,
-			e.macMapIndexToType[e.mac.SelectedIndex()], vsys, e.file.Text, util.WriterFunc(func(p []byte) (int, error) {
-				Gui.TasksInfos.UpdateTaskStatus(uuid, string(p), true)
-				return len(p), nil
-			}))
+			e.macMapIndexToType[e.mac.SelectedIndex()], vsys, e.file.Text, Gui.TasksInfos.NewProgressWriter(uuid))
 		if err != nil {
 			t := fmt.Sprintf(lang.X("export.done.error", "OVA export '%s' from server '%s' failed"), e.file.Text, e.vmServer.Name)
 			SetStatusText(t, MsgError)
@@ -307,7 +306,7 @@ func (e *ExportHelper) Export() {
 			dialog.ShowError(errors.New(lang.X("export.error.nofile", "No export file given")), Gui.MainWindow)
 		} else {
 			s := filebrowser.SftpHelper{}
-			s.DeleteFile(e.vmServer.Client.Client, e.file.Text)
+			s.DeleteFile(e.vmServer.SshClient(), e.file.Text)
 			e.doExport2()
 		}
 	})
@@ -330,7 +329,7 @@ func (e *ExportHelper) Export() {
 }
 
 func (e *ExportHelper) doBrowse() {
-	sftp := filebrowser.NewSftpBrowser(e.vmServer.Client.Client, e.vmServer.OvaPath, nil,
+	sftp := filebrowser.NewSftpBrowser(e.vmServer.SshClient(), e.vmServer.OvaPath, nil,
 		lang.X("export.browse.title", "Select file for export"), filebrowser.SftpFileBrowserMode_savefile)
 	sftp.Show(Gui.MainWindow, 0.75, func(file string, fi os.FileInfo, dir string) {
 		e.vmServer.OvaPath = dir
@@ -429,7 +428,7 @@ func NewImportHelper(s *vm.VmServer) *ImportHelper {
 
 func (i *ImportHelper) Import() {
 	r := regexp.MustCompile(`(?i)\.(ova|ovf)$`)
-	sftp := filebrowser.NewSftpBrowser(i.vmServer.Client.Client, i.vmServer.OvaPath, r,
+	sftp := filebrowser.NewSftpBrowser(i.vmServer.SshClient(), i.vmServer.OvaPath, r,
 		lang.X("import.browse.title", "Select file for import"), filebrowser.SftpFileBrowserMode_openfile)
 	sftp.Show(Gui.MainWindow, 0.75, func(file string, fi os.FileInfo, dir string) {
 		i.vmServer.OvaPath = dir
@@ -489,25 +488,14 @@ func (i *ImportHelper) doImportOva(file string) {
 		uuid := uuid.NewString()
 
 		name := fmt.Sprintf(lang.X("import.task.name", "Import OVA %s"), util.GetFilename(file))
-		Gui.TasksInfos.AddTask(uuid, name, "")
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		Gui.TasksInfos.AddTask(uuid, name, "", cancel)
 		OpenTaskDetails()
 		ResetStatus()
 
-		s := ""
-		err := i.vmServer.ImportOva(&i.vmServer.Client, i.macMapIndexToType[i.mac.SelectedIndex()], i.vdi.Checked,
-			file, vsys, util.WriterFunc(func(p []byte) (int, error) {
-				s += string(p)
-				i := strings.LastIndex(s, "\n")
-				strStatus := s
-				if i >= 0 {
-					strStatus = s[i+1:]
-					s = s[i+1:]
-				}
-				if strStatus != "" {
-					Gui.TasksInfos.UpdateTaskStatus(uuid, strStatus, false)
-				}
-				return len(p), nil
-			}))
+		err := i.vmServer.ImportOva(i.vmServer.Client.WithContext(ctx), i.macMapIndexToType[i.mac.SelectedIndex()], i.vdi.Checked,
+			file, vsys, Gui.TasksInfos.NewProgressWriter(uuid))
 		if err != nil {
 			t := fmt.Sprintf(lang.X("import.done.error", "Import of OVA '%s' in server '%s'failed"), file, i.vmServer.Name)
 			SetStatusText(t, MsgError)
@@ -0,0 +1,131 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"bytemystery-com/vboxssh/vm"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Label which copies its text to the clipboard when tapped
+type CopyLabel struct {
+	widget.Label
+}
+
+var _ fyne.Tappable = (*CopyLabel)(nil)
+var _ desktop.Cursorable = (*CopyLabel)(nil)
+
+func NewCopyLabel(text string) *CopyLabel {
+	l := &CopyLabel{}
+	l.Text = text
+	l.ExtendBaseWidget(l)
+	return l
+}
+
+func (l *CopyLabel) Tapped(*fyne.PointEvent) {
+	copyToClipboard(l.Text)
+}
+
+func (l *CopyLabel) Cursor() desktop.Cursor {
+	if l.Text == "" {
+		return desktop.DefaultCursor
+	}
+	return desktop.PointerCursor
+}
+
+func copyToClipboard(text string) {
+	if text == "" {
+		return
+	}
+	Gui.App.Clipboard().SetContent(text)
+	SetStatusText(fmt.Sprintf(lang.X("clipboard.copied", "'%s' copied to clipboard"), text), MsgInfo)
+}
+
+// small and dimmed for the tree
+func newTreeSecondaryLabel() *CopyLabel {
+	l := NewCopyLabel("")
+	l.Importance = widget.LowImportance
+	l.SizeName = theme.SizeNameCaptionText
+	return l
+}
+
+// text for the tree - empty if disabled or unknown
+func guestInfoTreeText(v *vm.VMachine) string {
+	if !Gui.Settings.TreeGuestInfo {
+		return ""
+	}
+	g := v.GuestInfo()
+	if g == nil || !v.GuestInfoAvailable() {
+		return ""
+	}
+	return g.PrimaryAddress()
+}
+
+// VMs whose guest infos failed at the last poll - reported once only
+var guestInfoFailed sync.Map
+
+// called by the tree polling for all running VMs of a server
+func updateGuestInfos(s *vm.VmServer) {
+	if !Gui.Settings.TreeGuestInfo {
+		return
+	}
+	for _, v := range Data.GetVms(s.UUID, true) {
+		_, err := v.UpdateGuestInfo(s, true)
+		if err == nil {
+			guestInfoFailed.Delete(v.UUID)
+			continue
+		}
+		if _, reported := guestInfoFailed.Swap(v.UUID, true); !reported {
+			SetStatusText(fmt.Sprintf(lang.X("guestinfo.update.error", "Reading the guest infos of VM '%s' failed with: %s - see the audit log"), v.Name, err.Error()), MsgWarning)
+		}
+	}
+}
+
+func toggleTreeGuestInfo(item *fyne.MenuItem) {
+	Gui.Settings.TreeGuestInfo = !Gui.Settings.TreeGuestInfo
+	Gui.Settings.Store()
+	item.Checked = Gui.Settings.TreeGuestInfo
+	Gui.MainMenu.Refresh()
+	if Gui.Settings.TreeGuestInfo {
+		go func() {
+			for _, s := range Data.GetServers(true) {
+				if s.IsConnected() {
+					updateGuestInfos(s)
+				}
+			}
+			treeRefresh()
+		}()
+	} else {
+		treeRefresh()
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"bytemystery-com/vboxssh/server"
+	"bytemystery-com/vboxssh/vm"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/widget"
+)
+
+func doImportSshConfig() {
+	file := server.SshConfigFile()
+	cfg, err := server.ParseSshConfig(file)
+	if err != nil {
+		dialog.ShowError(err, Gui.MainWindow)
+		return
+	}
+	aliases := cfg.Aliases()
+	if len(aliases) == 0 {
+		dialog.ShowInformation(lang.X("sshconfig.title", "Import from SSH config"),
+			fmt.Sprintf(lang.X("sshconfig.nohosts", "No hosts found in '%s'."), file), Gui.MainWindow)
+		return
+	}
+
+	// already imported servers will be re-synced
+	existing := make(map[string]*vm.VmServer)
+	for _, s := range Data.GetServers(true) {
+		if s.ConfigAlias != "" {
+			existing[s.ConfigAlias] = s
+		}
+	}
+
+	checks := make([]*widget.Check, len(aliases))
+	box := container.NewVBox()
+	for i, alias := range aliases {
+		h := cfg.Get(alias)
+		text := fmt.Sprintf("%s  (%s@%s:%d)", alias, h.User, h.HostName, h.Port)
+		if len(h.ProxyJump) > 0 {
+			text += fmt.Sprintf(lang.X("sshconfig.via", " via %s"), strings.Join(h.ProxyJump, ", "))
+		}
+		if _, ok := existing[alias]; ok {
+			text += " - " + lang.X("sshconfig.update", "already imported, update")
+		}
+		checks[i] = widget.NewCheck(text, nil)
+		_, ok := existing[alias]
+		checks[i].SetChecked(!ok)
+		box.Add(checks[i])
+	}
+
+	all := widget.NewButton(lang.X("sshconfig.all", "All"), func() {
+		for _, item := range checks {
+			item.SetChecked(true)
+		}
+	})
+	none := widget.NewButton(lang.X("sshconfig.none", "None"), func() {
+		for _, item := range checks {
+			item.SetChecked(false)
+		}
+	})
+
+	c := container.NewBorder(container.NewHBox(widget.NewLabel(file), all, none), nil, nil, nil, container.NewVScroll(box))
+
+	dia := dialog.NewCustomConfirm(lang.X("sshconfig.title", "Import from SSH config"),
+		lang.X("sshconfig.import", "Import"), lang.X("cancel", "Cancel"), c, func(ok bool) {
+			if !ok {
+				return
+			}
+			added := 0
+			updated := 0
+			for i, alias := range aliases {
+				if !checks[i].Checked {
+					continue
+				}
+				srv := cfg.ToServer(alias)
+				if s, ok := existing[alias]; ok {
+					syncServerFromConfig(s, srv)
+					updated++
+				} else {
+					importServer(srv)
+					added++
+				}
+			}
+			SaveServers()
+			Gui.Tree.Refresh()
+			SetStatusText(fmt.Sprintf(lang.X("sshconfig.done", "%d server(s) imported, %d server(s) updated from SSH config."), added, updated), MsgInfo)
+		}, Gui.MainWindow)
+	ms := Gui.MainWindow.Canvas().Size()
+	dia.Resize(fyne.NewSize(ms.Width*.7, ms.Height*.7))
+	dia.Show()
+}
+
+func importServer(srv server.Server) {
+	srv.KeyFileReader = readKeyFile
+	srv.HostFileReader = readKeyFile
+	srv.HostKeyConfirm = confirmHostKey
+	srv.HostKeyUpdated = SaveServers
+	var vms *vm.VmServer
+	vms = Data.AddData(srv, func() {
+		setAfterConnectStatus(vms, nil)
+	}, func(err error) {
+		setAfterConnectStatus(vms, err)
+	})
+}
+
+// Password and the own settings of the server are kept
+func syncServerFromConfig(s *vm.VmServer, srv server.Server) {
+	if s.Host != srv.Host || s.Port != srv.Port {
+		s.HostKey = ""
+	}
+	s.Host = srv.Host
+	s.Port = srv.Port
+	s.User = srv.User
+	if srv.KeyFile != "" {
+		s.KeyFile = srv.KeyFile
+		s.KeyFileContent = nil
+	}
+	if srv.CertFile != "" {
+		s.CertFile = srv.CertFile
+		s.CertFileContent = nil
+	}
+	s.UseAgent = srv.UseAgent || s.UseAgent
+	// keep the known host keys and passwords of unchanged jump hosts
+	for i := range srv.JumpHosts {
+		for _, old := range s.JumpHosts {
+			if old.Host == srv.JumpHosts[i].Host && old.Port == srv.JumpHosts[i].Port {
+				srv.JumpHosts[i].HostKey = old.HostKey
+				srv.JumpHosts[i].Password = old.Password
+				break
+			}
+		}
+	}
+	s.JumpHosts = srv.JumpHosts
+	if s.IsConnected() {
+		s.Disonnect(&s.Client.Client)
+	}
+	go s.Connect(func() {
+		setAfterConnectStatus(s, nil)
+	}, func(err error) {
+		setAfterConnectStatus(s, err)
+	})
+}
@@ -87,6 +87,7 @@ type GUI struct {
 	IconEmpty         *fyne.StaticResource
 	IconOk            *fyne.StaticResource
 	IconError         *fyne.StaticResource
+	IconDegraded      fyne.Resource
 	PicStartU         *fyne.StaticResource
 	PicStartD         *fyne.StaticResource
 	PicPauseU         *fyne.StaticResource
@@ -162,6 +163,7 @@ type GUI struct {
 
 	ServerSshTab      *ServerSshInfos
 	ServerStatTab     *ServerStatInfos
+	ServerCmdTab      *ServerCmdInfos
 	ServerVmTab       *VmServerInfos
 	VmInfoTab         *InfoTab
 	VmCpuRamTab       *CpuRamTab
@@ -175,6 +177,8 @@ type GUI struct {
 	VmUsbAttachTab    *UsbAttachTab
 	VmSnapshotTab     *SnapshotTab
 	VmSharedFolderTab *SharedFolderTab
+	VmGuestPropTab    *GuestPropertyTab
+	VmGuestConsoleTab *GuestConsoleTab
 	TasksInfos        *TasksInfos
 	DetailObjs        []DetailsInterface
 }
@@ -244,16 +248,26 @@ func main() {
 	Gui.MenuItems["menu.server.disconnect"] = m
 	m.Shortcut = &desktop.CustomShortcut{KeyName: fyne.KeyN, Modifier: fyne.KeyModifierControl}
 
+	m = fyne.NewMenuItem(lang.X("menu.server.import_sshconfig", "Import from SSH config"), doImportSshConfig)
+	Gui.MenuItems["menu.server.import_sshconfig"] = m
+
 	Gui.MenuServer = fyne.NewMenu(lang.X("menu.server", "Server"),
 		Gui.MenuItems["menu.server.add"],
 		Gui.MenuItems["menu.server.remove"],
+		Gui.MenuItems["menu.server.import_sshconfig"],
 		fyne.NewMenuItemSeparator(),
 		Gui.MenuItems["menu.server.connect"],
 		Gui.MenuItems["menu.server.reconnect"],
 		Gui.MenuItems["menu.server.disconnect"],
 	)
+	guestInfoItem := fyne.NewMenuItem(lang.X("menu.edit.treeguestinfo", "Show guest addresses in tree"), nil)
+	guestInfoItem.Checked = Gui.Settings.TreeGuestInfo
+	guestInfoItem.Action = func() {
+		toggleTreeGuestInfo(guestInfoItem)
+	}
 	eMenu := fyne.NewMenu(lang.X("menu.edit", "Edit"),
-		fyne.NewMenuItem(lang.X("menu.edit.appearance", "Appearance"), showAppearanceDialog))
+		fyne.NewMenuItem(lang.X("menu.edit.appearance", "Appearance"), showAppearanceDialog),
+		guestInfoItem)
 
 	Gui.MenuItems["menu.machine.import"] = fyne.NewMenuItem(lang.X("menu.machine.import", "Import"), doImport)
 	Gui.MenuItems["menu.machine.export"] = fyne.NewMenuItem(lang.X("menu.machine.export", "Export"), doExport)
@@ -355,6 +369,9 @@ func main() {
 	Gui.ServerStatTab = NewServerStatTab()
 	Gui.DetailObjs = append(Gui.DetailObjs, Gui.ServerStatTab)
 
+	Gui.ServerCmdTab = NewServerCmdTab()
+	Gui.DetailObjs = append(Gui.DetailObjs, Gui.ServerCmdTab)
+
 	Gui.ServerVmTab = NewVmServerTab()
 	Gui.DetailObjs = append(Gui.DetailObjs, Gui.ServerVmTab)
 
@@ -391,14 +408,21 @@ func main() {
 	Gui.VmSharedFolderTab = NewSharedFolderTab()
 	Gui.DetailObjs = append(Gui.DetailObjs, Gui.VmSharedFolderTab)
 
-	Gui.VmServerTabs = container.NewAppTabs(Gui.ServerSshTab.tabItem, Gui.ServerStatTab.tabItem, Gui.ServerVmTab.tabItem)
+	Gui.VmGuestPropTab = NewGuestPropertyTab()
+	Gui.DetailObjs = append(Gui.DetailObjs, Gui.VmGuestPropTab)
+
+	Gui.VmGuestConsoleTab = NewGuestConsoleTab()
+	Gui.DetailObjs = append(Gui.DetailObjs, Gui.VmGuestConsoleTab)
+
+	Gui.VmServerTabs = container.NewAppTabs(Gui.ServerSshTab.tabItem, Gui.ServerStatTab.tabItem, Gui.ServerCmdTab.tabItem, Gui.ServerVmTab.tabItem)
 
 	Gui.SShServerDetails = widget.NewAccordionItem(lang.X("details.server", "Server"), Gui.VmServerTabs)
 
 	Gui.VmInfoTabs = container.NewAppTabs(
 		Gui.VmInfoTab.tabItem, Gui.VmSystemTab.tabItem, Gui.VmCpuRamTab.tabItem,
 		Gui.VmDisplayTab.tabItem, Gui.VmRdpTab.tabItem, Gui.VmAudioTab.tabItem, Gui.VmStorageContent.tabItem,
-		Gui.VmUsbTab.tabItem, Gui.VmUsbAttachTab.tabItem, Gui.VmSnapshotTab.tabItem, Gui.VmSharedFolderTab.tabItem)
+		Gui.VmUsbTab.tabItem, Gui.VmUsbAttachTab.tabItem, Gui.VmSnapshotTab.tabItem, Gui.VmSharedFolderTab.tabItem,
+		Gui.VmGuestPropTab.tabItem, Gui.VmGuestConsoleTab.tabItem)
 	Gui.VmInfoDetails = widget.NewAccordionItem(lang.X("details.vm_info", "VM - General"), Gui.VmInfoTabs)
 
 	for i := 0; i < NUMBER_OF_NICS; i++ {
@@ -466,6 +490,7 @@ func main() {
 		} else {
 			LoadData()
 			go treeUpdateTimerProc()
+			go certExpiryTimerProc()
 			UpdateButtons()
 			if Gui.Settings.FirstStart {
 				Gui.Settings.FirstStart = false
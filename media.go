@@ -25,6 +25,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"image/color"
 	"os"
@@ -153,7 +154,7 @@ func (m *MediaHelper) selectImage(fOk func(vm.MediaInfo), fAddNewMedia func(), f
 
 func (m *MediaHelper) addNewFddMedia() {
 	r := regexp.MustCompile(`(?i)\.(img)$`)
-	sftp := filebrowser.NewSftpBrowser(m.vmServer.Client.Client, m.vmServer.FloppyImagesPath, r,
+	sftp := filebrowser.NewSftpBrowser(m.vmServer.SshClient(), m.vmServer.FloppyImagesPath, r,
 		lang.X("details.vm_storage.addmedia.floppy.title", "Select floppy image file"), filebrowser.SftpFileBrowserMode_openfile)
 	sftp.Show(m.mainWindow, m.windowScaleNew, func(file string, fi os.FileInfo, dir string) {
 		me := vm.MediaInfo{
@@ -167,7 +168,7 @@ func (m *MediaHelper) addNewFddMedia() {
 
 func (m *MediaHelper) addNewDvdMedia() {
 	r := regexp.MustCompile(`(?i)\.(iso)$`)
-	sftp := filebrowser.NewSftpBrowser(m.vmServer.Client.Client, m.vmServer.DvdImagesPath, r,
+	sftp := filebrowser.NewSftpBrowser(m.vmServer.SshClient(), m.vmServer.DvdImagesPath, r,
 		lang.X("details.vm_storage.addmedia.dvd.title", "Select CD/DVD image file"), filebrowser.SftpFileBrowserMode_openfile)
 	sftp.Show(m.mainWindow, m.windowScaleNew, func(file string, fi os.FileInfo, dir string) {
 		me := vm.MediaInfo{
@@ -181,7 +182,7 @@ func (m *MediaHelper) addNewDvdMedia() {
 
 func (m *MediaHelper) addNewHddMedia() {
 	r := regexp.MustCompile(`(?i)\.(vdi|vmdk|hdd|vhd)$`)
-	sftp := filebrowser.NewSftpBrowser(m.vmServer.Client.Client, m.vmServer.HddImagesPath, r,
+	sftp := filebrowser.NewSftpBrowser(m.vmServer.SshClient(), m.vmServer.HddImagesPath, r,
 		lang.X("details.vm_storage.addmedia.hdd.title", "Select HDD image file"), filebrowser.SftpFileBrowserMode_openfile)
 	sftp.Show(m.mainWindow, m.windowScaleNew, func(file string, fi os.FileInfo, dir string) {
 		m.vmServer.HddImagesPath = dir
@@ -312,14 +313,14 @@ func (m *MediaHelper) createNewHddMedia() {
 		}
 	}
 
-	sftp := filebrowser.NewSftpBrowser(m.vmServer.Client.Client, startFolder, r,
+	sftp := filebrowser.NewSftpBrowser(m.vmServer.SshClient(), startFolder, r,
 		lang.X("details.vm_storage.addmedia.hdd.create.title", "Create new HDD image file"), filebrowser.SftpFileBrowserMode_savefile)
 	sftp.Show(m.mainWindow, m.windowScaleNew, func(file string, fi os.FileInfo, dir string) {
 		if fi != nil {
 			err := s.DeleteMedia(&m.vmServer.Client, vm.Media_disk, file)
 			if err != nil {
 				s := filebrowser.SftpHelper{}
-				s.DeleteFile(m.vmServer.Client.Client, file)
+				s.DeleteFile(m.vmServer.SshClient(), file)
 			}
 		}
 		m.ShowNewHddPropertyDialog(lang.X("details.vm_storage.addmedia.create.title", "New HDD properties"), file,
@@ -327,15 +328,14 @@ func (m *MediaHelper) createNewHddMedia() {
 				uuid := uuid.NewString()
 
 				name := lang.X("details.vm_storge.createmedium.task.name", "Create media")
-				Gui.TasksInfos.AddTask(uuid, name, "")
+				ctx, cancel := context.WithCancel(context.Background())
+				Gui.TasksInfos.AddTask(uuid, name, "", cancel)
 				OpenTaskDetails()
 				ResetStatus()
 
 				go func() {
-					err := s.CreateMedia(&s.Client, vm.Media_disk, size, &format, &fixedSize, file, util.WriterFunc(func(p []byte) (int, error) {
-						Gui.TasksInfos.UpdateTaskStatus(uuid, string(p), true)
-						return len(p), nil
-					}))
+					defer cancel()
+					err := s.CreateMedia(s.Client.WithContext(ctx), vm.Media_disk, size, &format, &fixedSize, file, Gui.TasksInfos.NewProgressWriter(uuid))
 					if err != nil {
 						t := fmt.Sprintf(lang.X("details.vm_storge.createmedium.done.error", "Create medium on server '%s' failed"), s.Name)
 						SetStatusText(t, MsgError)
@@ -41,6 +41,9 @@ const (
 	PREF_UPDATE_CHECK_INTERVAL_VALUE = 48
 	PREF_UPDATE_CHECK_AUTO_KEY       = "autoupdatecheck"
 	PREF_UPDATE_CHECK_AUTO_VALUE     = true
+	PREF_TREE_GUEST_INFO_KEY         = "tree.guest_info"
+	PREF_TREE_GUEST_INFO_VALUE       = false
+	PREF_GUEST_CONSOLE_KEY           = "guestconsole"
 )
 
 type Preferences struct {
@@ -53,6 +56,8 @@ type Preferences struct {
 	LastUpdatecheck     int64
 	UpdateCheckInterval int
 	AutoUpdateCheck     bool
+	TreeGuestInfo       bool
+	GuestConsole        string // json String
 }
 
 func NewPreferences() *Preferences {
@@ -66,6 +71,8 @@ func NewPreferences() *Preferences {
 		LastUpdatecheck:     100 * int64(Gui.App.Preferences().IntWithFallback(PREF_UPDATE_LAST_CHECK_KEY, PREF_UPDATE_LAST_CHECK_VALUE)),
 		UpdateCheckInterval: Gui.App.Preferences().IntWithFallback(PREF_UPDATE_CHECK_INTERVAL_KEY, PREF_UPDATE_CHECK_INTERVAL_VALUE),
 		AutoUpdateCheck:     Gui.App.Preferences().BoolWithFallback(PREF_UPDATE_CHECK_AUTO_KEY, PREF_UPDATE_CHECK_AUTO_VALUE),
+		TreeGuestInfo:       Gui.App.Preferences().BoolWithFallback(PREF_TREE_GUEST_INFO_KEY, PREF_TREE_GUEST_INFO_VALUE),
+		GuestConsole:        Gui.App.Preferences().StringWithFallback(PREF_GUEST_CONSOLE_KEY, ""),
 	}
 	return p
 }
@@ -81,4 +88,6 @@ func (p *Preferences) Store() {
 	pref.SetInt(PREF_UPDATE_LAST_CHECK_KEY, int(p.LastUpdatecheck/100))
 	pref.SetInt(PREF_UPDATE_CHECK_INTERVAL_KEY, p.UpdateCheckInterval)
 	pref.SetBool(PREF_UPDATE_CHECK_AUTO_KEY, p.AutoUpdateCheck)
+	pref.SetBool(PREF_TREE_GUEST_INFO_KEY, p.TreeGuestInfo)
+	pref.SetString(PREF_GUEST_CONSOLE_KEY, p.GuestConsole)
 }
@@ -0,0 +1,170 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"bytemystery-com/vboxssh/util"
+	"bytemystery-com/vboxssh/vm"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	PREVIEW_QUIET_TIME = 300 * time.Millisecond
+	PREVIEW_MAX_TIME   = 5 * time.Second
+)
+
+// A tab with a preview - applyTo does the work of Apply for the given server
+// and VM. The preview passes a copy of the server with an own executor, so
+// commands of other tabs, VMs and tasks still run live in the meantime.
+type previewTab interface {
+	DetailsInterface
+	applyTo(s *vm.VmServer, v *vm.VMachine)
+}
+
+// The preview button next to the apply button of a tab
+func newPreviewButton(tab previewTab, save func() func()) *widget.Button {
+	return widget.NewButtonWithIcon(lang.X("preview.button", "Preview"), theme.VisibilityIcon(), func() {
+		previewApply(tab, save)
+	})
+}
+
+// Runs the apply of tab with a preview executor and shows the collected
+// commands. The apply functions store the new values as old ones when a
+// command succeeds - save returns a function which restores them.
+func previewApply(tab previewTab, save func() func()) {
+	s, v := getActiveServerAndVm()
+	if s == nil || v == nil {
+		return
+	}
+	restore := save()
+	p := vm.NewPreviewExecutor(s.GetExecutor())
+	tab.applyTo(s.WithExecutor(p), v)
+	go func() {
+		cmds := p.Wait(PREVIEW_QUIET_TIME, PREVIEW_MAX_TIME)
+		fyne.Do(func() {
+			restore()
+			if len(cmds) == 0 {
+				SetStatusText(fmt.Sprintf(lang.X("preview.nochanges", "No changes to apply for VM '%s'"), v.Name), MsgInfo)
+				return
+			}
+			showPreview(s, v, tab, cmds)
+		})
+	}()
+}
+
+func showPreview(s *vm.VmServer, v *vm.VMachine, tab DetailsInterface, cmds []vm.PreviewCmd) {
+	var dia *dialog.CustomDialog
+
+	items := container.NewVBox()
+	for index, cmd := range cmds {
+		line := widget.NewLabel(fmt.Sprintf("%d: %s", index+1, s.CommandLine(cmd.Cmd, cmd.Args)))
+		line.Wrapping = fyne.TextWrapWord
+		line.TextStyle = fyne.TextStyle{Monospace: true}
+		items.Add(line)
+		for _, change := range cmd.Changes {
+			before, ok := v.PropertyForOption(change.Option)
+			if !ok {
+				before = "-"
+			}
+			diff := widget.NewLabel(fmt.Sprintf("    %s:  %s  →  %s", change.Option, before, change.After))
+			if before == change.After {
+				diff.Importance = widget.LowImportance
+			} else {
+				diff.Importance = widget.WarningImportance
+			}
+			items.Add(diff)
+		}
+	}
+
+	execute := widget.NewButtonWithIcon(lang.X("preview.execute", "Execute"), theme.ConfirmIcon(), func() {
+		dia.Hide()
+		executePreview(s, v, tab, cmds)
+	})
+	execute.Importance = widget.HighImportance
+	copyScript := widget.NewButtonWithIcon(lang.X("preview.copy", "Copy as script"), theme.ContentCopyIcon(), func() {
+		Gui.App.Clipboard().SetContent(previewScript(s, v, cmds))
+		SetStatusText(lang.X("preview.copied", "Commands copied to clipboard"), MsgInfo)
+	})
+	cancel := widget.NewButtonWithIcon(lang.X("preview.cancel", "Cancel"), theme.CancelIcon(), func() {
+		dia.Hide()
+	})
+	buttons := container.New(layout.NewGridLayout(5), layout.NewSpacer(), cancel, copyScript, execute, layout.NewSpacer())
+
+	c := container.NewBorder(nil, container.NewVBox(util.NewVFiller(0.5), buttons), nil, nil, container.NewVScroll(items))
+	dia = dialog.NewCustomWithoutButtons(fmt.Sprintf(lang.X("preview.title", "Preview - %s"), v.Name), c, Gui.MainWindow)
+	si := Gui.MainWindow.Canvas().Size()
+	dia.Resize(fyne.NewSize(si.Width*0.8, si.Height*0.8))
+	dia.Show()
+}
+
+// Runs the collected commands one after the other and stops at the first
+// error - like the script with set -e
+func executePreview(s *vm.VmServer, v *vm.VMachine, tab DetailsInterface, cmds []vm.PreviewCmd) {
+	ResetStatus()
+	go func() {
+		for _, cmd := range cmds {
+			lines, err := vm.RunCmd(&s.Client, cmd.Cmd, cmd.Args, nil, nil)
+			if err != nil {
+				if len(lines) > 0 {
+					err = errors.Join(err, errors.New(strings.TrimSpace(strings.Join(lines, "\n"))))
+				}
+				SetStatusText(fmt.Sprintf(lang.X("preview.execute.error", "Command '%s' for VM '%s' failed with: %s"),
+					s.CommandLine(cmd.Cmd, cmd.Args), v.Name, err.Error()), MsgError)
+				break
+			}
+		}
+		v.UpdateStatus(&s.Client, nil)
+		fyne.Do(func() {
+			Gui.Tree.Refresh()
+			// reloads the old values of the tab too
+			tab.UpdateBySelect()
+			UpdateButtons()
+		})
+	}()
+}
+
+func previewScript(s *vm.VmServer, v *vm.VMachine, cmds []vm.PreviewCmd) string {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	b.WriteString("# " + v.Name + " (" + v.UUID + ")\n")
+	b.WriteString("set -e\n")
+	for _, cmd := range cmds {
+		b.WriteString(s.CommandLine(cmd.Cmd, cmd.Args))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
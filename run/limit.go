@@ -0,0 +1,146 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package run
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+const (
+	// sshd allows 10 sessions per connection by default
+	DEFAULT_MAX_SESSIONS = 8
+)
+
+type Priority int
+
+const (
+	Priority_user Priority = iota
+	Priority_background
+)
+
+// Limits the concurrent sessions of one connection. Waiting calls are
+// served first in first out, user actions before background polling.
+type SessionLimiter struct {
+	lock    sync.Mutex
+	max     int
+	active  int
+	waiting [2][]chan struct{}
+}
+
+func NewSessionLimiter(max int) *SessionLimiter {
+	l := SessionLimiter{}
+	l.setMax(max)
+	return &l
+}
+
+func (l *SessionLimiter) setMax(max int) {
+	if max <= 0 {
+		max = DEFAULT_MAX_SESSIONS
+	}
+	l.max = max
+}
+
+func (l *SessionLimiter) SetMax(max int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.setMax(max)
+	l.dispatch()
+}
+
+func (l *SessionLimiter) Acquire(prio Priority) {
+	l.AcquireContext(context.Background(), prio)
+}
+
+// Returns the error of the context if it is done before a session is free
+func (l *SessionLimiter) AcquireContext(ctx context.Context, prio Priority) error {
+	l.lock.Lock()
+	if l.active < l.max && l.queued(prio) == 0 {
+		l.active++
+		l.lock.Unlock()
+		return nil
+	}
+	ch := make(chan struct{})
+	l.waiting[prio] = append(l.waiting[prio], ch)
+	l.lock.Unlock()
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		l.lock.Lock()
+		defer l.lock.Unlock()
+		index := slices.Index(l.waiting[prio], ch)
+		if index >= 0 {
+			l.waiting[prio] = slices.Delete(l.waiting[prio], index, index+1)
+		} else {
+			// was dispatched in the meantime
+			l.active--
+			l.dispatch()
+		}
+		return ctx.Err()
+	}
+}
+
+func (l *SessionLimiter) Release() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.active--
+	l.dispatch()
+}
+
+// waiting calls with the same or a higher priority
+func (l *SessionLimiter) queued(prio Priority) int {
+	n := 0
+	for p := Priority_user; p <= prio; p++ {
+		n += len(l.waiting[p])
+	}
+	return n
+}
+
+func (l *SessionLimiter) dispatch() {
+	for l.active < l.max {
+		var ch chan struct{}
+		for p := range l.waiting {
+			if len(l.waiting[p]) > 0 {
+				ch = l.waiting[p][0]
+				l.waiting[p] = l.waiting[p][1:]
+				break
+			}
+		}
+		if ch == nil {
+			return
+		}
+		l.active++
+		close(ch)
+	}
+}
+
+// active sessions, waiting calls and the maximum
+func (l *SessionLimiter) Stats() (int, int, int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.active, len(l.waiting[Priority_user]) + len(l.waiting[Priority_background]), l.max
+}
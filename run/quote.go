@@ -0,0 +1,57 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package run
+
+import (
+	"strings"
+)
+
+// characters which need no quoting in a POSIX shell word
+const shellSafeChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:,+@%"
+
+// Quotes s as one word for a POSIX shell (sh, bash, dash, zsh). Inside single
+// quotes nothing is special - a single quote ends the quoting, is escaped
+// with a backslash and the quoting starts again
+func QuoteArg(s string) string {
+	if s == "" {
+		return "''"
+	}
+	// a leading = is the =cmd expansion of zsh
+	if strings.Trim(s, shellSafeChars) == "" && s[0] != '=' {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Builds the command line for the remote shell - every word is quoted
+func QuoteCmd(cmd string, args []string) string {
+	var b strings.Builder
+	b.WriteString(QuoteArg(cmd))
+	for _, arg := range args {
+		b.WriteByte(' ')
+		b.WriteString(QuoteArg(arg))
+	}
+	return b.String()
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package run
+
+import (
+	"os/exec"
+	"testing"
+)
+
+var quoteTests = []struct {
+	arg    string
+	quoted string
+}{
+	{"", "''"},
+	{"list", "list"},
+	{"--nic1=nat", "--nic1=nat"},
+	{"=ls", "'=ls'"},
+	{"a=b", "a=b"},
+	{"-rf", "-rf"},
+	{"/tmp/a.vdi", "/tmp/a.vdi"},
+	{"my vm", "'my vm'"},
+	{"it's", `'it'\''s'`},
+	{"'", `''\'''`},
+	{"$HOME", "'$HOME'"},
+	{"a$(id)b", "'a$(id)b'"},
+	{"`id`", "'`id`'"},
+	{"a\nb", "'a\nb'"},
+	{"~", "'~'"},
+	{"~/x", "'~/x'"},
+	{"a;b", "'a;b'"},
+	{"a*b", "'a*b'"},
+	{`back\slash`, `'back\slash'`},
+	{"tab\there", "'tab\there'"},
+}
+
+func TestQuoteArg(t *testing.T) {
+	for _, test := range quoteTests {
+		if q := QuoteArg(test.arg); q != test.quoted {
+			t.Errorf("QuoteArg(%q) = %q, want %q", test.arg, q, test.quoted)
+		}
+	}
+}
+
+func TestQuoteCmd(t *testing.T) {
+	q := QuoteCmd("VBoxManage", []string{"modifyvm", "my vm", "--description", "it's $5"})
+	want := `VBoxManage modifyvm 'my vm' --description 'it'\''s $5'`
+	if q != want {
+		t.Errorf("QuoteCmd = %q, want %q", q, want)
+	}
+}
+
+// the shells must see exactly the original value
+func TestQuoteArgShell(t *testing.T) {
+	for _, name := range []string{"sh", "bash", "zsh"} {
+		shell, err := exec.LookPath(name)
+		if err != nil {
+			t.Logf("no %s", name)
+			continue
+		}
+		for _, test := range quoteTests {
+			out, err := exec.Command(shell, "-c", QuoteCmd("printf", []string{"%s", test.arg})).Output()
+			if err != nil {
+				t.Fatalf("%s for %q: %v", name, test.arg, err)
+			}
+			if string(out) != test.arg {
+				t.Errorf("%s gave %q for %q", name, out, test.arg)
+			}
+		}
+	}
+}
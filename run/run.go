@@ -27,14 +27,53 @@ package run
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"io"
+	"os"
 	"os/exec"
 	"strings"
 	"sync"
+	"time"
 
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
 )
 
+const (
+	// time to wait for the output after a local process was killed
+	KILL_WAIT_DELAY = 2 * time.Second
+)
+
+// clients whose sessions request agent forwarding
+var agentForwarding sync.Map
+
+func SetAgentForwarding(client *ssh.Client, enable bool) {
+	if client == nil {
+		return
+	}
+	if enable {
+		agentForwarding.Store(client, true)
+	} else {
+		agentForwarding.Delete(client)
+	}
+}
+
+func newSession(client *ssh.Client) (*ssh.Session, error) {
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := agentForwarding.Load(client); ok {
+		err = agent.RequestAgentForwarding(session)
+		if err != nil {
+			session.Close()
+			return nil, err
+		}
+	}
+	return session, nil
+}
+
 func mergeOutAndErr(bOut bytes.Buffer, bErr bytes.Buffer) []string {
 	str := bOut.String()
 	if bErr.Len() > 0 {
@@ -47,16 +86,70 @@ func mergeOutAndErr(bOut bytes.Buffer, bErr bytes.Buffer) []string {
 	return lines
 }
 
+// Returns a context with the timeout - no timeout if timeout <= 0
+func WithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// the error of the context wins - the exit status of a killed process says nothing
+func contextError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		if err == nil {
+			return ctxErr
+		}
+		return errors.Join(ctxErr, err)
+	}
+	return err
+}
+
 // runs a command local
 func RunLocalCmd(cmd string, args []string, userWriterOut, userWriterErr io.Writer) ([]string, error) {
+	return RunLocalCmdEnv(cmd, args, nil, userWriterOut, userWriterErr)
+}
+
+// env (KEY=value) is added to the environment of the process
+func RunLocalCmdEnv(cmd string, args []string, env []string, userWriterOut, userWriterErr io.Writer) ([]string, error) {
+	return RunLocalCmdContext(context.Background(), cmd, args, env, userWriterOut, userWriterErr)
+}
+
+// The process is killed when the context is done
+func RunLocalCmdContext(ctx context.Context, cmd string, args []string, env []string, userWriterOut, userWriterErr io.Writer) ([]string, error) {
+	return RunLocalCmdStdin(ctx, cmd, args, env, nil, userWriterOut, userWriterErr)
+}
+
+// stdin is read by the process - e.g. a password which must not be an argument
+func RunLocalCmdStdin(ctx context.Context, cmd string, args []string, env []string, stdin io.Reader, userWriterOut, userWriterErr io.Writer) ([]string, error) {
+	var lines []string
+	var err error
 	if userWriterOut == nil && userWriterErr == nil {
-		return RunLocalCmdSimple(cmd, args)
+		lines, err = runLocalCmdSimple(newLocalCmd(ctx, cmd, args, env, stdin))
+	} else {
+		lines, err = runLocalCmdWithProgess(newLocalCmd(ctx, cmd, args, env, stdin), userWriterOut, userWriterErr)
 	}
-	return RunLocalCmdWithProgess(cmd, args, userWriterOut, userWriterErr)
+	return lines, contextError(ctx, err)
+}
+
+func newLocalCmd(ctx context.Context, cmd string, args []string, env []string, stdin io.Reader) *exec.Cmd {
+	cmdEx := exec.CommandContext(ctx, cmd, args...)
+	cmdEx.WaitDelay = KILL_WAIT_DELAY
+	cmdEx.Stdin = stdin
+	if len(env) > 0 {
+		cmdEx.Env = append(os.Environ(), env...)
+	}
+	return cmdEx
 }
 
 func RunLocalCmdSimple(cmd string, args []string) ([]string, error) {
-	cmdEx := exec.Command(cmd, args...)
+	return runLocalCmdSimple(exec.Command(cmd, args...))
+}
+
+func runLocalCmdSimple(cmdEx *exec.Cmd) ([]string, error) {
 	var bOut bytes.Buffer
 	var bErr bytes.Buffer
 	cmdEx.Stdout = &bOut
@@ -67,7 +160,10 @@ func RunLocalCmdSimple(cmd string, args []string) ([]string, error) {
 }
 
 func RunLocalCmdWithProgess(cmd string, args []string, userWriterOut, userWriterErr io.Writer) ([]string, error) {
-	cmdEx := exec.Command(cmd, args...)
+	return runLocalCmdWithProgess(exec.Command(cmd, args...), userWriterOut, userWriterErr)
+}
+
+func runLocalCmdWithProgess(cmdEx *exec.Cmd, userWriterOut, userWriterErr io.Writer) ([]string, error) {
 	var bOut bytes.Buffer
 	var bErr bytes.Buffer
 
@@ -121,38 +217,92 @@ func RunLocalCmdWithProgess(cmd string, args []string, userWriterOut, userWriter
 
 // runs a command via SSH
 func RunSshCmd(client *ssh.Client, cmd string, args []string, userWriterOut, userWriterErr io.Writer) ([]string, error) {
+	return RunSshCmdContext(context.Background(), client, cmd, args, userWriterOut, userWriterErr)
+}
+
+// When the context is done the remote process gets a SIGTERM and the
+// session is closed
+func RunSshCmdContext(ctx context.Context, client *ssh.Client, cmd string, args []string, userWriterOut, userWriterErr io.Writer) ([]string, error) {
+	return RunSshCmdStdin(ctx, client, cmd, args, nil, userWriterOut, userWriterErr)
+}
+
+// stdin is sent to the remote process and closed afterwards
+func RunSshCmdStdin(ctx context.Context, client *ssh.Client, cmd string, args []string, stdin io.Reader, userWriterOut, userWriterErr io.Writer) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var lines []string
+	var err error
 	if userWriterOut == nil && userWriterErr == nil {
-		return RunSshCmdSimple(client, cmd, args)
+		lines, err = runSshCmdSimple(ctx, client, cmd, args, stdin)
+	} else {
+		lines, err = runSshCmdWithProgress(ctx, client, cmd, args, stdin, userWriterOut, userWriterErr)
+	}
+	return lines, contextError(ctx, err)
+}
+
+// closes the session when the context is done - call the returned func
+// when the command has finished
+func watchSession(ctx context.Context, session *ssh.Session) func() {
+	if ctx.Done() == nil {
+		return func() {}
+	}
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			// most sshd ignore signals - closing the channel ends the command
+			session.Signal(ssh.SIGTERM)
+			session.Close()
+		case <-done:
+		}
+	}()
+	return func() {
+		close(done)
 	}
-	return RunSshCmdWithProgress(client, cmd, args, userWriterOut, userWriterErr)
 }
 
 func RunSshCmdSimple(client *ssh.Client, cmd string, args []string) ([]string, error) {
-	session, err := client.NewSession()
+	return runSshCmdSimple(context.Background(), client, cmd, args, nil)
+}
+
+func runSshCmdSimple(ctx context.Context, client *ssh.Client, cmd string, args []string, stdin io.Reader) ([]string, error) {
+	// the session setup is part of the latency
+	start := time.Now()
+	session, err := newSession(client)
 	if err != nil {
 		return nil, err
 	}
 	defer session.Close()
+	defer watchSession(ctx, session)()
 	var bOut bytes.Buffer
 	var bErr bytes.Buffer
+	session.Stdin = stdin
 	session.Stdout = &bOut
 	session.Stderr = &bErr
 
-	for _, arg := range args {
-		cmd += " " + arg
-	}
+	cmd = QuoteCmd(cmd, args)
 	err = session.Run(cmd)
+	if ctx.Err() == nil {
+		getLatency(client).add(false, time.Since(start))
+	}
 	lines := mergeOutAndErr(bOut, bErr)
 
 	return lines, err
 }
 
 func RunSshCmdWithProgress(client *ssh.Client, cmd string, args []string, userWriterOut, userWriterErr io.Writer) ([]string, error) {
-	session, err := client.NewSession()
+	return runSshCmdWithProgress(context.Background(), client, cmd, args, nil, userWriterOut, userWriterErr)
+}
+
+func runSshCmdWithProgress(ctx context.Context, client *ssh.Client, cmd string, args []string, stdin io.Reader, userWriterOut, userWriterErr io.Writer) ([]string, error) {
+	session, err := newSession(client)
 	if err != nil {
 		return nil, err
 	}
 	defer session.Close()
+	defer watchSession(ctx, session)()
+	session.Stdin = stdin
 	var bOut bytes.Buffer
 	var bErr bytes.Buffer
 
@@ -165,9 +315,7 @@ func RunSshCmdWithProgress(client *ssh.Client, cmd string, args []string, userWr
 		return nil, err
 	}
 
-	for _, arg := range args {
-		cmd += " " + arg
-	}
+	cmd = QuoteCmd(cmd, args)
 
 	outWriters := make([]io.Writer, 0, 2)
 	errWriters := make([]io.Writer, 0, 2)
@@ -0,0 +1,317 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package run
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+var ErrShellFraming = errors.New("persistent shell framing error")
+
+const (
+	// after a failure the commands use own sessions - the shell is started
+	// again after the backoff which doubles with each failure in a row
+	SHELL_RETRY_MIN = 5 * time.Second
+	SHELL_RETRY_MAX = 5 * time.Minute
+)
+
+// Exit status of a command in the persistent shell
+type ExitError struct {
+	Status int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("Process exited with status %d", e.Status)
+}
+
+func (e *ExitError) ExitStatus() int {
+	return e.Status
+}
+
+// Average run time of the commands of a client
+type LatencyStat struct {
+	Count   int
+	Average time.Duration
+}
+
+type latency struct {
+	lock    sync.Mutex
+	session LatencyStat
+	shell   LatencyStat
+}
+
+func (l *latency) add(shell bool, d time.Duration) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	stat := &l.session
+	if shell {
+		stat = &l.shell
+	}
+	stat.Average = (stat.Average*time.Duration(stat.Count) + d) / time.Duration(stat.Count+1)
+	stat.Count++
+}
+
+var latencies sync.Map
+
+func getLatency(client *ssh.Client) *latency {
+	l, _ := latencies.LoadOrStore(client, &latency{})
+	return l.(*latency)
+}
+
+// Latency of commands in own sessions and in the persistent shell
+func GetLatencyStats(client *ssh.Client) (LatencyStat, LatencyStat) {
+	if client == nil {
+		return LatencyStat{}, LatencyStat{}
+	}
+	l, ok := latencies.Load(client)
+	if !ok {
+		return LatencyStat{}, LatencyStat{}
+	}
+	lat := l.(*latency)
+	lat.lock.Lock()
+	defer lat.lock.Unlock()
+	return lat.session, lat.shell
+}
+
+// One long living sh per client. The commands run one after the other -
+// their output is framed by a random marker followed by the exit status.
+type shellSession struct {
+	lock sync.Mutex
+	// only for session - CloseShell must not wait for a running command
+	sessionLock sync.Mutex
+	session     *ssh.Session
+	stdin       io.WriteCloser
+	stdout      *bufio.Reader
+	stderr      *bufio.Reader
+	// after a failure the client uses own sessions until retryAt
+	retryAt time.Time
+	backoff time.Duration
+}
+
+var shells sync.Map
+
+// Closes the persistent shell of the client - called on disconnect
+func CloseShell(client *ssh.Client) {
+	if client == nil {
+		return
+	}
+	if s, ok := shells.LoadAndDelete(client); ok {
+		s.(*shellSession).close()
+	}
+	latencies.Delete(client)
+}
+
+func getShell(client *ssh.Client) *shellSession {
+	s, _ := shells.LoadOrStore(client, &shellSession{})
+	return s.(*shellSession)
+}
+
+func (s *shellSession) close() {
+	s.sessionLock.Lock()
+	defer s.sessionLock.Unlock()
+	if s.session != nil {
+		s.session.Close()
+		s.session = nil
+	}
+}
+
+func (s *shellSession) start(client *ssh.Client) error {
+	session, err := newSession(client)
+	if err != nil {
+		return err
+	}
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		session.Close()
+		return err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		return err
+	}
+	stderr, err := session.StderrPipe()
+	if err != nil {
+		session.Close()
+		return err
+	}
+	// sh and not the login shell - the framing needs POSIX syntax
+	err = session.Start("exec sh")
+	if err != nil {
+		session.Close()
+		return err
+	}
+	s.sessionLock.Lock()
+	s.session = session
+	s.sessionLock.Unlock()
+	s.stdin = stdin
+	s.stdout = bufio.NewReader(stdout)
+	s.stderr = bufio.NewReader(stderr)
+	return nil
+}
+
+// stdin of the command is /dev/null - otherwise it reads the next commands
+func shellScript(cmd string, args []string, marker string) string {
+	return fmt.Sprintf("{ %s ; } </dev/null\nvboxssh_rc=$?\nprintf '\\n%s %%d\\n' \"$vboxssh_rc\"\nprintf '\\n%s\\n' >&2\n",
+		QuoteCmd(cmd, args), marker, marker)
+}
+
+func newMarker() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return "__VBOXSSH_" + hex.EncodeToString(b) + "__"
+}
+
+// reads up to the marker line and returns the text before it and the rest
+// of the marker line - on an error the text read so far
+func readFramed(r *bufio.Reader, marker string) (string, string, error) {
+	var b strings.Builder
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			b.WriteString(line)
+			return b.String(), "", errors.Join(ErrShellFraming, err)
+		}
+		if rest, ok := strings.CutPrefix(line, marker); ok {
+			// the newline in front of the marker was added by printf
+			text := strings.TrimSuffix(b.String(), "\n")
+			return text, strings.TrimSpace(rest), nil
+		}
+		b.WriteString(line)
+	}
+}
+
+// Runs the command in the persistent shell of the client. If the shell is
+// busy or failed a short time ago, the command gets its own session. On a
+// framing error the following commands use own sessions until the shell is
+// started again after a backoff. The command itself only runs again in an own
+// session if it was not sent - otherwise it may have run and the error is
+// returned.
+func RunShellCmdContext(ctx context.Context, client *ssh.Client, cmd string, args []string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s := getShell(client)
+	if !s.lock.TryLock() {
+		return RunSshCmdContext(ctx, client, cmd, args, nil, nil)
+	}
+	defer s.lock.Unlock()
+	if time.Now().Before(s.retryAt) {
+		return RunSshCmdContext(ctx, client, cmd, args, nil, nil)
+	}
+	if s.stdin == nil {
+		err := s.start(client)
+		if err != nil {
+			s.fail()
+			return RunSshCmdContext(ctx, client, cmd, args, nil, nil)
+		}
+	}
+
+	start := time.Now()
+	marker := newMarker()
+	_, err := io.WriteString(s.stdin, shellScript(cmd, args, marker))
+	if err != nil {
+		// not sent - safe to run it again
+		s.fail()
+		return RunSshCmdContext(ctx, client, cmd, args, nil, nil)
+	}
+
+	type result struct {
+		text, rest string
+		err        error
+	}
+	outCh := make(chan result, 1)
+	errCh := make(chan result, 1)
+	go func() {
+		text, rest, err := readFramed(s.stdout, marker)
+		outCh <- result{text, rest, err}
+	}()
+	go func() {
+		text, rest, err := readFramed(s.stderr, marker)
+		errCh <- result{text, rest, err}
+	}()
+
+	var out, serr result
+	for range 2 {
+		select {
+		case out = <-outCh:
+		case serr = <-errCh:
+		case <-ctx.Done():
+			// the command can not be stopped alone - the next command
+			// starts a new shell
+			s.reset()
+			return nil, ctx.Err()
+		}
+	}
+	if out.err != nil || serr.err != nil {
+		// the script was sent - a command without output may have run
+		s.fail()
+		return nil, errors.Join(out.err, serr.err)
+	}
+	rc, err := strconv.Atoi(out.rest)
+	if err != nil {
+		s.fail()
+		return nil, errors.Join(ErrShellFraming, err)
+	}
+	getLatency(client).add(true, time.Since(start))
+	s.backoff = 0
+
+	str := out.text
+	if serr.text != "" {
+		if str != "" {
+			str += "\n"
+		}
+		str += serr.text
+	}
+	// same result as a command in an own session
+	lines := strings.Split(str, "\n")
+	if rc != 0 {
+		return lines, &ExitError{Status: rc}
+	}
+	return lines, nil
+}
+
+func (s *shellSession) fail() {
+	s.backoff = min(max(2*s.backoff, SHELL_RETRY_MIN), SHELL_RETRY_MAX)
+	s.retryAt = time.Now().Add(s.backoff)
+	s.reset()
+}
+
+func (s *shellSession) reset() {
+	s.stdin = nil
+	s.close()
+}
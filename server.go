@@ -27,11 +27,16 @@ package main
 import (
 	"errors"
 	"fmt"
+	"strings"
 
+	"bytemystery-com/vboxssh/server"
 	"bytemystery-com/vboxssh/vm"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/widget"
+	"golang.org/x/crypto/ssh"
 )
 
 func addNewServer() {
@@ -164,22 +169,96 @@ func reconnectServer() error {
 	if err != nil {
 		return err
 	}
-	err = s.Reconnect(&s.Client.Client)
-	if err == nil {
-		SetStatusText(fmt.Sprintf(lang.X("status.server_reconnect_ok", "Reconnect for server '%s'."), s.Name), MsgInfo)
-	} else {
-		SetStatusText(fmt.Sprintf(lang.X("status.server_reconnect_error", "Reconnect for server '%s' failed. (%s)"), s.Name, err.Error()), MsgError)
+	// not in the UI thread - the host key dialog may wait for the user
+	go func() {
+		err := s.Reconnect(&s.Client.Client)
+		if err == nil {
+			SetStatusText(fmt.Sprintf(lang.X("status.server_reconnect_ok", "Reconnect for server '%s'."), s.Name), MsgInfo)
+		} else {
+			SetStatusText(fmt.Sprintf(lang.X("status.server_reconnect_error", "Reconnect for server '%s' failed. (%s)"), s.Name, err.Error()), MsgError)
+			showHostKeyError(s, err)
+		}
+		treeRefresh()
+	}()
+	return nil
+}
+
+func connStateText(state vm.ConnState) (string, widget.Importance) {
+	switch state {
+	case vm.ConnState_connected:
+		return lang.X("server.state.connected", "connected"), widget.SuccessImportance
+	case vm.ConnState_degraded:
+		return lang.X("server.state.degraded", "degraded"), widget.WarningImportance
+	case vm.ConnState_reconnecting:
+		return lang.X("server.state.reconnecting", "reconnecting"), widget.WarningImportance
+	default:
+		return lang.X("server.state.offline", "offline"), widget.DangerImportance
 	}
-	return err
+}
+
+// called by the health monitor of the servers
+func ConnStateCallBack(uuid string) {
+	s := Data.GetServer(uuid, true)
+	if s == nil {
+		return
+	}
+	state := s.GetConnState()
+	text, _ := connStateText(state)
+	msg := fmt.Sprintf(lang.X("status.server_state", "Server '%s' is %s."), s.Name, text)
+	switch state {
+	case vm.ConnState_connected:
+		SetStatusText(msg, MsgInfo)
+	case vm.ConnState_offline:
+		SetStatusText(msg, MsgError)
+	default:
+		SetStatusText(msg, MsgWarning)
+	}
+	treeRefresh()
+	fyne.Do(func() { UpdateToolbarMenu() })
 }
 
 func setAfterConnectStatus(s *vm.VmServer, err error) {
 	if err == nil {
 		SetStatusText(fmt.Sprintf(lang.X("status.server_connect_ok", "Connect for server '%s'."), s.Name), MsgInfo)
 		fyne.Do(func() { UpdateUI() })
+		checkCertExpiry(s)
 	} else {
 		SetStatusText(fmt.Sprintf(lang.X("status.server_connect_error", "Connect for server '%s' failed. (%s)"), s.Name, err.Error()), MsgError)
 		fyne.Do(func() { UpdateUI() })
+		showHostKeyError(s, err)
 	}
 	treeRefresh()
 }
+
+// Asks the user if an unknown host key should be trusted.
+// Called from the connect go routine - never from the UI thread
+func confirmHostKey(hostname string, key ssh.PublicKey) bool {
+	ch := make(chan bool, 1)
+	fyne.Do(func() {
+		msg := fmt.Sprintf(lang.X("server.hostkey.unknown.msg", "The authenticity of host '%s' can't be established.\n\nKey type: %s\nFingerprint: %s\n\nDo you want to trust this host key and continue connecting ?"),
+			hostname, key.Type(), ssh.FingerprintSHA256(key))
+		dia := dialog.NewConfirm(lang.X("server.hostkey.unknown.title", "Unknown host key"), msg, func(ok bool) {
+			ch <- ok
+		}, Gui.MainWindow)
+		dia.SetConfirmText(lang.X("server.hostkey.unknown.accept", "Trust"))
+		dia.SetDismissText(lang.X("cancel", "Cancel"))
+		dia.Show()
+	})
+	return <-ch
+}
+
+func showHostKeyError(s *vm.VmServer, err error) {
+	var changedErr *server.HostKeyChangedError
+	if !errors.As(err, &changedErr) {
+		return
+	}
+	known := make([]string, 0, len(changedErr.Known))
+	for _, item := range changedErr.Known {
+		known = append(known, server.FingerprintKey(item))
+	}
+	msg := fmt.Sprintf(lang.X("server.hostkey.changed.msg", "The host key of server '%s' (%s) has changed !!\n\nSomeone could be eavesdropping on you right now (man-in-the-middle attack)\nor the host key has just been replaced.\n\nPresented key:\n%s\n\nKnown key:\n%s\n\nThe connection was refused. If the change is expected use\n'Forget host key' on the SSH tab and connect again."),
+		s.Name, changedErr.Host, server.FingerprintKey(changedErr.Key), strings.Join(known, "\n"))
+	fyne.Do(func() {
+		dialog.ShowInformation(lang.X("server.hostkey.changed.title", "Host key changed"), msg, Gui.MainWindow)
+	})
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package server
+
+import (
+	"errors"
+	"net"
+	"os"
+
+	"bytemystery-com/vboxssh/run"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+const (
+	AGENT_SOCK_ENV = "SSH_AUTH_SOCK"
+)
+
+// True if a ssh-agent is reachable
+func IsAgentAvailable() bool {
+	conn, err := dialAgent()
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+func dialAgent() (net.Conn, error) {
+	sock := os.Getenv(AGENT_SOCK_ENV)
+	if sock == "" {
+		return nil, errors.New(AGENT_SOCK_ENV + " is not set")
+	}
+	return net.Dial("unix", sock)
+}
+
+// Signers of the agent in the order of the agent followed by the signer
+// of the key file - all in one callback because the client tries every
+// auth method only once
+func agentAuth(conn net.Conn, sig ssh.Signer) ssh.AuthMethod {
+	client := agent.NewClient(conn)
+	return ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+		signers, err := client.Signers()
+		if err != nil && sig == nil {
+			return nil, err
+		}
+		if sig != nil {
+			signers = append(signers, sig)
+		}
+		return signers, nil
+	})
+}
+
+// Forwards the local agent to the remote host - the sessions request the
+// forwarding in the run package
+func forwardAgent(client *ssh.Client) error {
+	sock := os.Getenv(AGENT_SOCK_ENV)
+	if sock == "" {
+		return errors.New(AGENT_SOCK_ENV + " is not set")
+	}
+	err := agent.ForwardToRemote(client, sock)
+	if err != nil {
+		return err
+	}
+	run.SetAgentForwarding(client, true)
+	return nil
+}
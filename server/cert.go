@@ -0,0 +1,127 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package server
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	CERT_SUFFIX = "-cert.pub"
+	// warn when less than this or 10% of the validity window is left
+	CERT_EXPIRY_WARNING = time.Hour
+)
+
+// The user certificate - CertFile / CertFileContent or <KeyFile>-cert.pub
+// like ssh does
+func (server *Server) GetCertificate() (*ssh.Certificate, error) {
+	var data []byte
+	var err error
+	if len(server.CertFileContent) > 0 {
+		data = server.CertFileContent
+	} else if server.CertFile != "" {
+		data, err = server.readFile(server.CertFile)
+	} else if server.KeyFile != "" && !strings.HasSuffix(server.KeyFile, ".pub") {
+		data, err = server.readFile(server.KeyFile + CERT_SUFFIX)
+	} else {
+		return nil, errors.New("no certificate")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseCertificate(data)
+}
+
+func (server *Server) readFile(file string) ([]byte, error) {
+	if server.KeyFileReader != nil {
+		return server.KeyFileReader(file)
+	}
+	return os.ReadFile(file)
+}
+
+func ParseCertificate(data []byte) (*ssh.Certificate, error) {
+	key, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	if err != nil {
+		return nil, err
+	}
+	cert, ok := key.(*ssh.Certificate)
+	if !ok {
+		return nil, errors.New("not a certificate")
+	}
+	if cert.CertType != ssh.UserCert {
+		return nil, errors.New("not a user certificate")
+	}
+	return cert, nil
+}
+
+// Zero time for an infinite validity
+func CertValidity(cert *ssh.Certificate) (time.Time, time.Time) {
+	var after, before time.Time
+	if cert.ValidAfter != 0 {
+		after = time.Unix(int64(cert.ValidAfter), 0)
+	}
+	if cert.ValidBefore != ssh.CertTimeInfinity {
+		before = time.Unix(int64(cert.ValidBefore), 0)
+	}
+	return after, before
+}
+
+// True if the certificate expires soon or has already expired
+func CertExpiresSoon(cert *ssh.Certificate, now time.Time) bool {
+	after, before := CertValidity(cert)
+	if before.IsZero() {
+		return false
+	}
+	warn := CERT_EXPIRY_WARNING
+	if !after.IsZero() {
+		warn = max(warn, before.Sub(after)/10)
+	}
+	return before.Sub(now) < warn
+}
+
+// Combines the private key with the certificate, the plain signer is used
+// if there is no certificate
+func (server *Server) certSigner(sig ssh.Signer) (ssh.Signer, error) {
+	explicit := server.CertFile != "" || len(server.CertFileContent) > 0
+	cert, err := server.GetCertificate()
+	if err != nil {
+		// only an explicit certificate must exist
+		if explicit {
+			return nil, err
+		}
+		return sig, nil
+	}
+	certSig, err := ssh.NewCertSigner(cert, sig)
+	if err != nil && !explicit {
+		// an old <KeyFile>-cert.pub of a rotated key - ignored like OpenSSH does
+		return sig, nil
+	}
+	return certSig, err
+}
@@ -0,0 +1,326 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// Host presented a key which differs from the known one
+type HostKeyChangedError struct {
+	Host  string
+	Key   ssh.PublicKey
+	Known []ssh.PublicKey
+}
+
+func (e *HostKeyChangedError) Error() string {
+	return fmt.Sprintf("host key for %s has changed (%s)", e.Host, FingerprintKey(e.Key))
+}
+
+// Host key is unknown and was not accepted by the user
+type HostKeyRejectedError struct {
+	Host string
+	Key  ssh.PublicKey
+}
+
+func (e *HostKeyRejectedError) Error() string {
+	return fmt.Sprintf("host key for %s was not accepted (%s)", e.Host, FingerprintKey(e.Key))
+}
+
+// Only used to get the known keys for a host from the known_hosts file
+type probeKey struct{}
+
+func (probeKey) Type() string {
+	return "probe"
+}
+
+func (probeKey) Marshal() []byte {
+	return []byte("probe")
+}
+
+func (probeKey) Verify([]byte, *ssh.Signature) error {
+	return errors.New("probe key")
+}
+
+// Returns ~/.ssh/known_hosts or "" if there is no home dir
+func KnownHostsFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return ""
+	}
+	return filepath.Join(home, ".ssh", "known_hosts")
+}
+
+func FingerprintKey(key ssh.PublicKey) string {
+	if key == nil {
+		return ""
+	}
+	return key.Type() + " " + ssh.FingerprintSHA256(key)
+}
+
+func keysEqual(a, b ssh.PublicKey) bool {
+	return bytes.Equal(a.Marshal(), b.Marshal())
+}
+
+// The stored host key of this server
+func (server *Server) GetHostKey() (ssh.PublicKey, error) {
+	if server.HostKey == "" {
+		return nil, errors.New("no host key")
+	}
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(server.HostKey))
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (server *Server) setHostKey(key ssh.PublicKey) {
+	// certificates will be renewed, the CA in known_hosts is the trust anchor
+	if _, ok := key.(*ssh.Certificate); ok {
+		return
+	}
+	str := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+	if str == server.HostKey {
+		return
+	}
+	server.HostKey = str
+	if server.HostKeyUpdated != nil {
+		server.HostKeyUpdated()
+	}
+}
+
+// Removes the stored key and the entries in known_hosts so that the next
+// connect asks again (key rotation)
+func (server *Server) ForgetHostKey() error {
+	server.HostKey = ""
+	if server.HostKeyUpdated != nil {
+		server.HostKeyUpdated()
+	}
+	return removeKnownHosts(KnownHostsFile(), net.JoinHostPort(server.Host, fmt.Sprint(server.Port)))
+}
+
+func (server *Server) checkHostKey(hostname string, remote net.Addr, key ssh.PublicKey, pinned []ssh.PublicKey) error {
+	// keys from the host key files are pinned
+	if len(pinned) > 0 {
+		for _, item := range pinned {
+			if keysEqual(key, item) {
+				return nil
+			}
+		}
+		return &HostKeyChangedError{Host: hostname, Key: key, Known: pinned}
+	}
+
+	known, err := server.GetHostKey()
+	if err == nil {
+		if keysEqual(key, known) {
+			return nil
+		}
+		return &HostKeyChangedError{Host: hostname, Key: key, Known: []ssh.PublicKey{known}}
+	}
+
+	file := KnownHostsFile()
+	ok, want, err := checkKnownHosts(file, hostname, remote, key)
+	if err != nil {
+		return err
+	}
+	if ok {
+		server.setHostKey(key)
+		return nil
+	}
+	if len(want) > 0 {
+		return &HostKeyChangedError{Host: hostname, Key: key, Known: want}
+	}
+
+	// trust on first use
+	if server.HostKeyConfirm == nil || !server.HostKeyConfirm(hostname, key) {
+		return &HostKeyRejectedError{Host: hostname, Key: key}
+	}
+	server.setHostKey(key)
+	err = appendKnownHosts(file, hostname, key)
+	if err != nil {
+		// the key is stored with the server - the next connect does not ask again
+		return fmt.Errorf("host key accepted but not saved to known_hosts: %w", err)
+	}
+	return nil
+}
+
+// Keys which are expected for this host - used for the host key algorithms
+func (server *Server) expectedHostKeys(hostname string, remote net.Addr, pinned []ssh.PublicKey) []ssh.PublicKey {
+	if len(pinned) > 0 {
+		return pinned
+	}
+	known, err := server.GetHostKey()
+	if err == nil {
+		return []ssh.PublicKey{known}
+	}
+	_, want, _ := checkKnownHosts(KnownHostsFile(), hostname, remote, probeKey{})
+	return want
+}
+
+// Known key types first so that the server presents the key we know
+func hostKeyAlgorithms(keys []ssh.PublicKey) []string {
+	if len(keys) == 0 {
+		return nil
+	}
+	algos := make([]string, 0, len(keys)+8)
+	add := func(list ...string) {
+		for _, item := range list {
+			if !slices.Contains(algos, item) {
+				algos = append(algos, item)
+			}
+		}
+	}
+	for _, key := range keys {
+		switch key.Type() {
+		case ssh.KeyAlgoRSA:
+			add(ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA)
+		case ssh.CertAlgoRSAv01:
+			add(ssh.CertAlgoRSASHA512v01, ssh.CertAlgoRSASHA256v01, ssh.CertAlgoRSAv01)
+		default:
+			add(key.Type())
+		}
+	}
+	add(ssh.SupportedAlgorithms().HostKeys...)
+	return algos
+}
+
+// Returns true if the key is known, otherwise the known keys for the host
+func checkKnownHosts(file, hostname string, remote net.Addr, key ssh.PublicKey) (bool, []ssh.PublicKey, error) {
+	if file == "" {
+		return false, nil, nil
+	}
+	if _, err := os.Stat(file); err != nil {
+		return false, nil, nil
+	}
+	callBack, err := knownhosts.New(file)
+	if err != nil {
+		return false, nil, err
+	}
+	err = callBack(hostname, remote, key)
+	if err == nil {
+		return true, nil, nil
+	}
+	var keyErr *knownhosts.KeyError
+	if errors.As(err, &keyErr) {
+		want := make([]ssh.PublicKey, 0, len(keyErr.Want))
+		for _, item := range keyErr.Want {
+			want = append(want, item.Key)
+		}
+		return false, want, nil
+	}
+	return false, nil, err
+}
+
+func appendKnownHosts(file, hostname string, key ssh.PublicKey) error {
+	if file == "" {
+		return errors.New("no known_hosts file")
+	}
+	err := os.MkdirAll(filepath.Dir(file), 0o700)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key) + "\n")
+	return err
+}
+
+// |1|salt|hash - see sshd(8)
+func matchHashedHost(entry, host string) bool {
+	items := strings.Split(entry, "|")
+	if len(items) != 4 || items[1] != "1" {
+		return false
+	}
+	salt, err := base64.StdEncoding.DecodeString(items[2])
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha1.New, salt)
+	mac.Write([]byte(host))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)) == items[3]
+}
+
+// Removes the plain and hashed entries for hostname - @cert-authority
+// and @revoked lines are kept
+func removeKnownHosts(file, hostname string) error {
+	if file == "" {
+		return nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	host := knownhosts.Normalize(hostname)
+	var out bytes.Buffer
+	removed := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		if len(fields) > 1 && !strings.HasPrefix(fields[0], "#") && !strings.HasPrefix(fields[0], "@") {
+			match := false
+			for _, item := range strings.Split(fields[0], ",") {
+				if item == host || matchHashedHost(item, host) {
+					match = true
+					break
+				}
+			}
+			if match {
+				removed = true
+				continue
+			}
+		}
+		out.WriteString(line)
+		out.WriteString("\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if !removed {
+		return nil
+	}
+	return os.WriteFile(file, out.Bytes(), 0o600)
+}
@@ -25,59 +25,87 @@
 package server
 
 import (
-	"bytes"
 	"errors"
+	"fmt"
 	"net"
 	"os"
 	"strconv"
+	"sync"
 	"sync/atomic"
 	"time"
 
+	"bytemystery-com/vboxssh/run"
+
 	"golang.org/x/crypto/ssh"
 )
 
-type countingConn struct {
-	net.Conn
+const (
+	DIAL_TIMEOUT      = 10 * time.Second
+	HANDSHAKE_TIMEOUT = 30 * time.Second
+	TCP_KEEPALIVE     = 30 * time.Second
+)
+
+// counters of all connections of a server - kept over reconnects
+type connStats struct {
 	readBytes  atomic.Uint64
 	writeBytes atomic.Uint64
 	reConnects atomic.Uint64
-	startTime  time.Time
+	startTime  atomic.Int64 // unix nano
+}
+
+// protects Server.stats
+var statsLock sync.Mutex
+
+// one per connection chain - closing an old chain must not touch a new one
+type countingConn struct {
+	net.Conn
+	stats *connStats
 }
 
 func (c *countingConn) Read(b []byte) (int, error) {
 	n, err := c.Conn.Read(b)
-	c.readBytes.Add(uint64(n))
+	c.stats.readBytes.Add(uint64(n))
 	return n, err
 }
 
 func (c *countingConn) Write(b []byte) (int, error) {
 	n, err := c.Conn.Write(b)
-	c.writeBytes.Add(uint64(n))
+	c.stats.writeBytes.Add(uint64(n))
 	return n, err
 }
 
-func (c *countingConn) Reconnect() {
-	c.reConnects.Add(1)
-	c.startTime = time.Now()
-}
-
 type Server struct {
-	Name             string                       `json:"name"`
-	Host             string                       `json:"host"`
-	Port             int                          `json:"port"`
-	User             string                       `json:"user"`
-	Password         string                       `json:"pass"`
-	KeyFile          string                       `json:"keyfile"`
-	KeyFileReader    func(string) ([]byte, error) `json:"-"`
-	KeyFileContent   []byte                       `json:"keyfilecontent"`
-	HostFiles        []string                     `json:"hostfiles"`
-	HostFilesContent [][]byte                     `json:"hostfilescontent"`
-	HostFileReader   func(string) ([]byte, error) `json:"-"`
-	countConnection  *countingConn                `json:"-"`
+	Name             string                           `json:"name"`
+	Host             string                           `json:"host"`
+	Port             int                              `json:"port"`
+	User             string                           `json:"user"`
+	Password         string                           `json:"pass"`
+	KeyFile          string                           `json:"keyfile"`
+	KeyFileReader    func(string) ([]byte, error)     `json:"-"`
+	KeyFileContent   []byte                           `json:"keyfilecontent"`
+	CertFile         string                           `json:"certfile"`
+	CertFileContent  []byte                           `json:"certfilecontent"`
+	HostFiles        []string                         `json:"hostfiles"`
+	HostFilesContent [][]byte                         `json:"hostfilescontent"`
+	HostFileReader   func(string) ([]byte, error)     `json:"-"`
+	UseAgent         bool                             `json:"useagent"`
+	AgentForward     bool                             `json:"agentforward"`
+	HostKey          string                           `json:"hostkey"`
+	HostKeyConfirm   func(string, ssh.PublicKey) bool `json:"-"`
+	HostKeyUpdated   func()                           `json:"-"`
+	JumpHosts        []Server                         `json:"jumphosts"`
+	ConfigAlias      string                           `json:"configalias"`
+	MaxSessions      int                              `json:"maxsessions"`
+	stats            *connStats                       `json:"-"`
 }
 
 func (server *Server) IsAlive() bool {
-	conn, err := net.Dial("tcp", net.JoinHostPort(server.Host, strconv.Itoa(server.Port)))
+	// behind jump hosts only the first one is reachable
+	addr := net.JoinHostPort(server.Host, strconv.Itoa(server.Port))
+	if len(server.JumpHosts) > 0 {
+		addr = net.JoinHostPort(server.JumpHosts[0].Host, strconv.Itoa(server.JumpHosts[0].Port))
+	}
+	conn, err := net.DialTimeout("tcp", addr, DIAL_TIMEOUT)
 	if err != nil {
 		return false
 	}
@@ -97,7 +125,7 @@ func (server *Server) Reconnect(client **ssh.Client) error {
 		return err
 	}
 	*client = c
-	server.countConnection.Reconnect()
+	server.connStats().reConnects.Add(1)
 	return nil
 }
 
@@ -108,6 +136,8 @@ func (server *Server) Disonnect(client **ssh.Client) error {
 	if *client == nil {
 		return errors.New("client is nil")
 	}
+	run.SetAgentForwarding(*client, false)
+	run.CloseShell(*client)
 	err := (*client).Close()
 	*client = nil
 	return err
@@ -115,129 +145,237 @@ func (server *Server) Disonnect(client **ssh.Client) error {
 
 func (server *Server) Connect() (*ssh.Client, error) {
 	var client *ssh.Client = nil
-	// var err error
 	if len(server.Host) > 0 {
-		var sig ssh.Signer = nil
-		if len(server.KeyFile) > 0 || len(server.KeyFileContent) > 0 {
-			var key []byte
-			var err error
-			if len(server.KeyFileContent) > 0 {
-				key = server.KeyFileContent
-			} else {
-				if server.KeyFileReader != nil {
-					key, err = server.KeyFileReader(server.KeyFile)
-				} else {
-					key, err = os.ReadFile(server.KeyFile)
-				}
+		// dial through the jump hosts like ProxyJump
+		dialer := net.Dialer{Timeout: DIAL_TIMEOUT, KeepAlive: TCP_KEEPALIVE}
+		dial := dialer.Dial
+		jumps := make([]*ssh.Client, 0, len(server.JumpHosts))
+		closeJumps := func() {
+			for i := len(jumps) - 1; i >= 0; i-- {
+				jumps[i].Close()
 			}
+		}
+		for i := range server.JumpHosts {
+			hop := server.jumpHost(i)
+			conn, err := dial("tcp", hop.address())
 			if err != nil {
-				return nil, err
+				closeJumps()
+				return nil, fmt.Errorf("jump host %s: %w", hop.address(), err)
 			}
-			if len(server.Password) > 0 {
-				sig, err = ssh.ParsePrivateKeyWithPassphrase([]byte(key), []byte(server.Password))
-			} else {
-				sig, err = ssh.ParsePrivateKey([]byte(key))
+			// the first connection is the one on the wire
+			if i == 0 {
+				conn = server.countConn(conn)
 			}
+			jumpClient, err := hop.handshake(conn)
+			if err != nil {
+				conn.Close()
+				closeJumps()
+				return nil, fmt.Errorf("jump host %s: %w", hop.address(), err)
+			}
+			jumps = append(jumps, jumpClient)
+			dial = jumpClient.Dial
+		}
+
+		conn, err := dial("tcp", server.address())
+		if err != nil {
+			closeJumps()
+			return nil, err
+		}
+		if len(jumps) == 0 {
+			conn = server.countConn(conn)
+		}
+		client, err = server.handshake(conn)
+		if err != nil {
+			conn.Close()
+			closeJumps()
+			return nil, err
+		}
+		if len(jumps) > 0 {
+			go func() {
+				client.Wait()
+				closeJumps()
+			}()
+		}
 
+		if server.AgentForward {
+			err = forwardAgent(client)
 			if err != nil {
+				client.Close()
 				return nil, err
 			}
 		}
-		var hostKeys []ssh.PublicKey
-		if len(server.HostFiles) > 0 || len(server.HostFilesContent) > 0 {
-			if len(server.HostFilesContent) > 0 {
-				for _, host := range server.HostFilesContent {
-					hKey, _, _, _, err := ssh.ParseAuthorizedKey(host)
-					if err == nil {
-						hostKeys = append(hostKeys, hKey)
-					}
-				}
-			} else {
-				hostKeys = make([]ssh.PublicKey, 0, len(server.HostFiles))
-				for _, item := range server.HostFiles {
-					var host []byte
-					var err error
-					if server.HostFileReader != nil {
-						host, err = server.HostFileReader(item)
-					} else {
-						host, err = os.ReadFile(item)
-					}
-					if err == nil {
-						hKey, _, _, _, err := ssh.ParseAuthorizedKey(host)
-						if err == nil {
-							hostKeys = append(hostKeys, hKey)
-						}
-					}
-				}
-			}
+	} else {
+		client = nil
+	}
+	server.connStats().startTime.Store(time.Now().UnixNano())
+	return client, nil
+}
+
+func (server *Server) address() string {
+	return server.Host + ":" + strconv.Itoa(server.Port)
+}
+
+// Copy of a jump host with the readers and callbacks of the server. The
+// saved entry is not changed - except for a learned host key.
+func (server *Server) jumpHost(i int) *Server {
+	hop := server.JumpHosts[i]
+	hop.JumpHosts = nil
+	hop.stats = nil
+	if hop.Port == 0 {
+		hop.Port = 22
+	}
+	if hop.KeyFileReader == nil {
+		hop.KeyFileReader = server.KeyFileReader
+	}
+	if hop.HostFileReader == nil {
+		hop.HostFileReader = server.HostFileReader
+	}
+	if hop.HostKeyConfirm == nil {
+		hop.HostKeyConfirm = server.HostKeyConfirm
+	}
+	updated := hop.HostKeyUpdated
+	if updated == nil {
+		updated = server.HostKeyUpdated
+	}
+	hop.HostKeyUpdated = func() {
+		if i < len(server.JumpHosts) {
+			server.JumpHosts[i].HostKey = hop.HostKey
+		}
+		if updated != nil {
+			updated()
 		}
+	}
+	return &hop
+}
 
-		auth := []ssh.AuthMethod{}
-		if sig != nil {
-			auth = append(auth, ssh.PublicKeys(sig))
-		} else if len(server.KeyFile) == 0 {
-			auth = append(auth, ssh.Password(server.Password), ssh.KeyboardInteractive(
-				func(user, instruction string, questions []string, echos []bool) (answers []string, err error) {
-					answers = make([]string, len(questions))
-					for n := range questions {
-						answers[n] = server.Password
-					}
-					return answers, nil
-				}))
-		}
-
-		config := &ssh.ClientConfig{
-			User: server.User,
-			Auth: auth,
-			/*
-				HostKeyAlgorithms: []string{
-					ssh.KeyAlgoED25519,
-				},
-			*/
-			HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
-				if len(hostKeys) > 0 {
-					for _, item := range hostKeys {
-						if bytes.Equal(key.Marshal(), item.Marshal()) {
-							return nil
-						}
-					}
-					return errors.New("hostkey does not match")
-				} else {
-					return nil
-				}
-			},
+func (server *Server) connStats() *connStats {
+	statsLock.Lock()
+	defer statsLock.Unlock()
+	if server.stats == nil {
+		server.stats = &connStats{}
+	}
+	return server.stats
+}
+
+func (server *Server) countConn(conn net.Conn) net.Conn {
+	return &countingConn{Conn: conn, stats: server.connStats()}
+}
+
+func (server *Server) handshake(conn net.Conn) (*ssh.Client, error) {
+	var sig ssh.Signer = nil
+	if len(server.KeyFile) > 0 || len(server.KeyFileContent) > 0 {
+		var key []byte
+		var err error
+		if len(server.KeyFileContent) > 0 {
+			key = server.KeyFileContent
+		} else {
+			if server.KeyFileReader != nil {
+				key, err = server.KeyFileReader(server.KeyFile)
+			} else {
+				key, err = os.ReadFile(server.KeyFile)
+			}
 		}
-		rawConn, err := net.Dial("tcp", server.Host+":"+strconv.Itoa(server.Port))
 		if err != nil {
 			return nil, err
 		}
-		if server.countConnection == nil {
-			server.countConnection = &countingConn{
-				Conn: rawConn,
-			}
+		if len(server.Password) > 0 {
+			sig, err = ssh.ParsePrivateKeyWithPassphrase([]byte(key), []byte(server.Password))
 		} else {
-			server.countConnection.Conn = rawConn
+			sig, err = ssh.ParsePrivateKey([]byte(key))
+		}
+
+		if err != nil {
+			return nil, err
 		}
-		sshConn, chans, reqs, err := ssh.NewClientConn(server.countConnection, server.Host+":"+strconv.Itoa(server.Port), config)
+		sig, err = server.certSigner(sig)
 		if err != nil {
 			return nil, err
 		}
-		client = ssh.NewClient(sshConn, chans, reqs)
+	}
+	var hostKeys []ssh.PublicKey
+	if len(server.HostFiles) > 0 || len(server.HostFilesContent) > 0 {
+		if len(server.HostFilesContent) > 0 {
+			for _, host := range server.HostFilesContent {
+				hKey, _, _, _, err := ssh.ParseAuthorizedKey(host)
+				if err == nil {
+					hostKeys = append(hostKeys, hKey)
+				}
+			}
+		} else {
+			hostKeys = make([]ssh.PublicKey, 0, len(server.HostFiles))
+			for _, item := range server.HostFiles {
+				var host []byte
+				var err error
+				if server.HostFileReader != nil {
+					host, err = server.HostFileReader(item)
+				} else {
+					host, err = os.ReadFile(item)
+				}
+				if err == nil {
+					hKey, _, _, _, err := ssh.ParseAuthorizedKey(host)
+					if err == nil {
+						hostKeys = append(hostKeys, hKey)
+					}
+				}
+			}
+		}
+	}
 
+	auth := []ssh.AuthMethod{}
+	if server.UseAgent {
+		agentConn, err := dialAgent()
 		if err != nil {
 			return nil, err
 		}
-	} else {
-		client = nil
+		// only needed while the handshake signs
+		defer agentConn.Close()
+		auth = append(auth, agentAuth(agentConn, sig))
+	} else if sig != nil {
+		auth = append(auth, ssh.PublicKeys(sig))
 	}
-	server.countConnection.startTime = time.Now()
-	return client, nil
+	if sig == nil && len(server.KeyFile) == 0 {
+		auth = append(auth, ssh.Password(server.Password), ssh.KeyboardInteractive(
+			func(user, instruction string, questions []string, echos []bool) (answers []string, err error) {
+				answers = make([]string, len(questions))
+				for n := range questions {
+					answers[n] = server.Password
+				}
+				return answers, nil
+			}))
+	}
+
+	config := &ssh.ClientConfig{
+		User:              server.User,
+		Auth:              auth,
+		HostKeyAlgorithms: hostKeyAlgorithms(server.expectedHostKeys(server.address(), conn.RemoteAddr(), hostKeys)),
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			// the user may need some time to confirm a new key
+			conn.SetDeadline(time.Time{})
+			defer conn.SetDeadline(time.Now().Add(HANDSHAKE_TIMEOUT))
+			return server.checkHostKey(hostname, remote, key, hostKeys)
+		},
+	}
+	// a dead host must not block the handshake forever - not supported by
+	// channels of jump hosts
+	conn.SetDeadline(time.Now().Add(HANDSHAKE_TIMEOUT))
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, server.address(), config)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return ssh.NewClient(sshConn, chans, reqs), nil
 }
 
 func (server *Server) GetStatistic() (uint64, uint64, uint64, time.Time) {
-	if server.countConnection != nil {
-		return server.countConnection.readBytes.Load(), server.countConnection.writeBytes.Load(), server.countConnection.reConnects.Load(), server.countConnection.startTime
-	} else {
+	statsLock.Lock()
+	stats := server.stats
+	statsLock.Unlock()
+	if stats == nil {
 		return 0, 0, 0, time.Time{}
 	}
+	var start time.Time
+	if t := stats.startTime.Load(); t != 0 {
+		start = time.Unix(0, t)
+	}
+	return stats.readBytes.Load(), stats.writeBytes.Load(), stats.reConnects.Load(), start
 }
@@ -0,0 +1,388 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package server
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	SSH_CONFIG_MAX_INCLUDE_DEPTH = 16
+	SSH_CONFIG_MAX_JUMP_DEPTH    = 8
+)
+
+// One concrete Host entry of ~/.ssh/config after applying all matching blocks
+type SshConfigHost struct {
+	Alias         string
+	HostName      string
+	Port          int
+	User          string
+	IdentityFiles []string
+	CertFiles     []string
+	ProxyJump     []string
+}
+
+type sshConfigBlock struct {
+	patterns []string
+	isMatch  bool
+	options  [][2]string
+	// patterns of the blocks with the Include of the file - all must match
+	guards [][]string
+}
+
+type SshConfig struct {
+	blocks  []sshConfigBlock
+	aliases []string
+}
+
+// Returns ~/.ssh/config or "" if there is no home dir
+func SshConfigFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return ""
+	}
+	return filepath.Join(home, ".ssh", "config")
+}
+
+func ParseSshConfig(file string) (*SshConfig, error) {
+	c := SshConfig{}
+	// options before the first Host line are valid for all hosts
+	c.blocks = append(c.blocks, sshConfigBlock{patterns: []string{"*"}})
+	err := c.parseFile(file, 0, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// options go to the block current until the first Host or Match line
+func (c *SshConfig) parseFile(file string, depth int, current int, guards [][]string) error {
+	if depth > SSH_CONFIG_MAX_INCLUDE_DEPTH {
+		return errors.New("include depth exceeded")
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, args := splitConfigLine(scanner.Text())
+		if key == "" || len(args) == 0 {
+			continue
+		}
+		switch key {
+		case "host":
+			c.blocks = append(c.blocks, sshConfigBlock{patterns: args, guards: guards})
+			current = len(c.blocks) - 1
+			for _, item := range args {
+				// a host of an include in a not matching block is never used
+				if !strings.ContainsAny(item, "*?!") && !containsString(c.aliases, item) && matchGuards(guards, item) {
+					c.aliases = append(c.aliases, item)
+				}
+			}
+		case "match":
+			// only "Match all" is evaluated
+			block := sshConfigBlock{isMatch: true, guards: guards}
+			if strings.ToLower(args[0]) == "all" {
+				block.patterns = []string{"*"}
+			}
+			c.blocks = append(c.blocks, block)
+			current = len(c.blocks) - 1
+		case "include":
+			// the included blocks only apply if the including block matches
+			inner := append(slices.Clone(guards), c.blocks[current].patterns)
+			for _, item := range args {
+				item = expandTilde(item)
+				if !filepath.IsAbs(item) {
+					item = filepath.Join(filepath.Dir(SshConfigFile()), item)
+				}
+				files, err := filepath.Glob(item)
+				if err != nil {
+					continue
+				}
+				for _, inc := range files {
+					// errors in included files are ignored like ssh does for missing ones
+					c.parseFile(inc, depth+1, current, inner)
+				}
+			}
+		default:
+			block := &c.blocks[current]
+			block.options = append(block.options, [2]string{key, strings.Join(args, " ")})
+		}
+	}
+	return scanner.Err()
+}
+
+// key is lower case - "Key value", "Key=value" and quoted values
+func splitConfigLine(line string) (string, []string) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", nil
+	}
+	n := strings.IndexAny(line, " \t=")
+	if n < 0 {
+		return strings.ToLower(line), nil
+	}
+	key := strings.ToLower(line[:n])
+	rest := strings.TrimLeft(line[n:], " \t")
+	rest = strings.TrimPrefix(rest, "=")
+	rest = strings.TrimSpace(rest)
+
+	args := make([]string, 0, 2)
+	var arg strings.Builder
+	inQuote := false
+	hasArg := false
+	for _, r := range rest {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			hasArg = true
+		case (r == ' ' || r == '\t') && !inQuote:
+			if hasArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				hasArg = false
+			}
+		case r == '#' && !inQuote && !hasArg:
+			return key, args
+		default:
+			arg.WriteRune(r)
+			hasArg = true
+		}
+	}
+	if hasArg {
+		args = append(args, arg.String())
+	}
+	return key, args
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func expandTilde(s string) string {
+	if s == "~" || strings.HasPrefix(s, "~/") {
+		home, err := os.UserHomeDir()
+		if err == nil {
+			return filepath.Join(home, s[1:])
+		}
+	}
+	return s
+}
+
+func matchHostPatterns(patterns []string, host string) bool {
+	match := false
+	for _, item := range patterns {
+		negate := strings.HasPrefix(item, "!")
+		item = strings.TrimPrefix(item, "!")
+		ok, _ := path.Match(strings.ToLower(item), strings.ToLower(host))
+		if ok {
+			if negate {
+				return false
+			}
+			match = true
+		}
+	}
+	return match
+}
+
+func (b *sshConfigBlock) matches(host string) bool {
+	return matchGuards(b.guards, host) && matchHostPatterns(b.patterns, host)
+}
+
+func matchGuards(guards [][]string, host string) bool {
+	for _, patterns := range guards {
+		if !matchHostPatterns(patterns, host) {
+			return false
+		}
+	}
+	return true
+}
+
+// All concrete host aliases in the order of the config file
+func (c *SshConfig) Aliases() []string {
+	return c.aliases
+}
+
+// Applies all matching blocks - the first value wins like in ssh
+func (c *SshConfig) Get(alias string) SshConfigHost {
+	h := SshConfigHost{Alias: alias}
+	seen := make(map[string]bool)
+	for _, block := range c.blocks {
+		if !block.matches(alias) {
+			continue
+		}
+		for _, opt := range block.options {
+			key, value := opt[0], opt[1]
+			if key == "identityfile" {
+				h.IdentityFiles = append(h.IdentityFiles, value)
+				continue
+			}
+			if key == "certificatefile" {
+				h.CertFiles = append(h.CertFiles, value)
+				continue
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			switch key {
+			case "hostname":
+				h.HostName = value
+			case "port":
+				h.Port, _ = strconv.Atoi(value)
+			case "user":
+				h.User = value
+			case "proxyjump":
+				if strings.ToLower(value) != "none" {
+					for _, item := range strings.Split(value, ",") {
+						h.ProxyJump = append(h.ProxyJump, strings.TrimSpace(item))
+					}
+				}
+			}
+		}
+	}
+	if h.HostName == "" {
+		h.HostName = alias
+	}
+	h.HostName = strings.ReplaceAll(h.HostName, "%h", alias)
+	if h.Port == 0 {
+		h.Port = 22
+	}
+	if h.User == "" {
+		u, err := user.Current()
+		if err == nil {
+			h.User = u.Username
+		}
+	}
+	for i, item := range h.IdentityFiles {
+		h.IdentityFiles[i] = h.expandTokens(item)
+	}
+	for i, item := range h.CertFiles {
+		h.CertFiles[i] = h.expandTokens(item)
+	}
+	return h
+}
+
+func (h *SshConfigHost) expandTokens(s string) string {
+	home, _ := os.UserHomeDir()
+	localUser := ""
+	u, err := user.Current()
+	if err == nil {
+		localUser = u.Username
+	}
+	s = expandTilde(s)
+	r := strings.NewReplacer("%%", "%", "%d", home, "%h", h.HostName, "%n", h.Alias, "%p", strconv.Itoa(h.Port), "%r", h.User, "%u", localUser)
+	return r.Replace(s)
+}
+
+// Server for the alias including the jump hosts of ProxyJump.
+// Jump hosts may be aliases of the config as well
+func (c *SshConfig) ToServer(alias string) Server {
+	return c.toServer(c.Get(alias), 0)
+}
+
+func (c *SshConfig) toServer(h SshConfigHost, depth int) Server {
+	s := Server{
+		Name:        h.Alias,
+		Host:        h.HostName,
+		Port:        h.Port,
+		User:        h.User,
+		ConfigAlias: h.Alias,
+	}
+	for _, item := range h.IdentityFiles {
+		_, err := os.Stat(item)
+		if err == nil {
+			s.KeyFile = item
+			break
+		}
+	}
+	for _, item := range h.CertFiles {
+		_, err := os.Stat(item)
+		if err == nil {
+			s.CertFile = item
+			break
+		}
+	}
+	if s.KeyFile == "" {
+		// no key file - keys are most likely in the agent
+		s.UseAgent = len(h.IdentityFiles) == 0 && IsAgentAvailable()
+	}
+	if depth >= SSH_CONFIG_MAX_JUMP_DEPTH {
+		return s
+	}
+	for i, item := range h.ProxyJump {
+		jump := c.parseJump(item)
+		hop := c.toServer(jump, depth+1)
+		// like ssh only the first jump host is reached with its own ProxyJump
+		if i == 0 {
+			s.JumpHosts = append(s.JumpHosts, hop.JumpHosts...)
+		}
+		hop.JumpHosts = nil
+		hop.ConfigAlias = ""
+		s.JumpHosts = append(s.JumpHosts, hop)
+	}
+	return s
+}
+
+// [user@]host[:port] - host may be an alias
+func (c *SshConfig) parseJump(item string) SshConfigHost {
+	item = strings.TrimPrefix(item, "ssh://")
+	jumpUser := ""
+	if n := strings.LastIndex(item, "@"); n >= 0 {
+		jumpUser = item[:n]
+		item = item[n+1:]
+	}
+	jumpPort := 0
+	host, port, err := net.SplitHostPort(item)
+	if err == nil {
+		item = host
+		jumpPort, _ = strconv.Atoi(port)
+	}
+	h := c.Get(item)
+	if jumpUser != "" {
+		h.User = jumpUser
+	}
+	if jumpPort != 0 {
+		h.Port = jumpPort
+	}
+	return h
+}
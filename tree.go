@@ -131,7 +131,11 @@ func createCanvasObject(branche bool) fyne.CanvasObject {
 	icon.FillMode = canvas.ImageFillContain
 	icon.Refresh()
 
-	return container.NewHBox(text, layout.NewSpacer(), icon, util.NewFiller(24, 0))
+	// guest address of a running VM
+	secondary := newTreeSecondaryLabel()
+	secondary.Hide()
+
+	return container.NewHBox(text, secondary, layout.NewSpacer(), icon, util.NewFiller(24, 0))
 }
 
 // update
@@ -145,10 +149,16 @@ func treeUpdateItem(id widget.TreeNodeID, branch bool, o fyne.CanvasObject) {
 		return
 	}
 
-	icon, ok := c.Objects[2].(*canvas.Image)
+	secondary, ok := c.Objects[1].(*CopyLabel)
+	if !ok {
+		return
+	}
+
+	icon, ok := c.Objects[3].(*canvas.Image)
 	if !ok {
 		return
 	}
+	secondaryText := ""
 
 	var tStyle fyne.TextStyle
 	var tColor color.Color
@@ -172,9 +182,12 @@ func treeUpdateItem(id widget.TreeNodeID, branch bool, o fyne.CanvasObject) {
 		s := Data.GetServer(sid, false)
 		text.Text = s.Name
 		text.Refresh()
-		if s.IsConnected() {
+		switch s.GetConnState() {
+		case vm.ConnState_connected:
 			icon.Resource = Gui.IconOk
-		} else {
+		case vm.ConnState_degraded, vm.ConnState_reconnecting:
+			icon.Resource = Gui.IconDegraded
+		default:
 			icon.Resource = Gui.IconError
 		}
 		icon.SetMinSize(fyne.NewSize(16, 16))
@@ -193,12 +206,13 @@ func treeUpdateItem(id widget.TreeNodeID, branch bool, o fyne.CanvasObject) {
 		} else {
 			text.Text = vma.Name
 			text.Refresh()
+			secondaryText = guestInfoTreeText(vma)
 			state, err := vma.GetState()
 			if err != nil {
 				icon.Resource = Gui.IconUnknown
 				Data.Lock.RUnlock()
 				if vma.Name != "<inaccessible>" {
-					go treeUpdateVmStatus(sid, vmid, true)
+					go treeUpdateVmStatusEx(sid, vmid, true, true)
 				}
 			} else {
 				switch state {
@@ -225,9 +239,20 @@ func treeUpdateItem(id widget.TreeNodeID, branch bool, o fyne.CanvasObject) {
 		icon.FillMode = canvas.ImageFillContain
 		icon.Refresh()
 	}
+	if secondaryText != "" {
+		secondary.SetText(secondaryText)
+		secondary.Show()
+	} else {
+		secondary.Hide()
+	}
 }
 
 func treeUpdateVmStatus(serverUuid, vmUuid string, lock bool) {
+	treeUpdateVmStatusEx(serverUuid, vmUuid, lock, false)
+}
+
+// background updates wait behind user actions for a free SSH session
+func treeUpdateVmStatusEx(serverUuid, vmUuid string, lock bool, background bool) {
 	if lock {
 		Data.Lock.RLock()
 		defer Data.Lock.RUnlock()
@@ -241,18 +266,53 @@ func treeUpdateVmStatus(serverUuid, vmUuid string, lock bool) {
 		return
 	}
 
-	err := v.UpdateStatus(&s.Client, VMStatusUpdateCallBack)
+	client := &s.Client
+	if background {
+		client = s.BackgroundClient()
+	}
+	err := v.UpdateStatus(client, VMStatusUpdateCallBack)
 	if err != nil {
 		return
 	}
 }
 
+// One listing for the states of all VMs - full updates only for the
+// selected VM and VMs with a changed state
 func treeUpdateAllVms(s *vm.VmServer, delay int) {
+	states, err := vm.GetVMStates(s.BackgroundClient())
+	if err != nil {
+		treeUpdateAllVmsSingle(s, delay)
+		return
+	}
+	update := make([]*vm.VMachine, 0, len(states))
+	for _, vma := range Data.GetVms(s.UUID, true) {
+		state, ok := states[vma.UUID]
+		if !ok {
+			// inaccessible or removed - the VM list update handles it
+			continue
+		}
+		changed := vma.ApplyListState(state)
+		if changed || (s.UUID == Gui.ActiveItemServer && vma.UUID == Gui.ActiveItemVm) {
+			update = append(update, vma)
+		}
+	}
+	// the addresses are shown with the status of this poll
+	updateGuestInfos(s)
+	for _, vma := range update {
+		treeUpdateVmStatusEx(s.UUID, vma.UUID, false, true)
+	}
+	if delay > 0 {
+		time.Sleep(time.Duration(delay) * time.Millisecond)
+	}
+}
+
+// one showvminfo per VM - fallback if the listing fails
+func treeUpdateAllVmsSingle(s *vm.VmServer, delay int) {
 	vms := Data.GetVms(s.UUID, true)
 	if len(vms) > 0 {
 		delay /= len(vms)
 		for _, vma := range vms {
-			treeUpdateVmStatus(s.UUID, vma.UUID, false)
+			treeUpdateVmStatusEx(s.UUID, vma.UUID, false, true)
 			if delay > 0 {
 				time.Sleep(time.Duration(delay) * time.Millisecond)
 			}
@@ -262,6 +262,7 @@ func loadIconsForTheme() {
 	Gui.IconUnknown = loadIcon("assets/icons/"+dir+"/unknown.png", "icon_unknown")
 	Gui.IconOk = loadIcon("assets/icons/"+dir+"/ok.png", "icon_ok")
 	Gui.IconError = loadIcon("assets/icons/"+dir+"/error.png", "icon_error")
+	Gui.IconDegraded = theme.NewWarningThemedResource(theme.WarningIcon())
 
 	Gui.IconFloppy = loadIcon("assets/icons/"+dir+"/floppy.png", "icon_floppy")
 	Gui.IconIde = loadIcon("assets/icons/"+dir+"/ide.png", "icon_ide")
@@ -331,8 +332,11 @@ func CheckMasterKey() bool {
 }
 
 func LoadData() {
+	vm.SetConnStateCallBack(ConnStateCallBack)
+	initAuditLog()
 	servers, _ := loadServers(Gui.MasterPassword)
 	Data.LoadData(servers)
+	Gui.VmGuestConsoleTab.LoadSettings()
 	Gui.Tree.Refresh()
 	vms := Data.GetServers(true)
 	if len(vms) > 0 {
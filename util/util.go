@@ -124,14 +124,15 @@ func GetDefaultTextSize(s string) fyne.Size {
 }
 
 func SelectEntryFromProperty(w *widget.Select, v *vm.VMachine, key string, m map[string]int, oldValue *int) {
+	SelectEntryFromValue(w, v.Properties[key], m, oldValue)
+}
+
+func SelectEntryFromValue(w *widget.Select, str string, m map[string]int, oldValue *int) {
 	w.ClearSelected()
-	str, ok := v.Properties[key]
 	index := -1
+	val, ok := m[strings.ToLower(str)]
 	if ok {
-		val, ok := m[strings.ToLower(str)]
-		if ok {
-			index = val
-		}
+		index = val
 	}
 	if index >= 0 {
 		w.SetSelectedIndex(index)
@@ -455,6 +455,50 @@ func argTranslate(value any) (string, error) {
 		default:
 			return "", errors.New("wrong Start in Window type")
 		}
+	case NatProtocolType:
+		switch v {
+		case NatProtocol_tcp:
+			strVal = "tcp"
+		case NatProtocol_udp:
+			strVal = "udp"
+		default:
+			return "", errors.New("wrong NAT protocol type")
+		}
+	case BandwidthGroupType:
+		switch v {
+		case BandwidthGroup_disk:
+			strVal = "disk"
+		case BandwidthGroup_network:
+			strVal = "network"
+		default:
+			return "", errors.New("wrong bandwidth group type")
+		}
+	case GuestPropertyFlagType:
+		switch v {
+		case GuestPropertyFlag_transient:
+			strVal = "TRANSIENT"
+		case GuestPropertyFlag_transreset:
+			strVal = "TRANSRESET"
+		case GuestPropertyFlag_rdonlyguest:
+			strVal = "RDONLYGUEST"
+		case GuestPropertyFlag_rdonlyhost:
+			strVal = "RDONLYHOST"
+		case GuestPropertyFlag_readonly:
+			strVal = "READONLY"
+		default:
+			return "", errors.New("wrong guest property flag type")
+		}
+	case PlatformArchType:
+		switch v {
+		case PlatformArch_default:
+			strVal = ""
+		case PlatformArch_x86:
+			strVal = "x86"
+		case PlatformArch_arm:
+			strVal = "arm"
+		default:
+			return "", errors.New("wrong Platform architecture type")
+		}
 	default:
 		return "", errors.New("wrong value type")
 	}
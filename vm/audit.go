@@ -0,0 +1,305 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package vm
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	AUDIT_FILE_NAME      = "audit.jsonl"
+	AUDIT_MAX_FILE_SIZE  = 5 * 1024 * 1024
+	AUDIT_MAX_FILES      = 5
+	AUDIT_MAX_OUTPUT     = 4096
+	AUDIT_REDACTED_VALUE = "***"
+)
+
+var (
+	regexAuditUuid   = regexp.MustCompile(`^\{?[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\}?$`)
+	regexAuditSecret = regexp.MustCompile(`(?i)^-+[a-z0-9-]*(password|passwd|passphrase|secret|token|credential|settingspw)`)
+	regexAuditFile   = regexp.MustCompile(`(?i)^-+[a-z-]*file(=|$)`)
+
+	// commands which name a VM - the first UUID is the VM
+	auditVmCommands = []string{"showvminfo", "modifyvm", "controlvm", "startvm", "unregistervm", "snapshot",
+		"clonevm", "storageattach", "storagectl", "sharedfolder", "setextradata", "getextradata", "usbfilter",
+		"guestproperty", "guestcontrol", "bandwidthctl", "modifynvram", "export", "discardstate", "adoptstate"}
+)
+
+// One VBoxManage call
+type AuditEntry struct {
+	Time time.Time `json:"time"`
+	// user@host:port or "local"
+	Server string   `json:"server"`
+	VmUuid string   `json:"vm,omitempty"`
+	Cmd    string   `json:"cmd"`
+	Args   []string `json:"args"`
+	// ms
+	Duration   int64  `json:"duration"`
+	Exit       int    `json:"exit"`
+	Error      string `json:"error,omitempty"`
+	Output     string `json:"output,omitempty"`
+	Background bool   `json:"background,omitempty"`
+}
+
+// Append only JSON lines file - rotated to .1 ... .AUDIT_MAX_FILES
+type auditLog struct {
+	lock sync.Mutex
+	dir  string
+}
+
+var audit auditLog
+
+// Enables the audit log - "" disables it
+func SetAuditLogDir(dir string) {
+	audit.lock.Lock()
+	defer audit.lock.Unlock()
+	audit.dir = dir
+}
+
+func auditFile(dir string, n int) string {
+	file := filepath.Join(dir, AUDIT_FILE_NAME)
+	if n > 0 {
+		file += fmt.Sprintf(".%d", n)
+	}
+	return file
+}
+
+// Replaces the values of password like options
+func RedactArgs(args []string) []string {
+	list, _ := redactArgs(args)
+	return list
+}
+
+// also returns the replaced values
+func redactArgs(args []string) ([]string, []string) {
+	list := make([]string, len(args))
+	var secrets []string
+	redactNext := false
+	for n, arg := range args {
+		switch {
+		case redactNext:
+			list[n] = AUDIT_REDACTED_VALUE
+			secrets = append(secrets, arg)
+			redactNext = false
+		// --passwordfile & co name a file - e.g. stdin
+		case strings.HasPrefix(arg, "-") && regexAuditSecret.MatchString(arg) && !regexAuditFile.MatchString(arg):
+			if key, value, ok := strings.Cut(arg, "="); ok {
+				list[n] = key + "=" + AUDIT_REDACTED_VALUE
+				secrets = append(secrets, value)
+			} else {
+				list[n] = arg
+				redactNext = true
+			}
+		default:
+			list[n] = arg
+		}
+	}
+	return list, secrets
+}
+
+// Replaces the secret values in an output - e.g. an echoed password
+func redactSecrets(str string, secrets []string) string {
+	for _, secret := range secrets {
+		if secret != "" && secret != AUDIT_REDACTED_VALUE {
+			str = strings.ReplaceAll(str, secret, AUDIT_REDACTED_VALUE)
+		}
+	}
+	return str
+}
+
+func redactLines(lines []string, secrets []string) []string {
+	if len(secrets) == 0 || lines == nil {
+		return lines
+	}
+	list := make([]string, len(lines))
+	for n, line := range lines {
+		list[n] = redactSecrets(line, secrets)
+	}
+	return list
+}
+
+func auditVmUuid(args []string) string {
+	if len(args) < 2 || !slices.Contains(auditVmCommands, args[0]) {
+		return ""
+	}
+	for _, arg := range args[1:] {
+		if regexAuditUuid.MatchString(arg) {
+			return strings.Trim(arg, "{}")
+		}
+	}
+	return ""
+}
+
+func truncateOutput(lines []string) string {
+	str := strings.TrimSpace(strings.Join(lines, "\n"))
+	if len(str) > AUDIT_MAX_OUTPUT {
+		// not within an UTF-8 sequence
+		cut := AUDIT_MAX_OUTPUT
+		for cut > 0 && !utf8.RuneStart(str[cut]) {
+			cut--
+		}
+		str = str[:cut] + " ..."
+	}
+	return str
+}
+
+func auditCmd(client *VmSshClient, cmd string, args []string, start time.Time, lines []string, err error) {
+	audit.lock.Lock()
+	dir := audit.dir
+	audit.lock.Unlock()
+	if dir == "" {
+		return
+	}
+	redacted, secrets := redactArgs(args)
+	secrets = append(secrets, client.secret)
+	entry := AuditEntry{
+		Time:       start,
+		Server:     client.address,
+		VmUuid:     auditVmUuid(args),
+		Cmd:        cmd,
+		Args:       redacted,
+		Duration:   time.Since(start).Milliseconds(),
+		Exit:       exitCode(err),
+		Background: client.Background,
+	}
+	if client.IsLocal {
+		entry.Server = "local"
+	}
+	if err != nil {
+		entry.Error = redactSecrets(err.Error(), secrets)
+	}
+	// the output of polling is only of interest if it failed
+	if err != nil || !client.Background {
+		entry.Output = truncateOutput(redactLines(lines, secrets))
+	}
+	audit.write(dir, &entry)
+}
+
+// errors are ignored - the command has already run
+func (a *auditLog) write(dir string, entry *AuditEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if os.MkdirAll(dir, 0o700) != nil {
+		return
+	}
+	file := auditFile(dir, 0)
+	if info, err := os.Stat(file); err == nil && info.Size()+int64(len(data)) > AUDIT_MAX_FILE_SIZE {
+		rotate(dir)
+	}
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.Write(data)
+}
+
+func rotate(dir string) {
+	os.Remove(auditFile(dir, AUDIT_MAX_FILES))
+	for n := AUDIT_MAX_FILES - 1; n >= 0; n-- {
+		os.Rename(auditFile(dir, n), auditFile(dir, n+1))
+	}
+}
+
+// Reads all entries (oldest first) for which filter returns true - nil
+// filter returns all
+func ReadAuditLog(filter func(*AuditEntry) bool) ([]AuditEntry, error) {
+	// the writers must not wait for the reading
+	audit.lock.Lock()
+	dir := audit.dir
+	audit.lock.Unlock()
+	if dir == "" {
+		return nil, errors.New("no audit log")
+	}
+	files := make([]string, 0, AUDIT_MAX_FILES+1)
+	for n := AUDIT_MAX_FILES; n >= 0; n-- {
+		files = append(files, auditFile(dir, n))
+	}
+	list := make([]AuditEntry, 0, 1024)
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var entry AuditEntry
+			// a broken line (crash while writing) is skipped
+			if json.Unmarshal(scanner.Bytes(), &entry) != nil {
+				continue
+			}
+			if filter == nil || filter(&entry) {
+				list = append(list, entry)
+			}
+		}
+		f.Close()
+	}
+	return list, nil
+}
+
+// The value of AuditEntry.Server for this server
+func (v *VmServer) AuditAddress() string {
+	if v.IsLocal() {
+		return "local"
+	}
+	return fmt.Sprintf("%s@%s:%d", v.User, v.Host, v.Port)
+}
+
+// parse reports are in the audit log as errors of the command
+func auditParseReport(r *ParseReport, args []string) {
+	audit.lock.Lock()
+	dir := audit.dir
+	audit.lock.Unlock()
+	if dir == "" {
+		return
+	}
+	entry := AuditEntry{
+		Time:   r.Time,
+		Server: r.Server,
+		VmUuid: auditVmUuid(args),
+		Cmd:    VBOXMANAGE_APP,
+		Args:   RedactArgs(args),
+		Error:  "unparsed output",
+		Output: truncateOutput([]string{r.String()}),
+	}
+	audit.write(dir, &entry)
+}
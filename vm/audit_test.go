@@ -0,0 +1,65 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package vm
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateOutputUtf8(t *testing.T) {
+	// "ä" needs two bytes - the cut is in the middle of one
+	str := truncateOutput([]string{"x" + strings.Repeat("ä", AUDIT_MAX_OUTPUT)})
+	if !utf8.ValidString(str) {
+		t.Errorf("invalid UTF-8 after truncation: %q", str[len(str)-8:])
+	}
+	if !strings.HasSuffix(str, " ...") || len(str) > AUDIT_MAX_OUTPUT+4 {
+		t.Errorf("unexpected truncation: len %d", len(str))
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	tests := []struct {
+		args, redacted string
+	}{
+		{"modifyvm x --description my-password-is-long", "modifyvm x --description my-password-is-long"},
+		{"storageattach x --passthrough on", "storageattach x --passthrough on"},
+		{"unattended install x --password s3cret", "unattended install x --password ***"},
+		{"unattended install x --password=s3cret", "unattended install x --password=***"},
+		{"guestcontrol x run --passwordfile stdin", "guestcontrol x run --passwordfile stdin"},
+		{"import x.ova --settingspw s3cret", "import x.ova --settingspw ***"},
+		{"cloud --api-token s3cret", "cloud --api-token ***"},
+	}
+	for _, test := range tests {
+		list, secrets := redactArgs(strings.Fields(test.args))
+		if got := strings.Join(list, " "); got != test.redacted {
+			t.Errorf("redactArgs(%q) = %q, want %q", test.args, got, test.redacted)
+		}
+		if strings.Contains(test.args, "s3cret") && (len(secrets) != 1 || secrets[0] != "s3cret") {
+			t.Errorf("redactArgs(%q) secrets = %q", test.args, secrets)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+// 7.0.14r161095, 6.1.50_Ubuntur161033, 7.1.0_BETA2r164698
+var regexFullVersion = regexp.MustCompile(`^([0-9]+)\.([0-9]+)(?:\.([0-9]+))?(.*?)(?:r([0-9]+))?$`)
+
+type CapabilityType int
+
+const (
+	Capability_dashedOptions         CapabilityType = iota // --usb-ohci instead of --usbohci
+	Capability_deleteAll                                   // unregistervm --delete-all
+	Capability_globalSharedFolder                          // sharedfolder add global
+	Capability_audioEnabled                                // --audio-enabled
+	Capability_nestedHwVirt                                // --nested-hw-virt
+	Capability_cloudNetwork                                // --nic<n>=cloud
+	Capability_tpm                                         // --tpm-type
+	Capability_secureBoot                                  // modifynvram
+	Capability_platformArch                                // --platform-architecture
+	Capability_guestPropertyPatterns                       // guestproperty enumerate <vm> <pattern>...
+	Capability_guestControlCwd                             // guestcontrol run --cwd
+)
+
+// first version supporting the capability
+var capabilityTable = map[CapabilityType]VmVersion{
+	Capability_dashedOptions:         {Major: 7},
+	Capability_deleteAll:             {Major: 7},
+	Capability_globalSharedFolder:    {Major: 7},
+	Capability_audioEnabled:          {Major: 7},
+	Capability_nestedHwVirt:          {Major: 6},
+	Capability_cloudNetwork:          {Major: 6, Minor: 1},
+	Capability_tpm:                   {Major: 7},
+	Capability_secureBoot:            {Major: 7},
+	Capability_platformArch:          {Major: 7, Minor: 1},
+	Capability_guestPropertyPatterns: {Major: 7},
+	Capability_guestControlCwd:       {Major: 7, Minor: 1},
+}
+
+type VmVersion struct {
+	Major    int
+	Minor    int
+	Build    int
+	Suffix   string // _Ubuntu, _BETA2, ...
+	Revision int
+}
+
+func ParseVmVersion(version string) (VmVersion, error) {
+	items := regexFullVersion.FindStringSubmatch(version)
+	if items == nil {
+		return VmVersion{}, errors.New("unknown version")
+	}
+	var err error
+	v := VmVersion{Suffix: items[4]}
+	if v.Major, err = strconv.Atoi(items[1]); err != nil {
+		return VmVersion{}, errors.New("parse error")
+	}
+	if v.Minor, err = strconv.Atoi(items[2]); err != nil {
+		return VmVersion{}, errors.New("parse error")
+	}
+	if items[3] != "" {
+		v.Build, _ = strconv.Atoi(items[3])
+	}
+	if items[5] != "" {
+		v.Revision, _ = strconv.Atoi(items[5])
+	}
+	return v, nil
+}
+
+func (v VmVersion) Compare(o VmVersion) int {
+	switch {
+	case v.Major != o.Major:
+		return v.Major - o.Major
+	case v.Minor != o.Minor:
+		return v.Minor - o.Minor
+	default:
+		return v.Build - o.Build
+	}
+}
+
+func (v VmVersion) String() string {
+	if v.Build == 0 {
+		return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+	}
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Build)
+}
+
+type Capabilities struct {
+	Raw     string
+	Version VmVersion
+	Known   bool
+}
+
+func NewCapabilities(version string) *Capabilities {
+	c := Capabilities{Raw: version}
+	v, err := ParseVmVersion(version)
+	if err == nil {
+		c.Version = v
+		c.Known = true
+	}
+	return &c
+}
+
+// An unknown version is treated as the newest one
+func (c *Capabilities) Has(capability CapabilityType) bool {
+	if !c.Known {
+		return true
+	}
+	min, ok := capabilityTable[capability]
+	if !ok {
+		return true
+	}
+	return c.Version.Compare(min) >= 0
+}
+
+// first version supporting the capability
+func (c *Capabilities) Requires(capability CapabilityType) VmVersion {
+	return capabilityTable[capability]
+}
+
+func (c *Capabilities) unsupported(capability CapabilityType) error {
+	return fmt.Errorf("not supported by VirtualBox %s - requires %s or newer", c.Version.String(), c.Requires(capability).String())
+}
+
+// option name for modifyvm & co depending on the version
+func (c *Capabilities) option(dashed, legacy string) string {
+	if c.Has(Capability_dashedOptions) {
+		return dashed
+	}
+	return legacy
+}
+
+// shared by all copies of a server - the registry is read by polling
+// goroutines and the UI
+type capabilityCache struct {
+	lock         sync.Mutex
+	capabilities *Capabilities
+}
+
+// Capability registry of the server - never nil
+func (v *VmServer) Capabilities() *Capabilities {
+	if v.capabilities == nil {
+		return NewCapabilities(v.Version)
+	}
+	v.capabilities.lock.Lock()
+	defer v.capabilities.lock.Unlock()
+	c := v.capabilities.capabilities
+	if c == nil || c.Raw != v.Version {
+		c = NewCapabilities(v.Version)
+		v.capabilities.capabilities = c
+	}
+	return c
+}
+
+func (v *VmServer) HasCapability(capability CapabilityType) bool {
+	return v.Capabilities().Has(capability)
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package vm
+
+import (
+	"bytemystery-com/vboxssh/run"
+)
+
+type PrefixType int
+
+const (
+	Prefix_none PrefixType = iota
+	Prefix_sudo
+	Prefix_doas
+)
+
+// How VBoxManage is started on a server
+type CmdConfig struct {
+	VBoxManage string     `json:"vboxmanage"`
+	Prefix     PrefixType `json:"prefix"`
+	PrefixUser string     `json:"prefixuser"`
+	// KEY=value
+	Env []string `json:"env"`
+	// commands without progress output run in one long living shell
+	PersistentShell bool `json:"persistentshell"`
+}
+
+func (v *VmServer) SetCmdConfig(c CmdConfig) {
+	c.Env = append([]string(nil), c.Env...)
+	if c.PersistentShell != v.CmdConfig.PersistentShell {
+		// also resets a shell which failed
+		run.CloseShell(v.SshClient())
+	}
+	v.CmdConfig = c
+	if v.Client.config != nil {
+		*v.Client.config = c
+	}
+}
+
+// Average latency of commands in own sessions and in the persistent shell
+func (v *VmServer) GetLatencyStats() (run.LatencyStat, run.LatencyStat) {
+	return run.GetLatencyStats(v.SshClient())
+}
+
+// The shell command line which runs cmd with the config of the server.
+// The environment is always part of it, so it can be used in a script.
+func (v *VmServer) CommandLine(cmd string, args []string) string {
+	c := v.CmdConfig
+	cmd, args, _ = c.build(cmd, args, false)
+	return run.QuoteCmd(cmd, args)
+}
+
+// VBoxManage is translated since 7.0 and the parsers need the English
+// output - the configured environment may still override it
+var localeEnv = []string{"LC_ALL=C", "LANG=C", "LANGUAGE=C"}
+
+// Returns the command, the args and the environment for a local process.
+// Remote the environment is part of the command line because sudo / doas
+// reset it anyway: sudo gets VAR=value before the command, so a sudoers
+// rule which only allows VBoxManage still matches - the variables must be
+// allowed by env_check / env_keep or SETENV. doas has no such form and
+// runs env(1). The args are quoted later by the run package.
+func (c *CmdConfig) build(cmd string, args []string, local bool) (string, []string, []string) {
+	if c == nil {
+		c = &CmdConfig{}
+	}
+	if cmd == VBOXMANAGE_APP && c.VBoxManage != "" {
+		cmd = c.VBoxManage
+	}
+	env := make([]string, 0, len(localeEnv)+len(c.Env))
+	env = append(env, localeEnv...)
+	env = append(env, c.Env...)
+	list := make([]string, 0, len(args)+len(env)+6)
+	switch c.Prefix {
+	case Prefix_sudo:
+		list = append(list, "sudo", "-n")
+		if c.PrefixUser != "" {
+			list = append(list, "-u", c.PrefixUser)
+		}
+		// no "--" - sudo only takes VAR=value without it
+		list = append(list, env...)
+		list = append(list, cmd)
+		list = append(list, args...)
+		return list[0], list[1:], nil
+	case Prefix_doas:
+		list = append(list, "doas", "-n")
+		if c.PrefixUser != "" {
+			list = append(list, "-u", c.PrefixUser)
+		}
+	}
+	if local && c.Prefix == Prefix_none {
+		return cmd, args, env
+	}
+	list = append(list, "env")
+	list = append(list, env...)
+	list = append(list, cmd)
+	list = append(list, args...)
+	return list[0], list[1:], nil
+}
@@ -0,0 +1,520 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package vm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Typed view of showvminfo. A new one is built on every status update and
+// never changed afterwards - the GUI may keep it but must not modify it.
+// Keys which are not covered are still in VMachine.Properties.
+type VMConfig struct {
+	General       ConfigGeneral
+	System        ConfigSystem
+	Cpu           ConfigCpu
+	Display       ConfigDisplay
+	Storage       []ConfigStorageController
+	Nics          []ConfigNic
+	Usb           ConfigUsb
+	SharedFolders []ConfigSharedFolder
+	Snapshots     ConfigSnapshots
+	Vrde          ConfigVrde
+}
+
+type ConfigGeneral struct {
+	Name           string
+	UUID           string
+	OsType         string
+	Description    string
+	Groups         []string
+	CfgFile        string
+	SnapshotFolder string
+	LogFolder      string
+	State          string
+	// empty if no guest additions are running
+	GuestAdditionsVersion  string
+	GuestAdditionsRunLevel int
+}
+
+type ConfigSystem struct {
+	Chipset        string
+	Firmware       string
+	Tpm            string
+	Mouse          string
+	Keyboard       string
+	Acpi           bool
+	IoApic         bool
+	Hpet           bool
+	RtcUseUtc      bool
+	SecureBoot     bool
+	BiosTimeOffset int
+	// boot1 ... boot4 - "none", "floppy", "dvd", "disk" or "net"
+	BootOrder []string
+}
+
+type ConfigCpu struct {
+	Cpus             int
+	ExecutionCap     int
+	Memory           int
+	Pae              bool
+	LongMode         bool
+	HwVirtEx         bool
+	NestedHwVirt     bool
+	NestedPaging     bool
+	X2Apic           bool
+	ParaVirtProvider string
+}
+
+type ConfigDisplay struct {
+	Vram         int
+	Monitors     int
+	Controller   string
+	Accelerate3D bool
+}
+
+type ConfigStorageController struct {
+	// index in showvminfo
+	Index        int
+	Name         string
+	Type         string
+	Instance     int
+	PortCount    int
+	MaxPortCount int
+	Bootable     bool
+	Attachments  []ConfigAttachment
+}
+
+type ConfigAttachment struct {
+	Port   int
+	Device int
+	// path of the image, host drive or "emptydrive"
+	Medium        string
+	ImageUUID     string
+	NonRotational bool
+	HotPluggable  bool
+	Discard       bool
+	TempEject     bool
+}
+
+func (a *ConfigAttachment) IsEmpty() bool {
+	return a.Medium == "emptydrive"
+}
+
+type ConfigNic struct {
+	// 1 based as in VBoxManage
+	Index int
+	// "none", "null", "nat", "bridged", "intnet", "hostonly", "natnetwork", "generic", ...
+	Attachment string
+	// bridged / host-only adapter, internal network, NAT network or generic driver
+	Name           string
+	Type           string
+	Mac            string
+	CableConnected bool
+	Promiscuous    string
+	// port forwarding of a NAT adapter
+	NatRules []NatRule
+	// empty if none
+	BandwidthGroup string
+}
+
+type ConfigUsb struct {
+	Ohci    bool
+	Ehci    bool
+	Xhci    bool
+	Filters []ConfigUsbFilter
+	// UUIDs of the attached host devices
+	Attached []string
+}
+
+// The highest enabled controller
+func (u *ConfigUsb) Type() UsbType {
+	switch {
+	case u.Xhci:
+		return Usb_3
+	case u.Ehci:
+		return Usb_2
+	case u.Ohci:
+		return Usb_1
+	}
+	return Usb_none
+}
+
+type ConfigUsbFilter struct {
+	Active       bool
+	Name         string
+	VendorId     string
+	ProductId    string
+	Revision     string
+	Manufacturer string
+	Product      string
+	SerialNumber string
+	Remote       string
+}
+
+type ConfigSharedFolder struct {
+	Name       string
+	HostPath   string
+	MountPoint string
+	Global     bool
+	ReadOnly   bool
+	AutoMount  bool
+}
+
+type ConfigSnapshots struct {
+	// nil if the VM has no snapshots
+	Root        *ConfigSnapshot
+	CurrentName string
+	CurrentUUID string
+}
+
+type ConfigSnapshot struct {
+	Name        string
+	UUID        string
+	Description string
+	// suffix of the property keys - "-1-2" for SnapshotName-1-2
+	Node     string
+	Children []*ConfigSnapshot
+}
+
+type ConfigVrde struct {
+	Enabled bool
+	// configured ports - "3389" or "5000-5050"
+	Ports string
+	// port in use, -1 if not running
+	Port     int
+	Address  string
+	AuthType string
+	Security string
+	MultiCon bool
+	ReuseCon bool
+}
+
+var emptyConfig = VMConfig{}
+
+// Config of the last status update - never nil
+func (m *VMachine) Config() *VMConfig {
+	c := m.config.Load()
+	if c == nil {
+		return &emptyConfig
+	}
+	return c
+}
+
+func (m *VMachine) updateConfig() {
+	m.config.Store(parseVMConfig(m.Properties))
+}
+
+type propertyReader map[string]string
+
+// first of the keys found - the names differ between the versions
+func (p propertyReader) str(keys ...string) string {
+	for _, key := range keys {
+		if val, ok := p[key]; ok {
+			return val
+		}
+	}
+	return ""
+}
+
+func (p propertyReader) has(key string) bool {
+	_, ok := p[key]
+	return ok
+}
+
+func (p propertyReader) num(keys ...string) int {
+	val, _ := strconv.Atoi(p.str(keys...))
+	return val
+}
+
+func (p propertyReader) on(keys ...string) bool {
+	switch strings.ToLower(p.str(keys...)) {
+	case "on", "true", "enabled":
+		return true
+	}
+	return false
+}
+
+func parseVMConfig(props map[string]string) *VMConfig {
+	p := propertyReader(props)
+	c := VMConfig{}
+
+	c.General = ConfigGeneral{
+		Name:                   p.str("name"),
+		UUID:                   p.str("UUID"),
+		OsType:                 p.str("ostype"),
+		Description:            p.str("description"),
+		CfgFile:                p.str("CfgFile"),
+		SnapshotFolder:         p.str("SnapFldr"),
+		LogFolder:              p.str("LogFldr"),
+		State:                  p.str(VM_PROP_KEY_STATE),
+		GuestAdditionsVersion:  p.str("GuestAdditionsVersion"),
+		GuestAdditionsRunLevel: p.num("GuestAdditionsRunLevel"),
+	}
+	if groups := p.str("groups"); groups != "" {
+		c.General.Groups = strings.Split(groups, ",")
+	}
+
+	c.System = ConfigSystem{
+		Chipset:        p.str("chipset"),
+		Firmware:       p.str("firmware"),
+		Tpm:            p.str("tpm_type", "tpm-type"),
+		Mouse:          p.str("hidpointing"),
+		Keyboard:       p.str("hidkeyboard"),
+		Acpi:           p.on("acpi"),
+		IoApic:         p.on("ioapic"),
+		Hpet:           p.on("hpet"),
+		RtcUseUtc:      p.on("rtcuseutc"),
+		SecureBoot:     p.on("SecureBoot"),
+		BiosTimeOffset: p.num("biossystemtimeoffset"),
+	}
+	for i := 1; p.has(fmt.Sprintf("boot%d", i)); i++ {
+		c.System.BootOrder = append(c.System.BootOrder, p.str(fmt.Sprintf("boot%d", i)))
+	}
+
+	c.Cpu = ConfigCpu{
+		Cpus:             p.num("cpus"),
+		ExecutionCap:     p.num("cpuexecutioncap"),
+		Memory:           p.num("memory"),
+		Pae:              p.on("pae"),
+		LongMode:         p.on("longmode"),
+		HwVirtEx:         p.on("hwvirtex"),
+		NestedHwVirt:     p.on("nested-hw-virt"),
+		NestedPaging:     p.on("nestedpaging", "nested-paging"),
+		X2Apic:           p.on("x2apic"),
+		ParaVirtProvider: p.str("paravirtprovider"),
+	}
+
+	c.Display = ConfigDisplay{
+		Vram:         p.num("vram"),
+		Monitors:     p.num("monitorcount"),
+		Controller:   p.str("graphicscontroller"),
+		Accelerate3D: p.on("accelerate3d"),
+	}
+
+	c.Storage = parseStorageConfig(p)
+	c.Nics = parseNicConfig(p)
+	c.Usb = parseUsbConfig(p)
+	c.SharedFolders = parseSharedFolderConfig(p)
+	c.Snapshots = parseSnapshotConfig(p)
+
+	c.Vrde = ConfigVrde{
+		Enabled:  p.on("vrde"),
+		Ports:    p.str("vrdeports"),
+		Port:     -1,
+		Address:  p.str("vrdeaddress"),
+		AuthType: p.str("vrdeauthtype"),
+		Security: p.str("vrdeproperty[Security/Method]"),
+		MultiCon: p.on("vrdemulticon"),
+		ReuseCon: p.on("vrdereusecon"),
+	}
+	if port, err := strconv.Atoi(p.str("vrdeport")); err == nil {
+		c.Vrde.Port = port
+	}
+	return &c
+}
+
+func parseStorageConfig(p propertyReader) []ConfigStorageController {
+	var list []ConfigStorageController
+	for index := 0; p.has(fmt.Sprintf("storagecontrollername%d", index)); index++ {
+		ctrl := ConfigStorageController{
+			Index:        index,
+			Name:         p.str(fmt.Sprintf("storagecontrollername%d", index)),
+			Type:         p.str(fmt.Sprintf("storagecontrollertype%d", index)),
+			Instance:     p.num(fmt.Sprintf("storagecontrollerinstance%d", index)),
+			PortCount:    p.num(fmt.Sprintf("storagecontrollerportcount%d", index)),
+			MaxPortCount: p.num(fmt.Sprintf("storagecontrollermaxportcount%d", index)),
+			Bootable:     p.on(fmt.Sprintf("storagecontrollerbootable%d", index)),
+		}
+		// the keys of the attachments are quoted - the name may contain blanks
+		for port := range ctrl.PortCount {
+			// only IDE has two devices per port
+			for device := range 2 {
+				medium, ok := p[fmt.Sprintf("\"%s-%d-%d\"", ctrl.Name, port, device)]
+				if !ok || medium == "none" {
+					continue
+				}
+				ctrl.Attachments = append(ctrl.Attachments, ConfigAttachment{
+					Port:          port,
+					Device:        device,
+					Medium:        medium,
+					ImageUUID:     p.str(fmt.Sprintf("\"%s-ImageUUID-%d-%d\"", ctrl.Name, port, device)),
+					NonRotational: p.on(fmt.Sprintf("\"%s-nonrotational-%d-%d\"", ctrl.Name, port, device)),
+					HotPluggable:  p.on(fmt.Sprintf("\"%s-hot-pluggable-%d-%d\"", ctrl.Name, port, device)),
+					Discard:       p.on(fmt.Sprintf("\"%s-discard-%d-%d\"", ctrl.Name, port, device)),
+					TempEject:     p.on(fmt.Sprintf("\"%s-tempeject-%d-%d\"", ctrl.Name, port, device)),
+				})
+			}
+		}
+		list = append(list, ctrl)
+	}
+	return list
+}
+
+// The machine readable keys win - the nicN_xxx keys of the human readable
+// showvminfo (UpdateStatusEx) are only used if they are missing.
+func parseNicConfig(p propertyReader) []ConfigNic {
+	var list []ConfigNic
+	for index := 1; p.has(fmt.Sprintf("nic%d", index)); index++ {
+		nic := ConfigNic{
+			Index:          index,
+			Attachment:     p.str(fmt.Sprintf("nic%d", index)),
+			Type:           p.str(fmt.Sprintf("nictype%d", index)),
+			Mac:            p.str(fmt.Sprintf("macaddress%d", index)),
+			CableConnected: p.on(fmt.Sprintf("cableconnected%d", index)),
+			Promiscuous:    p.str(fmt.Sprintf("nicpromisc%d", index)),
+			Name: p.str(fmt.Sprintf("bridgeadapter%d", index), fmt.Sprintf("hostonlyadapter%d", index),
+				fmt.Sprintf("intnet%d", index), fmt.Sprintf("nat-network%d", index),
+				fmt.Sprintf("hostonly-network%d", index), fmt.Sprintf("generic%d", index)),
+			NatRules: parseNatRules(p, fmt.Sprintf("natpf%d", index)),
+		}
+		if group := p.str(fmt.Sprintf("nic%d_bandwidthgroup", index)); group != "none" {
+			nic.BandwidthGroup = group
+		}
+		list = append(list, nic)
+	}
+	// Forwarding(n) is counted per adapter - without the natpf<nic>(n) keys
+	// the rules belong to the first NAT adapter
+	for i := range list {
+		if list[i].Attachment == "nat" {
+			if len(list[i].NatRules) == 0 && !p.has(fmt.Sprintf("natpf%d(0)", list[i].Index)) {
+				list[i].NatRules = parseNatRules(p, "Forwarding")
+			}
+			break
+		}
+	}
+	return list
+}
+
+// Forwarding(0)="ssh,tcp,,2222,,22"
+func parseNatRules(p propertyReader, prefix string) []NatRule {
+	var list []NatRule
+	for index := 0; p.has(fmt.Sprintf("%s(%d)", prefix, index)); index++ {
+		rule, err := ParseNatRule(p.str(fmt.Sprintf("%s(%d)", prefix, index)))
+		if err == nil {
+			list = append(list, rule)
+		}
+	}
+	return list
+}
+
+func parseUsbConfig(p propertyReader) ConfigUsb {
+	u := ConfigUsb{
+		Ohci: p.on("usbohci", "usb"),
+		Ehci: p.on("usbehci", "ehci"),
+		Xhci: p.on("usbxhci", "xhci"),
+	}
+	for index := 1; p.has(fmt.Sprintf("USBFilterActive%d", index)); index++ {
+		u.Filters = append(u.Filters, ConfigUsbFilter{
+			Active:       p.on(fmt.Sprintf("USBFilterActive%d", index)),
+			Name:         p.str(fmt.Sprintf("USBFilterName%d", index)),
+			VendorId:     p.str(fmt.Sprintf("USBFilterVendorId%d", index)),
+			ProductId:    p.str(fmt.Sprintf("USBFilterProductId%d", index)),
+			Revision:     p.str(fmt.Sprintf("USBFilterRevision%d", index)),
+			Manufacturer: p.str(fmt.Sprintf("USBFilterManufacturer%d", index)),
+			Product:      p.str(fmt.Sprintf("USBFilterProduct%d", index)),
+			SerialNumber: p.str(fmt.Sprintf("USBFilterSerialNumber%d", index)),
+			Remote:       p.str(fmt.Sprintf("USBFilterRemote%d", index)),
+		})
+	}
+	for index := 1; p.has(fmt.Sprintf("USBAttachActive%d", index)); index++ {
+		u.Attached = append(u.Attached, p.str(fmt.Sprintf("USBAttachActive%d", index)))
+	}
+	return u
+}
+
+// Only the human readable showvminfo has all data of the shared folders -
+// the machine readable one is used if UpdateStatusEx did not run.
+// the flags come from the human readable showvminfo, see sharedFolderKey
+func parseSharedFolderConfig(p propertyReader) []ConfigSharedFolder {
+	var list []ConfigSharedFolder
+	for _, mapping := range []string{"Machine", "Global"} {
+		for index := 1; p.has(fmt.Sprintf("SharedFolderName%sMapping%d", mapping, index)); index++ {
+			name := p.str(fmt.Sprintf("SharedFolderName%sMapping%d", mapping, index))
+			list = append(list, ConfigSharedFolder{
+				Name:       name,
+				HostPath:   p.str(fmt.Sprintf("SharedFolderPath%sMapping%d", mapping, index)),
+				MountPoint: p.str(sharedFolderKey("MountPoint", mapping, name)),
+				Global:     mapping == "Global",
+				ReadOnly:   p.str(sharedFolderKey("ReadOnly", mapping, name)) == "true",
+				AutoMount:  p.str(sharedFolderKey("AutoMount", mapping, name)) == "true",
+			})
+		}
+	}
+	return list
+}
+
+// ssfReadOnlyMachineMapping:<name>
+func sharedFolderKey(flag string, mapping string, name string) string {
+	return fmt.Sprintf("ssf%s%sMapping:%s", flag, mapping, name)
+}
+
+// SnapshotName, SnapshotName-1, SnapshotName-1-1, SnapshotName-2, ...
+func parseSnapshotConfig(p propertyReader) ConfigSnapshots {
+	s := ConfigSnapshots{
+		CurrentName: p.str("CurrentSnapshotName"),
+		CurrentUUID: p.str("CurrentSnapshotUUID"),
+	}
+	if p.has("SnapshotName") {
+		s.Root = &ConfigSnapshot{
+			Name:        p.str("SnapshotName"),
+			UUID:        p.str("SnapshotUUID"),
+			Description: p.str("SnapshotDescription"),
+			Children:    parseSnapshotChildren(p, ""),
+		}
+	}
+	return s
+}
+
+func parseSnapshotChildren(p propertyReader, node string) []*ConfigSnapshot {
+	var list []*ConfigSnapshot
+	for index := 1; ; index++ {
+		child := fmt.Sprintf("%s-%d", node, index)
+		if !p.has("SnapshotName"+child) || !p.has("SnapshotUUID"+child) {
+			break
+		}
+		list = append(list, &ConfigSnapshot{
+			Name:        p.str("SnapshotName" + child),
+			UUID:        p.str("SnapshotUUID" + child),
+			Description: p.str("SnapshotDescription" + child),
+			Node:        child,
+			Children:    parseSnapshotChildren(p, child),
+		})
+	}
+	return list
+}
+
+// Calls f for the snapshot and all below it - depth first
+func (s *ConfigSnapshot) Walk(f func(snapshot *ConfigSnapshot)) {
+	if s == nil {
+		return
+	}
+	f(s)
+	for _, child := range s.Children {
+		child.Walk(f)
+	}
+}
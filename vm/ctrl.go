@@ -35,28 +35,16 @@ import (
 )
 
 var (
-	regexVMList             = regexp.MustCompile(`\"(.*)\"\s*{([0-9-a-fA-F]*)}`)
+	regexVMList = regexp.MustCompile(`\"(.*)\"\s*{([0-9-a-fA-F]*)}`)
+	// list --long vms
+	regexVMListUuid         = regexp.MustCompile(`^UUID:\s+([0-9a-fA-F-]{36})\s*$`)
+	regexVMListState        = regexp.MustCompile(`^State:\s+(.*?)\s+\(since\s+(.*)\)`)
 	regexVMInfoKeyValue     = regexp.MustCompile(`(.*)="(.*)"`)
 	regexVMInfoKeyValue2    = regexp.MustCompile(`(.*)=(.*)`)
 	regexVMInfoKeyValueDesc = regexp.MustCompile(`(.*)="(.*)`)
 	regexVMStart            = regexp.MustCompile(`successfully\s+started`)
 	regexVMSave             = regexp.MustCompile(`100%`)
 	regexVMPowerOff         = regexp.MustCompile(`100%`)
-	//(Driver: ALSA, Controller: HDA, Codec: STAC9221)
-	regexVMInfo2Audio1 = regexp.MustCompile(`^Audio:\s+(.*)`)
-	regexVMInfo2Audio2 = regexp.MustCompile(`\(Driver:\s+(.*), Controller:\s+(.*), Codec:\s+(.*)\).*`)
-
-	// NIC 1:                       MAC: 0800278ACCAC, Attachment: none, Cable connected: on, Trace: off (file: none), Type: 82583V, Reported speed: 0 Mbps, Boot priority: 0, Promisc Policy: allow-vms, Bandwidth group: none
-	// NIC 1:                       MAC: 0800278ACCAC, Attachment: Bridged Interface 'eno1', Cable connected: on, Trace: off (file: none), Type: 82583V, Reported speed: 0 Mbps, Boot priority: 0, Promisc Policy: allow-vms, Bandwidth group: none
-	regexVMInfo2Network1 = regexp.MustCompile(`^NIC ([0-9]+):\s*(.*)`)
-	regexVMInfo2Network2 = regexp.MustCompile(`MAC:\s*([0-9a-zA-Z]{12}),\s*Attachment:\s*(.*),\s*Cable connected:\s*(.*),\s*Trace:\s*(.*),\s*Type:\s*(.*),\s*Reported speed:\s*(.*),\s*Boot priority:\s*(.*),\s*Promisc Policy:\s*(.*),\s*Bandwidth group:\s*(.*)`)
-	// Internal Network 'intnet'
-	regexVMInfo2Network3 = regexp.MustCompile(`.*'(.*)'`)
-
-	// USB
-	regexVMInfo2USb1 = regexp.MustCompile(`^OHCI USB:\s+(.+)`)
-	regexVMInfo2USb2 = regexp.MustCompile(`^EHCI USB:\s+(.+)`)
-	regexVMInfo2USb3 = regexp.MustCompile(`^xHCI USB:\s+(.+)`)
 
 	// Shared folders
 	// Shared folders:              <none>
@@ -128,7 +116,8 @@ func (m *VMachine) runCmd(client *VmSshClient, cmd string, args []string, bUpdat
 	}
 	lines, err := RunCmd(client, cmd, args, nil, nil)
 
-	if err == nil && bUpdateStatus {
+	// a preview did not change anything
+	if err == nil && bUpdateStatus && !client.isPreview() {
 		go m.UpdateStatus(client, callBack)
 	}
 	if err != nil {
@@ -278,6 +267,10 @@ func (m *VMachine) updateStatusEx(client *VmSshClient) error {
 	if m.Properties == nil {
 		m.Properties = make(map[string]string, len(lines))
 	}
+	// only what the machine readable output does not have: the shared
+	// folder flags, keyed by mapping and name
+	c := parseCollector{}
+	foundSsf := false
 	inSsf := false
 	ssfCount := 0
 	for _, line := range lines {
@@ -291,99 +284,31 @@ func (m *VMachine) updateStatusEx(client *VmSshClient) error {
 				// Name: 'data', Host path: '/home/reiner/data' (machine mapping), readonly, mount-point: '/media/xxx'
 				items := regexVMInfo2SharedFolders1.FindStringSubmatch(line)
 				if len(items) == 7 {
-					m.Properties[fmt.Sprintf("ssfName%d", ssfCount+1)] = items[1]
-					m.Properties[fmt.Sprintf("ssfHostPath%d", ssfCount+1)] = items[2]
-					if strings.ToLower(items[3]) == "machine" {
-						m.Properties[fmt.Sprintf("ssfMapping%d", ssfCount+1)] = "machchine"
-					} else {
-						m.Properties[fmt.Sprintf("ssfMapping%d", ssfCount+1)] = "global"
+					mapping := "Machine"
+					if strings.ToLower(items[3]) == "global" {
+						mapping = "Global"
 					}
-					if strings.ToLower(items[4]) == "readonly" {
-						m.Properties[fmt.Sprintf("ssfReadOnly%d", ssfCount+1)] = "true"
-					} else {
-						m.Properties[fmt.Sprintf("ssfReadOnly%d", ssfCount+1)] = "false"
-					}
-					if strings.ToLower(items[5]) == "auto-mount" {
-						m.Properties[fmt.Sprintf("ssfAutoMount%d", ssfCount+1)] = "true"
-					} else {
-						m.Properties[fmt.Sprintf("ssfAutoMount%d", ssfCount+1)] = "false"
-					}
-					m.Properties[fmt.Sprintf("ssfMountPoint%d", ssfCount+1)] = items[6]
-
+					m.Properties[sharedFolderKey("ReadOnly", mapping, items[1])] = strconv.FormatBool(strings.ToLower(items[4]) == "readonly")
+					m.Properties[sharedFolderKey("AutoMount", mapping, items[1])] = strconv.FormatBool(strings.ToLower(items[5]) == "auto-mount")
+					m.Properties[sharedFolderKey("MountPoint", mapping, items[1])] = items[6]
 					ssfCount++
+				} else {
+					c.unparsed(line)
 				}
 			}
 			continue
 		}
-
-		items := regexVMInfo2Audio1.FindStringSubmatch(line)
-		if len(items) == 2 {
-			items = regexVMInfo2Audio2.FindStringSubmatch(items[1])
-			if len(items) == 4 {
-				m.Properties["audio_driver"] = strings.ToLower(items[1])
-				m.Properties["audio_controller"] = strings.ToLower(items[2])
-				m.Properties["audio_codec"] = strings.ToLower(items[3])
-			}
-			continue
-		}
-
-		// Network
-		items = regexVMInfo2Network1.FindStringSubmatch(line)
-		if len(items) == 3 {
-			index, err := strconv.Atoi(items[1])
-			if err == nil {
-				items = regexVMInfo2Network2.FindStringSubmatch(items[2])
-				if len(items) == 10 {
-					m.Properties[fmt.Sprintf("nic%d_mac", index)] = items[1]
-					items2 := regexVMInfo2Network3.FindStringSubmatch(items[2])
-					if len(items2) == 2 {
-						m.Properties[fmt.Sprintf("nic%d_name", index)] = items2[1]
-					}
-					m.Properties[fmt.Sprintf("nic%d_connected", index)] = strings.ToLower(items[3])
-					m.Properties[fmt.Sprintf("nic%d_promiscuous", index)] = strings.ToLower(items[8])
-				}
-			}
-			continue
-		}
-		// USB
-		items = regexVMInfo2USb1.FindStringSubmatch(line)
+		items := regexVMInfo2SharedFolders0.FindStringSubmatch(line)
 		if len(items) == 2 {
-			if items[1] == "enabled" {
-				m.Properties["usb1"] = "on"
-			} else {
-				m.Properties["usb1"] = "off"
-			}
-			continue
-		}
-		items = regexVMInfo2USb2.FindStringSubmatch(line)
-		if len(items) == 2 {
-			if items[1] == "enabled" {
-				m.Properties["usb2"] = "on"
-			} else {
-				m.Properties["usb2"] = "off"
-			}
-			continue
-		}
-		items = regexVMInfo2USb3.FindStringSubmatch(line)
-		if len(items) == 2 {
-			if items[1] == "enabled" {
-				m.Properties["usb3"] = "on"
-			} else {
-				m.Properties["usb3"] = "off"
-			}
-			continue
-		}
-		items = regexVMInfo2SharedFolders0.FindStringSubmatch(line)
-		if len(items) == 2 {
-			if items[1] == "<none>" {
-				m.Properties["ssf"] = "no"
-			} else {
-				m.Properties["ssf"] = "yes"
-				inSsf = true
-			}
-			continue
+			foundSsf = true
+			inSsf = items[1] != "<none>"
 		}
 	}
+	if !foundSsf {
+		c.missingLabel("Shared folders:")
+	}
+	c.report(client, []string{"showvminfo", m.UUID})
+	m.updateConfig()
 	return nil
 }
 
@@ -406,6 +331,7 @@ func (m *VMachine) UpdateStatus(client *VmSshClient, callBack func(uuid string))
 			clear(m.Properties)
 		}
 		lastWasDesc := false
+		natNic := ""
 		for _, line := range lines {
 			if line == "" {
 				lastWasDesc = false
@@ -415,6 +341,12 @@ func (m *VMachine) UpdateStatus(client *VmSshClient, callBack func(uuid string))
 			if len(items) == 3 {
 				lastWasDesc = false
 				m.Properties[items[1]] = items[2]
+				// Forwarding(n) follows natnet<nic> and is counted per adapter
+				if strings.HasPrefix(items[1], "natnet") {
+					natNic = items[1][len("natnet"):]
+				} else if natNic != "" && strings.HasPrefix(items[1], "Forwarding(") {
+					m.Properties["natpf"+natNic+items[1][len("Forwarding"):]] = items[2]
+				}
 				continue
 			}
 			items = regexVMInfoKeyValue2.FindStringSubmatch(line)
@@ -448,6 +380,7 @@ func (m *VMachine) UpdateStatus(client *VmSshClient, callBack func(uuid string))
 				continue
 			}
 		}
+		m.updateConfig()
 		if callBack != nil {
 			callBack(m.UUID)
 		}
@@ -509,3 +442,79 @@ func newFromVMList(str string) (*VMachine, error) {
 		logBuffer: make([][]string, 0, MAX_LOG_ENTRIES+1),
 	}, nil
 }
+
+// State of a VM from the bulk listing
+type VMListState struct {
+	// same values as VMState of showvminfo --machinereadable
+	State string
+	Since string
+}
+
+// human readable state of list --long vms to the machine readable one
+func vmStateFromList(state string) string {
+	switch state {
+	case "powered off":
+		return "poweroff"
+	case "teleporting paused vm":
+		return "teleportingpausedvm"
+	case "teleporting (incoming)":
+		return "teleportingin"
+	}
+	return strings.ReplaceAll(state, " ", "")
+}
+
+// States of all VMs of the server with one call - key is the UUID
+func GetVMStates(client *VmSshClient) (map[string]VMListState, error) {
+	vm := VMachine{}
+	lines, err := vm.runCmd(client, VBOXMANAGE_APP, []string{"list", "--long", "vms"}, false, nil)
+	if err != nil {
+		return nil, err
+	}
+	return parseVMListStates(lines), nil
+}
+
+func parseVMListStates(lines []string) map[string]VMListState {
+	states := make(map[string]VMListState, 20)
+	uuid := ""
+	for _, line := range lines {
+		items := regexVMListUuid.FindStringSubmatch(line)
+		if len(items) == 2 {
+			uuid = items[1]
+			continue
+		}
+		if uuid == "" {
+			continue
+		}
+		items = regexVMListState.FindStringSubmatch(line)
+		if len(items) == 3 {
+			states[uuid] = VMListState{
+				State: vmStateFromList(items[1]),
+				Since: items[2],
+			}
+			uuid = ""
+		}
+	}
+	return states
+}
+
+// Takes over the state of the bulk listing - returns true if the state changed
+// or the VM was never fully fetched
+func (m *VMachine) ApplyListState(state VMListState) bool {
+	// a full update is running
+	if !m.lock.TryLock() {
+		return false
+	}
+	defer m.lock.Unlock()
+	prev := m.listState
+	m.listState = state
+	old, ok := m.Properties[VM_PROP_KEY_STATE]
+	if !ok {
+		return true
+	}
+	if old != state.State {
+		m.Properties[VM_PROP_KEY_STATE] = state.State
+		return true
+	}
+	// stopped and started again between two polls
+	return prev.Since != "" && prev.Since != state.Since
+}
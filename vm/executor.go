@@ -0,0 +1,350 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package vm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"bytemystery-com/vboxssh/run"
+
+	"golang.org/x/crypto/ssh"
+)
+
+type ExecutorType int
+
+const (
+	Executor_live ExecutorType = iota
+	Executor_record
+	Executor_replay
+	Executor_preview
+)
+
+// Runs the commands of a VmSshClient. cmd and args are the ones of the vm
+// package - the command config of the server is applied by the live executor.
+type Executor interface {
+	Run(ctx context.Context, client *VmSshClient, cmd string, args []string, userWriterOut, userWriterErr io.Writer) ([]string, error)
+	Type() ExecutorType
+}
+
+// shared by all copies of a client
+type executorRef struct {
+	lock     sync.RWMutex
+	executor Executor
+}
+
+func (r *executorRef) get() Executor {
+	if r == nil {
+		return liveExecutor{}
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	if r.executor == nil {
+		return liveExecutor{}
+	}
+	return r.executor
+}
+
+func (r *executorRef) set(e Executor) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.executor = e
+}
+
+// nil means live
+func (v *VmServer) SetExecutor(e Executor) {
+	if v.Client.executor != nil {
+		v.Client.executor.set(e)
+	}
+}
+
+func (v *VmServer) GetExecutor() Executor {
+	return v.Client.executor.get()
+}
+
+// Copy of the server whose client runs its commands with e - the executor
+// of the server itself and of all other copies stays untouched
+func (v *VmServer) WithExecutor(e Executor) *VmServer {
+	c := *v
+	c.Client = v.Client.copy()
+	c.Client.executor = &executorRef{executor: e}
+	return &c
+}
+
+// The transcript file of a record or replay executor
+func ExecutorFile(e Executor) string {
+	switch x := e.(type) {
+	case *recordExecutor:
+		return x.file
+	case *replayExecutor:
+		return x.file
+	}
+	return ""
+}
+
+// The real VBoxManage - local or via SSH
+type liveExecutor struct{}
+
+func NewLiveExecutor() Executor {
+	return liveExecutor{}
+}
+
+func (liveExecutor) Type() ExecutorType {
+	return Executor_live
+}
+
+func (liveExecutor) Run(ctx context.Context, client *VmSshClient, cmd string, args []string, userWriterOut, userWriterErr io.Writer) ([]string, error) {
+	cmd, args, env := client.config.build(cmd, args, client.IsLocal)
+	var stdin io.Reader
+	if client.secret != "" {
+		stdin = strings.NewReader(client.secret)
+	}
+	if client.IsLocal {
+		return run.RunLocalCmdStdin(ctx, cmd, args, env, stdin, userWriterOut, userWriterErr)
+	}
+	sshClient := client.sshClient()
+	if sshClient == nil {
+		return nil, errors.New("ssh client is null")
+	}
+	if client.limiter != nil {
+		prio := run.Priority_user
+		if client.Background {
+			prio = run.Priority_background
+		}
+		err := client.limiter.AcquireContext(ctx, prio)
+		if err != nil {
+			return nil, err
+		}
+		defer client.limiter.Release()
+	}
+	// the stdin of the persistent shell is the script itself
+	if client.config != nil && client.config.PersistentShell && stdin == nil && userWriterOut == nil && userWriterErr == nil {
+		return run.RunShellCmdContext(ctx, sshClient, cmd, args)
+	}
+	return run.RunSshCmdStdin(ctx, sshClient, cmd, args, stdin, userWriterOut, userWriterErr)
+}
+
+// One command of a transcript file (JSON lines)
+type TranscriptEntry struct {
+	Time  time.Time `json:"time"`
+	Local bool      `json:"local"`
+	Cmd   string    `json:"cmd"`
+	Args  []string  `json:"args"`
+	// output as returned by RunCmd - stdout followed by stderr
+	Lines []string `json:"lines"`
+	// what was written to the writers of the caller (progress)
+	Stdout string `json:"stdout,omitempty"`
+	Stderr string `json:"stderr,omitempty"`
+	// -1 if the command could not be run
+	Exit  int    `json:"exit"`
+	Error string `json:"error,omitempty"`
+}
+
+// secrets are not recorded - so the key uses the redacted args
+func (e *TranscriptEntry) key() string {
+	return e.Cmd + "\x00" + strings.Join(RedactArgs(e.Args), "\x00")
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	var sshErr *ssh.ExitError
+	if errors.As(err, &sshErr) {
+		return sshErr.ExitStatus()
+	}
+	var shellErr *run.ExitError
+	if errors.As(err, &shellErr) {
+		return shellErr.ExitStatus()
+	}
+	return -1
+}
+
+// Runs the commands with next and appends them to a transcript file
+type recordExecutor struct {
+	lock sync.Mutex
+	next Executor
+	file string
+}
+
+func NewRecordExecutor(file string, next Executor) Executor {
+	if next == nil {
+		next = liveExecutor{}
+	}
+	return &recordExecutor{file: file, next: next}
+}
+
+func (r *recordExecutor) Type() ExecutorType {
+	return Executor_record
+}
+
+func (r *recordExecutor) Run(ctx context.Context, client *VmSshClient, cmd string, args []string, userWriterOut, userWriterErr io.Writer) ([]string, error) {
+	// transcripts are attached to tickets - no passwords in the args and the output
+	redacted, secrets := redactArgs(args)
+	secrets = append(secrets, client.secret)
+	entry := TranscriptEntry{
+		Time:  time.Now(),
+		Local: client.IsLocal,
+		Cmd:   cmd,
+		Args:  redacted,
+	}
+	var bOut, bErr bytes.Buffer
+	if userWriterOut != nil {
+		userWriterOut = io.MultiWriter(userWriterOut, &bOut)
+	}
+	if userWriterErr != nil {
+		userWriterErr = io.MultiWriter(userWriterErr, &bErr)
+	}
+	lines, err := r.next.Run(ctx, client, cmd, args, userWriterOut, userWriterErr)
+	entry.Lines = redactLines(lines, secrets)
+	entry.Stdout = redactSecrets(bOut.String(), secrets)
+	entry.Stderr = redactSecrets(bErr.String(), secrets)
+	entry.Exit = exitCode(err)
+	if err != nil {
+		entry.Error = redactSecrets(err.Error(), secrets)
+	}
+	r.append(&entry)
+	return lines, err
+}
+
+// errors are ignored - recording must not change the result of the command
+func (r *recordExecutor) append(entry *TranscriptEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	f, err := os.OpenFile(r.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.Write(append(data, '\n'))
+}
+
+// Serves the commands of a transcript file. The same command gets the
+// recorded results in their order - the last one is repeated.
+type replayExecutor struct {
+	lock    sync.Mutex
+	file    string
+	entries map[string][]*TranscriptEntry
+	next    map[string]int
+}
+
+func NewReplayExecutor(file string) (Executor, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	e, err := NewReplayExecutorFromReader(f)
+	if err != nil {
+		return nil, err
+	}
+	e.(*replayExecutor).file = file
+	return e, nil
+}
+
+func NewReplayExecutorFromReader(reader io.Reader) (Executor, error) {
+	r := replayExecutor{
+		entries: make(map[string][]*TranscriptEntry, 64),
+		next:    make(map[string]int, 64),
+	}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	n := 0
+	for scanner.Scan() {
+		n++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry TranscriptEntry
+		err := json.Unmarshal([]byte(line), &entry)
+		if err != nil {
+			return nil, fmt.Errorf("transcript line %d: %w", n, err)
+		}
+		key := entry.key()
+		r.entries[key] = append(r.entries[key], &entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (r *replayExecutor) Type() ExecutorType {
+	return Executor_replay
+}
+
+func (r *replayExecutor) Run(ctx context.Context, client *VmSshClient, cmd string, args []string, userWriterOut, userWriterErr io.Writer) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	key := (&TranscriptEntry{Cmd: cmd, Args: args}).key()
+	r.lock.Lock()
+	list := r.entries[key]
+	if len(list) == 0 {
+		r.lock.Unlock()
+		return nil, fmt.Errorf("no transcript entry for %s %s", cmd, strings.Join(RedactArgs(args), " "))
+	}
+	index := r.next[key]
+	if index < len(list)-1 {
+		r.next[key] = index + 1
+	}
+	entry := list[index]
+	r.lock.Unlock()
+
+	if userWriterOut != nil && entry.Stdout != "" {
+		io.WriteString(userWriterOut, entry.Stdout)
+	}
+	if userWriterErr != nil && entry.Stderr != "" {
+		io.WriteString(userWriterErr, entry.Stderr)
+	}
+	lines := append([]string(nil), entry.Lines...)
+	if entry.Exit > 0 {
+		// the callers evaluate the exit status
+		return lines, &run.ExitError{Status: entry.Exit}
+	}
+	if entry.Exit != 0 || entry.Error != "" {
+		return lines, errors.New(entry.Error)
+	}
+	return lines, nil
+}
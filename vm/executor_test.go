@@ -0,0 +1,143 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package vm
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bytemystery-com/vboxssh/server"
+)
+
+const testVmUUID = "5f0c9a7e-1d2b-4c3d-9e8f-0a1b2c3d4e5f"
+
+func newTestServer(t *testing.T, e Executor) *VmServer {
+	t.Helper()
+	v := NewVmServer(server.Server{})
+	v.SetExecutor(e)
+	return &v
+}
+
+// server which serves the commands from testdata/<name>
+func newReplayTestServer(t *testing.T, name string) *VmServer {
+	t.Helper()
+	e, err := NewReplayExecutor(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newTestServer(t, e)
+}
+
+// vm calls against a recorded transcript - no VirtualBox needed
+func TestReplayTranscript(t *testing.T) {
+	v := newReplayTestServer(t, "transcript.jsonl")
+
+	version, err := v.GetVersion()
+	if err != nil || version != "7.1.4r165100" {
+		t.Fatalf("GetVersion = %q, %v", version, err)
+	}
+
+	lines, err := RunCmd(&v.Client, VBOXMANAGE_APP, []string{"list", "vms"}, nil, nil)
+	if err != nil || len(lines) != 3 || !strings.Contains(lines[1], testVmUUID) {
+		t.Errorf("list vms = %q, %v", lines, err)
+	}
+
+	// the same command gets the recorded results in their order
+	for _, state := range []string{"poweroff", "running", "running"} {
+		lines, err = RunCmd(&v.Client, VBOXMANAGE_APP, []string{"showvminfo", testVmUUID, "--machinereadable"}, nil, nil)
+		if err != nil || len(lines) < 2 || lines[1] != `VMState="`+state+`"` {
+			t.Errorf("showvminfo = %q, %v - want %s", lines, err, state)
+		}
+	}
+
+	// the exit status is replayed too
+	_, err = RunCmd(&v.Client, VBOXMANAGE_APP, []string{"showvminfo", "unknown", "--machinereadable"}, nil, nil)
+	if exitCode(err) != 1 {
+		t.Errorf("showvminfo unknown = %v", err)
+	}
+
+	if _, err := RunCmd(&v.Client, VBOXMANAGE_APP, []string{"list", "hostonlyifs"}, nil, nil); err == nil {
+		t.Error("a command without transcript entry must fail")
+	}
+}
+
+// answers every command with the password of its args
+type echoExecutor struct{}
+
+func (echoExecutor) Type() ExecutorType {
+	return Executor_live
+}
+
+func (echoExecutor) Run(ctx context.Context, client *VmSshClient, cmd string, args []string, userWriterOut, userWriterErr io.Writer) ([]string, error) {
+	line := "no password"
+	for n, arg := range args[:len(args)-1] {
+		if arg == "--password" {
+			line = "login with " + args[n+1]
+		}
+	}
+	if userWriterOut != nil {
+		io.WriteString(userWriterOut, line+"\n")
+	}
+	return []string{line}, nil
+}
+
+func TestRecordRedactsSecrets(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "transcript.jsonl")
+	v := newTestServer(t, NewRecordExecutor(file, echoExecutor{}))
+	args := []string{"unattended", "install", testVmUUID, "--user=admin", "--password", "s3cret"}
+	var out bytes.Buffer
+	if _, err := RunCmd(&v.Client, VBOXMANAGE_APP, args, &out, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "s3cret") {
+		t.Errorf("the caller must get the real output: %q", out.String())
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("s3cret")) {
+		t.Errorf("secret in transcript: %s", data)
+	}
+
+	// and the recorded transcript can be replayed
+	e, err := NewReplayExecutor(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.SetExecutor(e)
+	out.Reset()
+	if _, err := RunCmd(&v.Client, VBOXMANAGE_APP, args, &out, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "login with ***") {
+		t.Errorf("replayed output = %q", out.String())
+	}
+}
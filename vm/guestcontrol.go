@@ -0,0 +1,164 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package vm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// guestcontrol run - the program runs with the rights of UserName in the guest
+type GuestRunOptions struct {
+	UserName string
+	Password string
+	// windows guests only
+	Domain string
+	// KEY=value - "KEY=" removes the variable
+	Env []string
+	// "" = home directory of the user
+	WorkDir string
+	// the guest process is killed afterwards - 0 = no limit
+	Timeout time.Duration
+	// absolute path in the guest
+	Exe  string
+	Args []string
+}
+
+func (o *GuestRunOptions) args(uuid string) ([]string, error) {
+	if o.UserName == "" {
+		return nil, errors.New("no user name for the guest")
+	}
+	if o.Exe == "" {
+		return nil, errors.New("no program to run in the guest")
+	}
+	args := []string{"guestcontrol", uuid, "run", "--username", o.UserName}
+	// the password is read from stdin - see RunInGuest
+	if o.Password != "" {
+		args = append(args, "--passwordfile", "stdin")
+	}
+	if o.Domain != "" {
+		args = append(args, "--domain", o.Domain)
+	}
+	for _, env := range o.Env {
+		if k, _, ok := strings.Cut(env, "="); !ok || k == "" {
+			return nil, errors.New("invalid environment entry '" + env + "'")
+		}
+		args = append(args, "--putenv", env)
+	}
+	if o.WorkDir != "" {
+		args = append(args, "--cwd", o.WorkDir)
+	}
+	if o.Timeout > 0 {
+		args = append(args, "--timeout", strconv.FormatInt(o.Timeout.Milliseconds(), 10))
+	}
+	// the first argument is the program and arg0 - works with 6.x and 7.x
+	args = append(args, "--", o.Exe)
+	return append(args, o.Args...), nil
+}
+
+// Runs a program in the guest and streams its output to stdout and stderr.
+// Returns the output lines (stdout followed by stderr) and the exit code of
+// VBoxManage which is the one of the guest process if it ended normally.
+// ctx cancels the command.
+func (m *VMachine) RunInGuest(ctx context.Context, v *VmServer, opt *GuestRunOptions, stdout, stderr io.Writer) ([]string, int, error) {
+	if opt.WorkDir != "" && !v.HasCapability(Capability_guestControlCwd) {
+		return nil, -1, v.Capabilities().unsupported(Capability_guestControlCwd)
+	}
+	args, err := opt.args(m.UUID)
+	if err != nil {
+		return nil, -1, err
+	}
+	client := v.Client.WithContext(ctx)
+	if opt.Password != "" {
+		// not on the command line - ps, sudo logs and the shell would show it
+		client = client.WithSecret(opt.Password)
+	}
+	if opt.Timeout > 0 {
+		// VBoxManage ends the guest process itself
+		client = client.WithTimeout(opt.Timeout + 30*time.Second)
+	}
+	lines, err := RunCmd(client, VBOXMANAGE_APP, args, stdout, stderr)
+	return lines, exitCode(err), err
+}
+
+// Splits a command line into arguments. Single quotes keep everything,
+// double quotes allow \" - a backslash outside of quotes only escapes
+// blanks and quotes so that windows paths can be typed as they are.
+func SplitCommandLine(line string) ([]string, error) {
+	var list []string
+	var arg strings.Builder
+	inArg := false
+	var quote rune
+	runes := []rune(line)
+	for n := 0; n < len(runes); n++ {
+		r := runes[n]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case quote == '"':
+			switch {
+			case r == '\\' && n+1 < len(runes) && runes[n+1] == '"':
+				arg.WriteRune('"')
+				n++
+			case r == '"':
+				quote = 0
+			default:
+				arg.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == '\\' && n+1 < len(runes) && (unicode.IsSpace(runes[n+1]) || runes[n+1] == '\'' || runes[n+1] == '"'):
+			arg.WriteRune(runes[n+1])
+			inArg = true
+			n++
+		case unicode.IsSpace(r):
+			if inArg {
+				list = append(list, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("missing closing quote")
+	}
+	if inArg {
+		list = append(list, arg.String())
+	}
+	return list, nil
+}
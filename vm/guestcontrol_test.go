@@ -0,0 +1,110 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package vm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunInGuestReplay(t *testing.T) {
+	v := newReplayTestServer(t, "guestcontrol.jsonl")
+	m := &VMachine{UUID: testVmUUID}
+	var out bytes.Buffer
+	_, exit, err := m.RunInGuest(context.Background(), v, &GuestRunOptions{
+		UserName: "admin",
+		Password: "s3cret",
+		Exe:      "/bin/df",
+		Args:     []string{"-h"},
+	}, &out, nil)
+	if err != nil || exit != 0 {
+		t.Fatalf("RunInGuest = %d, %v", exit, err)
+	}
+	if !strings.Contains(out.String(), "/dev/sda1") {
+		t.Errorf("RunInGuest output = %q", out.String())
+	}
+
+	// the exit code of the guest process
+	_, exit, _ = m.RunInGuest(context.Background(), v, &GuestRunOptions{UserName: "admin", Exe: "/bin/false"}, nil, nil)
+	if exit != 1 {
+		t.Errorf("RunInGuest /bin/false = %d", exit)
+	}
+}
+
+// answers every command with the password it was given on stdin - fails
+// if a password is on the command line
+type stdinEchoExecutor struct{}
+
+func (stdinEchoExecutor) Type() ExecutorType {
+	return Executor_live
+}
+
+func (stdinEchoExecutor) Run(ctx context.Context, client *VmSshClient, cmd string, args []string, userWriterOut, userWriterErr io.Writer) ([]string, error) {
+	for _, arg := range args {
+		if arg == "--password" {
+			return nil, errors.New("password on the command line")
+		}
+	}
+	line := "no password"
+	if client.secret != "" {
+		line = "login with " + client.secret
+	}
+	if userWriterOut != nil {
+		io.WriteString(userWriterOut, line+"\n")
+	}
+	return []string{line}, nil
+}
+
+func TestRunInGuestSecret(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "transcript.jsonl")
+	SetAuditLogDir(filepath.Join(dir, "audit"))
+	defer SetAuditLogDir("")
+	v := newTestServer(t, NewRecordExecutor(file, stdinEchoExecutor{}))
+	m := &VMachine{UUID: testVmUUID}
+	opt := GuestRunOptions{UserName: "admin", Password: "s3cret", Exe: "/bin/true"}
+	var out bytes.Buffer
+	if _, _, err := m.RunInGuest(context.Background(), v, &opt, &out, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "s3cret") {
+		t.Errorf("the caller must get the real output: %q", out.String())
+	}
+	for _, f := range []string{file, auditFile(filepath.Join(dir, "audit"), 0)} {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(data, []byte("s3cret")) || !bytes.Contains(data, []byte("login with ***")) {
+			t.Errorf("secret in %s: %s", filepath.Base(f), data)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package vm
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	GUESTINFO_NET_COUNT     = "/VirtualBox/GuestInfo/Net/Count"
+	GUESTINFO_NET_V4_IP     = "/VirtualBox/GuestInfo/Net/*/V4/IP"
+	GUESTINFO_NET_V6_IP     = "/VirtualBox/GuestInfo/Net/*/V6/IP"
+	GUESTINFO_OS_PRODUCT    = "/VirtualBox/GuestInfo/OS/Product"
+	GUESTINFO_OS_RELEASE    = "/VirtualBox/GuestInfo/OS/Release"
+	GUESTINFO_LOGGEDIN      = "/VirtualBox/GuestInfo/OS/LoggedInUsers"
+	GUESTINFO_LOGGEDIN_LIST = "/VirtualBox/GuestInfo/OS/LoggedInUsersList"
+)
+
+var regexGuestInfoNet = regexp.MustCompile(`^/VirtualBox/GuestInfo/Net/([0-9]+)/V([46])/IP$`)
+
+type GuestAddress struct {
+	// index of the interface in the guest
+	Interface int
+	IPv4      string
+	IPv6      string
+}
+
+// Published by the guest additions of a running VM
+type GuestInfo struct {
+	Addresses     []GuestAddress
+	OsProduct     string
+	OsRelease     string
+	LoggedInUsers int
+	// may be empty even if users are logged in
+	LoggedInUsersList string
+	Time              time.Time
+}
+
+// First IPv4 address - IPv6 if the guest has no IPv4 address
+func (g *GuestInfo) PrimaryAddress() string {
+	for _, a := range g.Addresses {
+		if a.IPv4 != "" {
+			return a.IPv4
+		}
+	}
+	for _, a := range g.Addresses {
+		if a.IPv6 != "" {
+			return a.IPv6
+		}
+	}
+	return ""
+}
+
+func (g *GuestInfo) AllAddresses() []string {
+	var list []string
+	for _, a := range g.Addresses {
+		if a.IPv4 != "" {
+			list = append(list, a.IPv4)
+		}
+	}
+	for _, a := range g.Addresses {
+		if a.IPv6 != "" {
+			list = append(list, a.IPv6)
+		}
+	}
+	return list
+}
+
+func newGuestInfo(props []GuestProperty) *GuestInfo {
+	g := GuestInfo{
+		LoggedInUsers: -1,
+		Time:          time.Now(),
+	}
+	// entries above the count are left over from former interfaces
+	count := -1
+	for _, p := range props {
+		if p.Name == GUESTINFO_NET_COUNT {
+			n, err := strconv.Atoi(p.Value)
+			if err == nil {
+				count = n
+			}
+		}
+	}
+	for _, p := range props {
+		switch p.Name {
+		case GUESTINFO_OS_PRODUCT:
+			g.OsProduct = p.Value
+		case GUESTINFO_OS_RELEASE:
+			g.OsRelease = p.Value
+		case GUESTINFO_LOGGEDIN:
+			n, err := strconv.Atoi(p.Value)
+			if err == nil {
+				g.LoggedInUsers = n
+			}
+		case GUESTINFO_LOGGEDIN_LIST:
+			g.LoggedInUsersList = p.Value
+		default:
+			items := regexGuestInfoNet.FindStringSubmatch(p.Name)
+			if len(items) != 3 || p.Value == "" {
+				continue
+			}
+			index, _ := strconv.Atoi(items[1])
+			if count >= 0 && index >= count {
+				continue
+			}
+			n := slices.IndexFunc(g.Addresses, func(a GuestAddress) bool {
+				return a.Interface == index
+			})
+			if n < 0 {
+				g.Addresses = append(g.Addresses, GuestAddress{Interface: index})
+				n = len(g.Addresses) - 1
+			}
+			if items[2] == "4" {
+				g.Addresses[n].IPv4 = p.Value
+			} else {
+				g.Addresses[n].IPv6 = p.Value
+			}
+		}
+	}
+	slices.SortFunc(g.Addresses, func(a, b GuestAddress) int {
+		return cmp.Compare(a.Interface, b.Interface)
+	})
+	return &g
+}
+
+// Only a running VM with guest additions publishes the infos
+func (m *VMachine) GuestInfoAvailable() bool {
+	state, err := m.GetState()
+	if err != nil || state != RunState_running {
+		return false
+	}
+	return m.Config().General.GuestAdditionsRunLevel > 0
+}
+
+// Last infos read by UpdateGuestInfo - nil if not available
+func (m *VMachine) GuestInfo() *GuestInfo {
+	return m.guestInfo.Load()
+}
+
+// Reads the infos with one enumerate - background uses the background client
+func (m *VMachine) UpdateGuestInfo(v *VmServer, background bool) (*GuestInfo, error) {
+	if !m.GuestInfoAvailable() {
+		m.guestInfo.Store(nil)
+		return nil, nil
+	}
+	client := &v.Client
+	if background {
+		client = v.BackgroundClient()
+	}
+	props, err := m.enumerateGuestProperties(client, v.HasCapability(Capability_guestPropertyPatterns), []string{
+		GUESTINFO_NET_COUNT, GUESTINFO_NET_V4_IP, GUESTINFO_NET_V6_IP,
+		GUESTINFO_OS_PRODUCT, GUESTINFO_OS_RELEASE, GUESTINFO_LOGGEDIN + "*",
+	})
+	if err != nil {
+		return nil, err
+	}
+	g := newGuestInfo(props)
+	m.guestInfo.Store(g)
+	return g, nil
+}
@@ -0,0 +1,326 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package vm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// /VirtualBox/GuestInfo/OS/Product = 'Linux' @ 2026-01-02T10:11:12.123456789Z [TRANSIENT, RDONLYGUEST]
+	regexGuestPropertyNew = regexp.MustCompile(`^(\S+)\s+=\s+'(.*)'(?:\s+@\s+(\S+))?(?:\s+\[(.*)\])?\s*$`)
+	// Name: /VirtualBox/GuestInfo/OS/Product, value: Linux, timestamp: 1767348672123456789, flags: TRANSIENT, RDONLYGUEST
+	regexGuestPropertyOld = regexp.MustCompile(`^Name:\s*(.*?),\s*value:\s*(.*),\s*timestamp:\s*(\d+),\s*flags:\s*(.*)$`)
+	// Name: /Test, value: 1, flags: TRANSIENT
+	regexGuestPropertyWait  = regexp.MustCompile(`^Name:\s*(.*?),\s*value:\s*(.*),\s*flags:\s*(.*)$`)
+	regexGuestPropertyValue = regexp.MustCompile(`^Value:\s*(.*)$`)
+	regexGuestPropertyTime  = regexp.MustCompile(`^Timestamp:\s*(\d+)`)
+	regexGuestPropertyFlags = regexp.MustCompile(`^Flags:\s*(.*)$`)
+	regexGuestPropertyNone  = regexp.MustCompile(`^No (properties found|value set)`)
+)
+
+var guestPropertyFlags = map[string]GuestPropertyFlagType{
+	"TRANSIENT":   GuestPropertyFlag_transient,
+	"TRANSRESET":  GuestPropertyFlag_transreset,
+	"RDONLYGUEST": GuestPropertyFlag_rdonlyguest,
+	"RDONLYHOST":  GuestPropertyFlag_rdonlyhost,
+	"READONLY":    GuestPropertyFlag_readonly,
+}
+
+type GuestProperty struct {
+	Name  string
+	Value string
+	// zero if unknown
+	TimeStamp time.Time
+	Flags     []GuestPropertyFlagType
+}
+
+func (p *GuestProperty) HasFlag(flag GuestPropertyFlagType) bool {
+	for _, f := range p.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// "TRANSIENT, RDONLYGUEST" - unknown flags are an error
+func ParseGuestPropertyFlags(str string) ([]GuestPropertyFlagType, error) {
+	var flags []GuestPropertyFlagType
+	for _, item := range strings.Split(str, ",") {
+		item = strings.ToUpper(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		f, ok := guestPropertyFlags[item]
+		if !ok {
+			return flags, fmt.Errorf("unknown guest property flag '%s'", item)
+		}
+		flags = append(flags, f)
+	}
+	return flags, nil
+}
+
+func FormatGuestPropertyFlags(flags []GuestPropertyFlagType) string {
+	list := make([]string, 0, len(flags))
+	for _, f := range flags {
+		str, err := argTranslate(f)
+		if err == nil {
+			list = append(list, str)
+		}
+	}
+	return strings.Join(list, ",")
+}
+
+// Patterns as used by VBoxManage - * and ? as wildcards (* includes /), alternatives separated by |
+func MatchGuestPropertyPattern(pattern, name string) bool {
+	if pattern == "" {
+		return true
+	}
+	for _, item := range strings.Split(pattern, "|") {
+		var b strings.Builder
+		b.WriteString("^")
+		for _, r := range item {
+			switch r {
+			case '*':
+				b.WriteString(".*")
+			case '?':
+				b.WriteString(".")
+			default:
+				b.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+		b.WriteString("$")
+		reg, err := regexp.Compile(b.String())
+		if err == nil && reg.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseGuestPropertyTime(str string) time.Time {
+	ns, err := strconv.ParseInt(str, 10, 64)
+	if err == nil {
+		return time.Unix(0, ns)
+	}
+	t, err := time.Parse(time.RFC3339Nano, str)
+	if err == nil {
+		return t
+	}
+	return time.Time{}
+}
+
+func parseGuestProperties(lines []string) ([]GuestProperty, *parseCollector) {
+	list := []GuestProperty{}
+	c := parseCollector{}
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \r")
+		if line == "" || regexGuestPropertyNone.MatchString(line) {
+			continue
+		}
+		items := regexGuestPropertyNew.FindStringSubmatch(line)
+		if len(items) != 5 {
+			items = regexGuestPropertyOld.FindStringSubmatch(line)
+		}
+		if len(items) != 5 {
+			c.unparsed(line)
+			continue
+		}
+		p := GuestProperty{
+			Name:      items[1],
+			Value:     items[2],
+			TimeStamp: parseGuestPropertyTime(items[3]),
+		}
+		flags, err := ParseGuestPropertyFlags(items[4])
+		if err != nil {
+			c.unparsed(line)
+		}
+		p.Flags = flags
+		list = append(list, p)
+	}
+	return list, &c
+}
+
+// All properties if no pattern is given
+func (m *VMachine) EnumerateGuestProperties(v *VmServer, patterns ...string) ([]GuestProperty, error) {
+	return m.enumerateGuestProperties(&v.Client, v.HasCapability(Capability_guestPropertyPatterns), patterns)
+}
+
+func (m *VMachine) enumerateGuestProperties(client *VmSshClient, positional bool, patterns []string) ([]GuestProperty, error) {
+	args := []string{"guestproperty", "enumerate", m.UUID}
+	if len(patterns) > 0 {
+		if positional {
+			args = append(args, patterns...)
+		} else {
+			args = append(args, "--patterns", strings.Join(patterns, "|"))
+		}
+	}
+	lines, err := RunCmd(client, VBOXMANAGE_APP, args, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	list, c := parseGuestProperties(lines)
+	c.report(client, args)
+	return list, nil
+}
+
+// nil if the property is not set
+func (m *VMachine) GetGuestProperty(client *VmSshClient, name string) (*GuestProperty, error) {
+	lines, err := RunCmd(client, VBOXMANAGE_APP, []string{"guestproperty", "get", m.UUID, name, "--verbose"}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var p *GuestProperty
+	for _, line := range lines {
+		if regexGuestPropertyNone.MatchString(line) {
+			return nil, nil
+		}
+		items := regexGuestPropertyValue.FindStringSubmatch(line)
+		if len(items) == 2 {
+			p = &GuestProperty{Name: name, Value: items[1]}
+			continue
+		}
+		if p == nil {
+			continue
+		}
+		items = regexGuestPropertyTime.FindStringSubmatch(line)
+		if len(items) == 2 {
+			p.TimeStamp = parseGuestPropertyTime(items[1])
+			continue
+		}
+		items = regexGuestPropertyFlags.FindStringSubmatch(line)
+		if len(items) == 2 {
+			p.Flags, _ = ParseGuestPropertyFlags(items[1])
+		}
+	}
+	if p == nil {
+		return nil, errors.New("unknown output of guestproperty get")
+	}
+	return p, nil
+}
+
+// An empty value deletes the property
+func (m *VMachine) SetGuestProperty(client *VmSshClient, name, value string, flags []GuestPropertyFlagType, callBack func(uuid string)) error {
+	if name == "" {
+		return errors.New("empty guest property name")
+	}
+	if value == "" {
+		return m.UnsetGuestProperty(client, name, callBack)
+	}
+	opt := []any{"set", m.UUID, name, value}
+	if len(flags) > 0 {
+		opt = append(opt, "--flags", FormatGuestPropertyFlags(flags))
+	}
+	return m.setPropertyEx2(client, "guestproperty", opt, callBack)
+}
+
+func (m *VMachine) UnsetGuestProperty(client *VmSshClient, name string, callBack func(uuid string)) error {
+	return m.setPropertyEx2(client, "guestproperty", []any{"unset", m.UUID, name}, callBack)
+}
+
+// Waits until a property matching pattern changes - nil if nothing changed within timeout.
+// An unset property has an empty value.
+func (m *VMachine) WaitGuestProperty(client *VmSshClient, pattern string, timeout time.Duration) (*GuestProperty, error) {
+	args := []string{"guestproperty", "wait", m.UUID, pattern, "--timeout", strconv.FormatInt(timeout.Milliseconds(), 10)}
+	// the command itself ends after timeout
+	lines, err := RunCmd(client.WithTimeout(timeout+30*time.Second), VBOXMANAGE_APP, args, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range lines {
+		items := regexGuestPropertyWait.FindStringSubmatch(strings.TrimSpace(line))
+		if len(items) == 4 {
+			p := GuestProperty{
+				Name:      items[1],
+				Value:     items[2],
+				TimeStamp: time.Now(),
+			}
+			p.Flags, _ = ParseGuestPropertyFlags(items[3])
+			return &p, nil
+		}
+	}
+	return nil, nil
+}
+
+// Calls f for every change of a property matching pattern until ctx is done.
+// The session is given back after every interval.
+// wait only reports one change and ends, so every change is taken from
+// the difference of two enumerations.
+func (m *VMachine) WatchGuestProperties(ctx context.Context, v *VmServer, pattern string, interval time.Duration, f func(p GuestProperty)) error {
+	c := v.Client.WithContext(ctx)
+	c.Background = true
+	positional := v.HasCapability(Capability_guestPropertyPatterns)
+	patterns := strings.Split(pattern, "|")
+	last, err := m.enumerateGuestProperties(c, positional, patterns)
+	for err == nil && ctx.Err() == nil {
+		_, err = m.WaitGuestProperty(c, pattern, interval)
+		if err != nil {
+			break
+		}
+		var list []GuestProperty
+		list, err = m.enumerateGuestProperties(c, positional, patterns)
+		if err != nil {
+			break
+		}
+		for _, p := range diffGuestProperties(last, list) {
+			f(p)
+		}
+		last = list
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// new and changed properties of list, removed ones with an empty value
+func diffGuestProperties(last, list []GuestProperty) []GuestProperty {
+	var changes []GuestProperty
+	for _, p := range list {
+		index := slices.IndexFunc(last, func(item GuestProperty) bool {
+			return item.Name == p.Name
+		})
+		if index < 0 || last[index].Value != p.Value || !last[index].TimeStamp.Equal(p.TimeStamp) ||
+			!slices.Equal(last[index].Flags, p.Flags) {
+			changes = append(changes, p)
+		}
+	}
+	for _, p := range last {
+		if !slices.ContainsFunc(list, func(item GuestProperty) bool {
+			return item.Name == p.Name
+		}) {
+			changes = append(changes, GuestProperty{Name: p.Name, TimeStamp: time.Now()})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package vm
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestEnumerateGuestProperties(t *testing.T) {
+	v := newReplayTestServer(t, "guestproperty.jsonl")
+	m := &VMachine{UUID: testVmUUID}
+	props, err := m.EnumerateGuestProperties(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(props) != 2 || props[0].Value != "Linux" || !props[0].HasFlag(GuestPropertyFlag_rdonlyguest) || props[0].TimeStamp.IsZero() ||
+		props[1].Name != "/Test" || props[1].Value != "x" {
+		t.Errorf("EnumerateGuestProperties = %+v", props)
+	}
+}
+
+func TestDiffGuestProperties(t *testing.T) {
+	stamp := time.Unix(1767348672, 0)
+	last := []GuestProperty{
+		{Name: "/a", Value: "1", TimeStamp: stamp},
+		{Name: "/b", Value: "2", TimeStamp: stamp},
+		{Name: "/c", Value: "3", TimeStamp: stamp},
+		{Name: "/d", Value: "4", TimeStamp: stamp},
+	}
+	list := []GuestProperty{
+		{Name: "/a", Value: "1", TimeStamp: stamp},
+		{Name: "/b", Value: "2", TimeStamp: stamp.Add(time.Second)},
+		{Name: "/c", Value: "x", TimeStamp: stamp},
+		{Name: "/e", Value: "5", TimeStamp: stamp},
+	}
+	changes := diffGuestProperties(last, list)
+	var names []string
+	for _, p := range changes {
+		names = append(names, p.Name+"="+p.Value)
+	}
+	if want := []string{"/b=2", "/c=x", "/e=5", "/d="}; !slices.Equal(names, want) {
+		t.Errorf("diffGuestProperties = %v, want %v", names, want)
+	}
+	if len(diffGuestProperties(list, list)) != 0 {
+		t.Error("diffGuestProperties reports unchanged properties")
+	}
+}
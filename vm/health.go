@@ -0,0 +1,292 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package vm
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"bytemystery-com/vboxssh/server"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	KEEPALIVE_INTERVAL   = 15 * time.Second
+	KEEPALIVE_TIMEOUT    = 10 * time.Second
+	KEEPALIVE_SLOW       = 2 * time.Second
+	KEEPALIVE_MAX_MISSED = 3
+	RECONNECT_MIN_DELAY  = 2 * time.Second
+	RECONNECT_MAX_DELAY  = 2 * time.Minute
+)
+
+type ConnState int
+
+const (
+	ConnState_offline ConnState = iota
+	ConnState_connected
+	ConnState_degraded
+	ConnState_reconnecting
+)
+
+// called whenever the connection state of a server changes
+var connStateCallBack func(uuid string)
+
+func SetConnStateCallBack(callBack func(uuid string)) {
+	connStateCallBack = callBack
+}
+
+type connMonitor struct {
+	lock    sync.Mutex
+	state   ConnState
+	stop    chan struct{}
+	rtt     time.Duration
+	retries int
+	lastErr error
+}
+
+func (v *VmServer) setConnState(state ConnState, err error) {
+	v.monitor.lock.Lock()
+	changed := v.monitor.state != state
+	v.monitor.state = state
+	v.monitor.lastErr = err
+	v.monitor.lock.Unlock()
+	if changed && connStateCallBack != nil {
+		connStateCallBack(v.UUID)
+	}
+}
+
+func (v *VmServer) GetConnState() ConnState {
+	if v.IsLocal() {
+		return ConnState_connected
+	}
+	if v.monitor == nil {
+		if v.SshClient() != nil {
+			return ConnState_connected
+		}
+		return ConnState_offline
+	}
+	v.monitor.lock.Lock()
+	defer v.monitor.lock.Unlock()
+	return v.monitor.state
+}
+
+// round trip time of the last keepalive, reconnect attempts and the last error
+func (v *VmServer) GetConnHealth() (time.Duration, int, error) {
+	if v.monitor == nil {
+		return 0, 0, nil
+	}
+	v.monitor.lock.Lock()
+	defer v.monitor.lock.Unlock()
+	return v.monitor.rtt, v.monitor.retries, v.monitor.lastErr
+}
+
+func (v *VmServer) startMonitor(client *ssh.Client) {
+	v.monitor.lock.Lock()
+	if v.monitor.stop != nil {
+		close(v.monitor.stop)
+	}
+	stop := make(chan struct{})
+	v.monitor.stop = stop
+	v.monitor.retries = 0
+	v.monitor.lock.Unlock()
+	v.setConnState(ConnState_connected, nil)
+	go v.monitorProc(client, stop)
+}
+
+func (v *VmServer) stopMonitor() {
+	v.monitor.lock.Lock()
+	defer v.monitor.lock.Unlock()
+	if v.monitor.stop != nil {
+		close(v.monitor.stop)
+		v.monitor.stop = nil
+	}
+}
+
+// nil if the keepalive was answered in time
+func keepAlive(client *ssh.Client) (time.Duration, error) {
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		// a failure reply is fine - the connection is alive
+		_, _, err := client.SendRequest("keepalive@openssh.com", true, nil)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		return time.Since(start), err
+	case <-time.After(KEEPALIVE_TIMEOUT):
+		return KEEPALIVE_TIMEOUT, errors.New("keepalive timeout")
+	}
+}
+
+func (v *VmServer) monitorProc(client *ssh.Client, stop chan struct{}) {
+	ticker := time.NewTicker(KEEPALIVE_INTERVAL)
+	defer ticker.Stop()
+	dead := make(chan struct{})
+	go func() {
+		client.Wait()
+		close(dead)
+	}()
+	missed := 0
+	for {
+		select {
+		case <-stop:
+			return
+		case <-dead:
+			v.reconnectProc(stop, errors.New("connection lost"))
+			return
+		case <-ticker.C:
+			rtt, err := keepAlive(client)
+			v.monitor.lock.Lock()
+			v.monitor.rtt = rtt
+			v.monitor.lock.Unlock()
+			if err == nil {
+				missed = 0
+				if rtt > KEEPALIVE_SLOW {
+					v.setConnState(ConnState_degraded, nil)
+				} else {
+					v.setConnState(ConnState_connected, nil)
+				}
+				continue
+			}
+			missed++
+			v.setConnState(ConnState_degraded, err)
+			if missed >= KEEPALIVE_MAX_MISSED {
+				v.reconnectProc(stop, err)
+				return
+			}
+		}
+	}
+}
+
+// reconnects with exponential backoff until it works or the monitor is stopped
+func (v *VmServer) reconnectProc(stop chan struct{}, reason error) {
+	select {
+	case <-stop:
+		return
+	default:
+	}
+	if old := v.swapClient(nil); old != nil {
+		v.Server.Disonnect(&old)
+	}
+	v.setConnState(ConnState_reconnecting, reason)
+	delay := RECONNECT_MIN_DELAY
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(delay):
+		}
+		v.monitor.lock.Lock()
+		v.monitor.retries++
+		v.monitor.lock.Unlock()
+
+		var client *ssh.Client
+		err := v.Server.Reconnect(&client)
+		if err == nil {
+			// stopped in the meantime by a manual connect / disconnect - the
+			// check is done under the client lock, so a later connect sees
+			// the new client and closes it
+			if !v.publishClient(client, stop) {
+				v.Server.Disonnect(&client)
+				return
+			}
+			v.monitor.lock.Lock()
+			v.monitor.retries = 0
+			v.monitor.lock.Unlock()
+			v.setConnState(ConnState_connected, nil)
+			go v.monitorProc(client, stop)
+			return
+		}
+		// a changed host key needs the user
+		var changedErr *server.HostKeyChangedError
+		var rejectedErr *server.HostKeyRejectedError
+		if errors.As(err, &changedErr) || errors.As(err, &rejectedErr) {
+			v.setConnState(ConnState_offline, err)
+			return
+		}
+		v.setConnState(ConnState_reconnecting, err)
+		delay = min(2*delay, RECONNECT_MAX_DELAY)
+	}
+}
+
+// false if the monitor was stopped - the client was not published then
+func (v *VmServer) publishClient(client *ssh.Client, stop chan struct{}) bool {
+	if v.Client.clientLock != nil {
+		v.Client.clientLock.Lock()
+		defer v.Client.clientLock.Unlock()
+	}
+	select {
+	case <-stop:
+		return false
+	default:
+	}
+	v.Client.Client = client
+	return true
+}
+
+// the client of the server is replaced under its lock
+func (v *VmServer) takeClient(client **ssh.Client) *ssh.Client {
+	if client == &v.Client.Client {
+		return v.swapClient(nil)
+	}
+	c := *client
+	*client = nil
+	return c
+}
+
+// stops the health monitor before the client is closed
+func (v *VmServer) Disonnect(client **ssh.Client) error {
+	v.stopMonitor()
+	if client == nil {
+		return errors.New("client is nil")
+	}
+	c := v.takeClient(client)
+	err := v.Server.Disonnect(&c)
+	v.setConnState(ConnState_offline, nil)
+	return err
+}
+
+func (v *VmServer) Reconnect(client **ssh.Client) error {
+	v.stopMonitor()
+	if client == nil {
+		return errors.New("client is nil")
+	}
+	c := v.takeClient(client)
+	err := v.Server.Reconnect(&c)
+	if err != nil {
+		v.setConnState(ConnState_offline, err)
+		return err
+	}
+	if client == &v.Client.Client {
+		v.swapClient(c)
+	} else {
+		*client = c
+	}
+	v.startMonitor(c)
+	return nil
+}
@@ -25,8 +25,7 @@
 package vm
 
 func (m *VMachine) SetAudioEnabled(v *VmServer, audioEnabled bool, callBack func(uuid string)) error {
-	maj, _, _ := v.getVmVersion()
-	if maj == 6 {
+	if !v.HasCapability(Capability_audioEnabled) {
 		if !audioEnabled {
 			return m.SetAudioDriver(v, AudioDriver_none, callBack)
 		} else {
@@ -38,46 +37,21 @@ func (m *VMachine) SetAudioEnabled(v *VmServer, audioEnabled bool, callBack func
 }
 
 func (m *VMachine) SetAudioController(v *VmServer, audioController AudioControllerType, callBack func(uuid string)) error {
-	maj, _, _ := v.getVmVersion()
-	if maj == 6 {
-		return m.setProperty(&v.Client, "audiocontroller", audioController, callBack)
-	} else {
-		return m.setProperty(&v.Client, "audio-controller", audioController, callBack)
-	}
+	return m.setProperty(&v.Client, v.Capabilities().option("audio-controller", "audiocontroller"), audioController, callBack)
 }
 
 func (m *VMachine) SetAudioCodec(v *VmServer, audioCodec AudioCodecType, callBack func(uuid string)) error {
-	maj, _, _ := v.getVmVersion()
-	if maj == 6 {
-		return m.setProperty(&v.Client, "audiocodec", audioCodec, callBack)
-	} else {
-		return m.setProperty(&v.Client, "audio-codec", audioCodec, callBack)
-	}
+	return m.setProperty(&v.Client, v.Capabilities().option("audio-codec", "audiocodec"), audioCodec, callBack)
 }
 
 func (m *VMachine) SetAudioInEnabled(v *VmServer, audioInEnabled bool, callBack func(uuid string)) error {
-	maj, _, _ := v.getVmVersion()
-	if maj == 6 {
-		return m.setProperty(&v.Client, "audioin", audioInEnabled, callBack)
-	} else {
-		return m.setProperty(&v.Client, "audio-in", audioInEnabled, callBack)
-	}
+	return m.setProperty(&v.Client, v.Capabilities().option("audio-in", "audioin"), audioInEnabled, callBack)
 }
 
 func (m *VMachine) SetAudioOutEnabled(v *VmServer, audioOutEnabled bool, callBack func(uuid string)) error {
-	maj, _, _ := v.getVmVersion()
-	if maj == 6 {
-		return m.setProperty(&v.Client, "audioout", audioOutEnabled, callBack)
-	} else {
-		return m.setProperty(&v.Client, "audio-out", audioOutEnabled, callBack)
-	}
+	return m.setProperty(&v.Client, v.Capabilities().option("audio-out", "audioout"), audioOutEnabled, callBack)
 }
 
 func (m *VMachine) SetAudioDriver(v *VmServer, audioDriver AudioDriverType, callBack func(uuid string)) error {
-	maj, _, _ := v.getVmVersion()
-	if maj == 6 {
-		return m.setProperty(&v.Client, "audio", audioDriver, callBack)
-	} else {
-		return m.setProperty(&v.Client, "audio-driver", audioDriver, callBack)
-	}
+	return m.setProperty(&v.Client, v.Capabilities().option("audio-driver", "audio"), audioDriver, callBack)
 }
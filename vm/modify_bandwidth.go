@@ -0,0 +1,169 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package vm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type BandwidthGroup struct {
+	Name string
+	Type BandwidthGroupType
+	// bytes per second - 0 disables the limit
+	Limit int64
+}
+
+func (m *VMachine) GetBandwidthGroups(client *VmSshClient) ([]BandwidthGroup, error) {
+	args := []string{"bandwidthctl", m.UUID, "list", "--machinereadable"}
+	lines, err := RunCmd(client, VBOXMANAGE_APP, args, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	list, c := parseBandwidthGroups(lines)
+	c.report(client, args)
+	return list, nil
+}
+
+// name="Limit"
+// type="Disk"
+// maxBytesPerSec=20971520
+func parseBandwidthGroups(lines []string) ([]BandwidthGroup, *parseCollector) {
+	list := []BandwidthGroup{}
+	c := parseCollector{}
+	parseRecords(lines, &c, func(record []string) bool {
+		group := BandwidthGroup{}
+		found := 0
+		for _, line := range record {
+			items := regexVMInfoKeyValue.FindStringSubmatch(line)
+			if len(items) != 3 {
+				items = regexVMInfoKeyValue2.FindStringSubmatch(line)
+			}
+			if len(items) != 3 {
+				return false
+			}
+			switch strings.TrimSpace(items[1]) {
+			case "name":
+				group.Name = items[2]
+				found++
+			case "type":
+				switch strings.ToLower(items[2]) {
+				case "disk":
+					group.Type = BandwidthGroup_disk
+				case "network":
+					group.Type = BandwidthGroup_network
+				default:
+					return false
+				}
+				found++
+			case "maxBytesPerSec":
+				limit, err := strconv.ParseInt(strings.TrimSpace(items[2]), 10, 64)
+				if err != nil {
+					return false
+				}
+				group.Limit = limit
+				found++
+			}
+		}
+		if found != 3 {
+			return false
+		}
+		list = append(list, group)
+		return true
+	})
+	return list, &c
+}
+
+// --limit accepts K for kibibytes - rounded up that a small limit does not become unlimited
+func bandwidthLimitArg(limit int64) string {
+	if limit <= 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%dK", (limit+1023)/1024)
+}
+
+func (m *VMachine) AddBandwidthGroup(client *VmSshClient, group BandwidthGroup, callBack func(uuid string)) error {
+	if group.Name == "" || strings.ContainsAny(group.Name, " \t") {
+		return fmt.Errorf("invalid bandwidth group name '%s'", group.Name)
+	}
+	return m.setPropertyEx2(client, "bandwidthctl", []any{m.UUID, "add", group.Name, "--type", group.Type, "--limit", bandwidthLimitArg(group.Limit)}, callBack)
+}
+
+// works with a running VM too
+func (m *VMachine) SetBandwidthLimit(client *VmSshClient, name string, limit int64, callBack func(uuid string)) error {
+	return m.setPropertyEx2(client, "bandwidthctl", []any{m.UUID, "set", name, "--limit", bandwidthLimitArg(limit)}, callBack)
+}
+
+// fails if the group is still assigned to an adapter or attachment
+func (m *VMachine) RemoveBandwidthGroup(client *VmSshClient, name string, callBack func(uuid string)) error {
+	return m.setPropertyEx2(client, "bandwidthctl", []any{m.UUID, "remove", name}, callBack)
+}
+
+// An empty name removes the group from the adapter
+func (m *VMachine) SetNicBandwidthGroup(v *VmServer, ifNumber int, name string, callBack func(uuid string)) error {
+	if name == "" {
+		name = "none"
+	}
+	return m.setProperty(&v.Client, fmt.Sprintf(v.Capabilities().option("nic-bandwidth-group%d", "nicbandwidthgroup%d"), ifNumber), name, callBack)
+}
+
+// An empty name removes the group from the attachment
+func (m *VMachine) SetStorageBandwidthGroup(client *VmSshClient, controllerName string, port, device int, name string, callBack func(uuid string)) error {
+	if name == "" {
+		name = "none"
+	}
+	return m.setPropertyEx2(client, "storageattach", []any{m.UUID, "--storagectl=" + controllerName, "--port", port, "--device", device, "--bandwidthgroup", name}, callBack)
+}
+
+type AttachmentBandwidthGroup struct {
+	Controller string
+	Port       int
+	Device     int
+	Group      string
+}
+
+// The groups of the storage attachments are only stored in the .vbox file
+func (m *VMachine) GetAttachmentBandwidthGroups(client *VmSshClient) ([]AttachmentBandwidthGroup, error) {
+	vbox, err := readSettingsFile(client, m.Config().General.CfgFile)
+	if err != nil {
+		return nil, err
+	}
+	list := []AttachmentBandwidthGroup{}
+	for _, ctrl := range vbox.Machine.storageControllers() {
+		for _, dev := range ctrl.Attached {
+			if dev.BandwidthGroup == "" {
+				continue
+			}
+			list = append(list, AttachmentBandwidthGroup{
+				Controller: ctrl.Name,
+				Port:       dev.Port,
+				Device:     dev.Device,
+				Group:      dev.BandwidthGroup,
+			})
+		}
+	}
+	return list, nil
+}
@@ -25,57 +25,38 @@
 package vm
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 // ifNumber starts from 1 up to 8
-func (m *VMachine) SetNetType(client *VmSshClient, ifNumber int, netType NetType, callBack func(uuid string)) error {
-	return m.setProperty(client, fmt.Sprintf("nic%d", ifNumber), netType, callBack)
+func (m *VMachine) SetNetType(v *VmServer, ifNumber int, netType NetType, callBack func(uuid string)) error {
+	if netType == Net_cloudnetwork && !v.HasCapability(Capability_cloudNetwork) {
+		return v.Capabilities().unsupported(Capability_cloudNetwork)
+	}
+	return m.setProperty(&v.Client, fmt.Sprintf("nic%d", ifNumber), netType, callBack)
 }
 
 func (m *VMachine) SetNetDevice(v *VmServer, ifNumber int, nicType NicType, callBack func(uuid string)) error {
-	maj, _, _ := v.getVmVersion()
-	if maj == 6 {
-		return m.setProperty(&v.Client, fmt.Sprintf("nictype%d", ifNumber), nicType, callBack)
-	} else {
-		return m.setProperty(&v.Client, fmt.Sprintf("nic-type%d", ifNumber), nicType, callBack)
-	}
+	return m.setProperty(&v.Client, fmt.Sprintf(v.Capabilities().option("nic-type%d", "nictype%d"), ifNumber), nicType, callBack)
 }
 
 func (m *VMachine) SetCableConnected(v *VmServer, ifNumber int, bConnected bool, callBack func(uuid string)) error {
-	maj, _, _ := v.getVmVersion()
-	if maj == 6 {
-		return m.setProperty(&v.Client, fmt.Sprintf("cableconnected%d", ifNumber), bConnected, callBack)
-	} else {
-		return m.setProperty(&v.Client, fmt.Sprintf("cable-connected%d", ifNumber), bConnected, callBack)
-	}
+	return m.setProperty(&v.Client, fmt.Sprintf(v.Capabilities().option("cable-connected%d", "cableconnected%d"), ifNumber), bConnected, callBack)
 }
 
 func (m *VMachine) SetPromiscMode(v *VmServer, ifNumber int, promiscType PromiscType, callBack func(uuid string)) error {
-	maj, _, _ := v.getVmVersion()
-	if maj == 6 {
-		return m.setProperty(&v.Client, fmt.Sprintf("nicpromisc%d", ifNumber), promiscType, callBack)
-	} else {
-		return m.setProperty(&v.Client, fmt.Sprintf("nic-promisc%d", ifNumber), promiscType, callBack)
-	}
+	return m.setProperty(&v.Client, fmt.Sprintf(v.Capabilities().option("nic-promisc%d", "nicpromisc%d"), ifNumber), promiscType, callBack)
 }
 
 func (m *VMachine) SetBridgeAdapter(v *VmServer, ifNumber int, adapter string, callBack func(uuid string)) error {
-	maj, _, _ := v.getVmVersion()
-	if maj == 6 {
-		return m.setProperty(&v.Client, fmt.Sprintf("bridgeadapter%d", ifNumber), adapter, callBack)
-	} else {
-		return m.setProperty(&v.Client, fmt.Sprintf("bridge-adapter%d", ifNumber), adapter, callBack)
-	}
+	return m.setProperty(&v.Client, fmt.Sprintf(v.Capabilities().option("bridge-adapter%d", "bridgeadapter%d"), ifNumber), adapter, callBack)
 }
 
 func (m *VMachine) SetHostOnlyAdapter(v *VmServer, ifNumber int, adapter string, callBack func(uuid string)) error {
-	maj, _, _ := v.getVmVersion()
-	if maj == 6 {
-		return m.setProperty(&v.Client, fmt.Sprintf("hostonlyadapter%d", ifNumber), adapter, callBack)
-	} else {
-		return m.setProperty(&v.Client, fmt.Sprintf("host-only-adapter%d", ifNumber), adapter, callBack)
-	}
+	return m.setProperty(&v.Client, fmt.Sprintf(v.Capabilities().option("host-only-adapter%d", "hostonlyadapter%d"), ifNumber), adapter, callBack)
 }
 
 func (m *VMachine) SetNatAdapter(client *VmSshClient, ifNumber int, adapter string, callBack func(uuid string)) error {
@@ -87,23 +68,149 @@ func (m *VMachine) SetInternalNetworkName(client *VmSshClient, ifNumber int, nam
 }
 
 func (m *VMachine) SetGenericNetworkName(v *VmServer, ifNumber int, name string, callBack func(uuid string)) error {
-	maj, _, _ := v.getVmVersion()
-	if maj == 6 {
-		return m.setProperty(&v.Client, fmt.Sprintf("nicgenericdrv%d", ifNumber), name, callBack)
-	} else {
-		return m.setProperty(&v.Client, fmt.Sprintf("nic-generic-drv%d", ifNumber), name, callBack)
-	}
+	return m.setProperty(&v.Client, fmt.Sprintf(v.Capabilities().option("nic-generic-drv%d", "nicgenericdrv%d"), ifNumber), name, callBack)
 }
 
-func (m *VMachine) SetCloudNetworkName(client *VmSshClient, ifNumber int, name string, callBack func(uuid string)) error {
-	return m.setProperty(client, fmt.Sprintf("cloud-network%d", ifNumber), name, callBack)
+func (m *VMachine) SetCloudNetworkName(v *VmServer, ifNumber int, name string, callBack func(uuid string)) error {
+	if !v.HasCapability(Capability_cloudNetwork) {
+		return v.Capabilities().unsupported(Capability_cloudNetwork)
+	}
+	return m.setProperty(&v.Client, fmt.Sprintf("cloud-network%d", ifNumber), name, callBack)
 }
 
 func (m *VMachine) SetMacAddress(v *VmServer, ifNumber int, mac string, callBack func(uuid string)) error {
-	maj, _, _ := v.getVmVersion()
-	if maj == 6 {
-		return m.setProperty(&v.Client, fmt.Sprintf("macaddress%d", ifNumber), mac, callBack)
-	} else {
-		return m.setProperty(&v.Client, fmt.Sprintf("mac-address%d", ifNumber), mac, callBack)
+	return m.setProperty(&v.Client, fmt.Sprintf(v.Capabilities().option("mac-address%d", "macaddress%d"), ifNumber), mac, callBack)
+}
+
+type NatRule struct {
+	Name      string
+	Protocol  NatProtocolType
+	HostIP    string
+	HostPort  int
+	GuestIP   string
+	GuestPort int
+}
+
+// name,tcp,hostip,hostport,guestip,guestport
+func ParseNatRule(str string) (NatRule, error) {
+	items := strings.Split(str, ",")
+	if len(items) != 6 {
+		return NatRule{}, errors.New("wrong number of fields")
+	}
+	rule := NatRule{
+		Name:    items[0],
+		HostIP:  items[2],
+		GuestIP: items[4],
+	}
+	switch strings.ToLower(items[1]) {
+	case "tcp":
+		rule.Protocol = NatProtocol_tcp
+	case "udp":
+		rule.Protocol = NatProtocol_udp
+	default:
+		return NatRule{}, errors.New("unknown protocol")
+	}
+	var err error
+	if rule.HostPort, err = strconv.Atoi(items[3]); err != nil {
+		return NatRule{}, errors.New("wrong host port")
+	}
+	if rule.GuestPort, err = strconv.Atoi(items[5]); err != nil {
+		return NatRule{}, errors.New("wrong guest port")
+	}
+	return rule, nil
+}
+
+// rule for --natpf<n> and controlvm natpf<n>
+func (r NatRule) String() string {
+	protocol, _ := argTranslate(r.Protocol)
+	return fmt.Sprintf("%s,%s,%s,%d,%s,%d", r.Name, protocol, r.HostIP, r.HostPort, r.GuestIP, r.GuestPort)
+}
+
+func (r NatRule) Validate() error {
+	if r.Name == "" || strings.ContainsAny(r.Name, ",\"") {
+		return errors.New("invalid rule name")
+	}
+	if r.HostPort <= 0 || r.HostPort > 65535 {
+		return errors.New("invalid host port")
+	}
+	if r.GuestPort <= 0 || r.GuestPort > 65535 {
+		return errors.New("invalid guest port")
+	}
+	return nil
+}
+
+// Same protocol and host port on an overlapping host address
+func (r NatRule) Collides(o NatRule) bool {
+	if r.Protocol != o.Protocol || r.HostPort != o.HostPort {
+		return false
+	}
+	anyAddr := func(ip string) bool {
+		return ip == "" || ip == "0.0.0.0"
+	}
+	return anyAddr(r.HostIP) || anyAddr(o.HostIP) || r.HostIP == o.HostIP
+}
+
+func (m *VMachine) GetNatRules(ifNumber int) []NatRule {
+	for _, nic := range m.Config().Nics {
+		if nic.Index == ifNumber {
+			return nic.NatRules
+		}
+	}
+	return nil
+}
+
+// a running VM gets the rule with controlvm
+func (m *VMachine) natpfCmd(ifNumber int, args ...string) []string {
+	state, _ := m.GetState()
+	switch state {
+	case RunState_running, RunState_paused:
+		return append([]string{"controlvm", m.UUID, fmt.Sprintf("natpf%d", ifNumber)}, args...)
+	default:
+		return append([]string{"modifyvm", m.UUID, fmt.Sprintf("--natpf%d", ifNumber)}, args...)
+	}
+}
+
+func (m *VMachine) AddNatRule(client *VmSshClient, ifNumber int, rule NatRule, callBack func(uuid string)) error {
+	err := rule.Validate()
+	if err != nil {
+		return err
+	}
+	return m.setPropertyInternal(client, m.natpfCmd(ifNumber, rule.String()), true, callBack)
+}
+
+func (m *VMachine) DeleteNatRule(client *VmSshClient, ifNumber int, name string, callBack func(uuid string)) error {
+	return m.setPropertyInternal(client, m.natpfCmd(ifNumber, "delete", name), true, callBack)
+}
+
+type NatRuleUse struct {
+	VmName   string
+	VmUUID   string
+	IfNumber int
+	Rule     NatRule
+}
+
+// Rules of vms colliding with rule - the rule itself (same VM, adapter and name) is skipped
+func FindNatCollisions(vms []*VMachine, self *VMachine, ifNumber int, rule NatRule) []NatRuleUse {
+	var list []NatRuleUse
+	for _, v := range vms {
+		for _, nic := range v.Config().Nics {
+			if nic.Attachment != "nat" {
+				continue
+			}
+			for _, r := range nic.NatRules {
+				if v == self && nic.Index == ifNumber && r.Name == rule.Name {
+					continue
+				}
+				if r.Collides(rule) {
+					list = append(list, NatRuleUse{
+						VmName:   v.Name,
+						VmUUID:   v.UUID,
+						IfNumber: nic.Index,
+						Rule:     r,
+					})
+				}
+			}
+		}
 	}
+	return list
 }
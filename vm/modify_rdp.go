@@ -29,46 +29,21 @@ func (m *VMachine) SetEnableRde(client *VmSshClient, bRde bool, callBack func(uu
 }
 
 func (m *VMachine) SetRdePorts(s *VmServer, ports string, callBack func(uuid string)) error {
-	maj, _, _ := s.getVmVersion()
-	if maj == 6 {
-		return m.setProperty(&s.Client, "vrdeport", ports, callBack)
-	} else {
-		return m.setProperty(&s.Client, "vrde-port", ports, callBack)
-	}
+	return m.setProperty(&s.Client, s.Capabilities().option("vrde-port", "vrdeport"), ports, callBack)
 }
 
 func (m *VMachine) SetRdeMultiConnection(s *VmServer, multi bool, callBack func(uuid string)) error {
-	maj, _, _ := s.getVmVersion()
-	if maj == 6 {
-		return m.setProperty(&s.Client, "vrdemulticon", multi, callBack)
-	} else {
-		return m.setProperty(&s.Client, "vrde-multi-con", multi, callBack)
-	}
+	return m.setProperty(&s.Client, s.Capabilities().option("vrde-multi-con", "vrdemulticon"), multi, callBack)
 }
 
 func (m *VMachine) SetRdeReuseConnection(s *VmServer, reuse bool, callBack func(uuid string)) error {
-	maj, _, _ := s.getVmVersion()
-	if maj == 6 {
-		return m.setProperty(&s.Client, "vrdereusecon", reuse, callBack)
-	} else {
-		return m.setProperty(&s.Client, "vrde-reuse-con", reuse, callBack)
-	}
+	return m.setProperty(&s.Client, s.Capabilities().option("vrde-reuse-con", "vrdereusecon"), reuse, callBack)
 }
 
 func (m *VMachine) SetRdeSecurityMethode(s *VmServer, security RdpSecurityType, callBack func(uuid string)) error {
-	maj, _, _ := s.getVmVersion()
-	if maj == 6 {
-		return m.setProperty(&s.Client, "vrdeproperty=Security/Method", security, callBack)
-	} else {
-		return m.setProperty(&s.Client, "vrde-property=Security/Method", security, callBack)
-	}
+	return m.setProperty(&s.Client, s.Capabilities().option("vrde-property=Security/Method", "vrdeproperty=Security/Method"), security, callBack)
 }
 
 func (m *VMachine) SetRdeAuthType(s *VmServer, auth RdpAuthType, callBack func(uuid string)) error {
-	maj, _, _ := s.getVmVersion()
-	if maj == 6 {
-		return m.setProperty(&s.Client, "vrdeauthtype", auth, callBack)
-	} else {
-		return m.setProperty(&s.Client, "vrde-auth-type", auth, callBack)
-	}
+	return m.setProperty(&s.Client, s.Capabilities().option("vrde-auth-type", "vrdeauthtype"), auth, callBack)
 }
@@ -30,33 +30,20 @@ import (
 )
 
 func (m *VMachine) SetUsb(v *VmServer, usb UsbType, callBack func(uuid string)) error {
-	maj, _, _ := v.getVmVersion()
-	if maj == 6 {
-		switch usb {
-		case Usb_none:
-			return m.setPropertyInternal(&v.Client, []string{"modifyvm", m.UUID, "--usbohci=off", "--usbehci=off", "--usbxhci=off"}, true, callBack)
-		case Usb_1:
-			return m.setPropertyInternal(&v.Client, []string{"modifyvm", m.UUID, "--usbohci=on", "--usbehci=off", "--usbxhci=off"}, true, callBack)
-		case Usb_2:
-			return m.setPropertyInternal(&v.Client, []string{"modifyvm", m.UUID, "--usbohci=off", "--usbehci=on", "--usbxhci=off"}, true, callBack)
-		case Usb_3:
-			return m.setPropertyInternal(&v.Client, []string{"modifyvm", m.UUID, "--usbohci=off", "--usbehci=off", "--usbxhci=on"}, true, callBack)
-		default:
-			return errors.New("unknown usbtype")
-		}
-	} else {
-		switch usb {
-		case Usb_none:
-			return m.setPropertyInternal(&v.Client, []string{"modifyvm", m.UUID, "--usb-ohci=off", "--usb-ehci=off", "--usb-xhci=off"}, true, callBack)
-		case Usb_1:
-			return m.setPropertyInternal(&v.Client, []string{"modifyvm", m.UUID, "--usb-ohci=on", "--usb-ehci=off", "--usb-xhci=off"}, true, callBack)
-		case Usb_2:
-			return m.setPropertyInternal(&v.Client, []string{"modifyvm", m.UUID, "--usb-ohci=off", "--usb-ehci=on", "--usb-xhci=off"}, true, callBack)
-		case Usb_3:
-			return m.setPropertyInternal(&v.Client, []string{"modifyvm", m.UUID, "--usb-ohci=off", "--usb-ehci=off", "--usb-xhci=on"}, true, callBack)
-		default:
-			return errors.New("unknown usbtype")
-		}
+	ohci := v.Capabilities().option("--usb-ohci=", "--usbohci=")
+	ehci := v.Capabilities().option("--usb-ehci=", "--usbehci=")
+	xhci := v.Capabilities().option("--usb-xhci=", "--usbxhci=")
+	switch usb {
+	case Usb_none:
+		return m.setPropertyInternal(&v.Client, []string{"modifyvm", m.UUID, ohci + "off", ehci + "off", xhci + "off"}, true, callBack)
+	case Usb_1:
+		return m.setPropertyInternal(&v.Client, []string{"modifyvm", m.UUID, ohci + "on", ehci + "off", xhci + "off"}, true, callBack)
+	case Usb_2:
+		return m.setPropertyInternal(&v.Client, []string{"modifyvm", m.UUID, ohci + "off", ehci + "on", xhci + "off"}, true, callBack)
+	case Usb_3:
+		return m.setPropertyInternal(&v.Client, []string{"modifyvm", m.UUID, ohci + "off", ehci + "off", xhci + "on"}, true, callBack)
+	default:
+		return errors.New("unknown usbtype")
 	}
 }
 
@@ -81,47 +68,47 @@ func getYesNoFromBool(b bool) string {
 }
 
 func (m *VMachine) AddUsbFilter(client *VmSshClient, index int, name, vendorId, productId, serialNumber, product, manufacturer string, active bool, callBack func(uuid string)) error {
-	options := []string{"usbfilter", "add", strconv.Itoa(index), "--target", m.UUID, "--name", client.quoteArgString(name)}
+	options := []string{"usbfilter", "add", strconv.Itoa(index), "--target", m.UUID, "--name", name}
 	if vendorId != "" {
-		options = append(options, "--vendorid", client.quoteArgString(vendorId))
+		options = append(options, "--vendorid", vendorId)
 	}
 	if productId != "" {
-		options = append(options, "--productid", client.quoteArgString(productId))
+		options = append(options, "--productid", productId)
 	}
 	if serialNumber != "" {
-		options = append(options, "--serialnumber", client.quoteArgString(serialNumber))
+		options = append(options, "--serialnumber", serialNumber)
 	}
 	if product != "" {
-		options = append(options, "--product", client.quoteArgString(product))
+		options = append(options, "--product", product)
 	}
 	if manufacturer != "" {
-		options = append(options, "--manufacturer", client.quoteArgString(manufacturer))
+		options = append(options, "--manufacturer", manufacturer)
 	}
-	options = append(options, "--active", client.quoteArgString(getYesNoFromBool(active)))
+	options = append(options, "--active", getYesNoFromBool(active))
 	return m.setPropertyInternal(client, options, true, callBack)
 }
 
 func (m *VMachine) ModifyUsbFilter(client *VmSshClient, index int, name, vendorId, productId, serialNumber, product, manufacturer string, active bool, callBack func(uuid string)) error {
 	options := []string{"usbfilter", "modify", strconv.Itoa(index), "--target", m.UUID}
 	if name != "" {
-		options = append(options, "--name", client.quoteArgString(name))
+		options = append(options, "--name", name)
 	}
 	if vendorId != "" {
-		options = append(options, "--vendorid", client.quoteArgString(vendorId))
+		options = append(options, "--vendorid", vendorId)
 	}
 	if productId != "" {
-		options = append(options, "--productid", client.quoteArgString(productId))
+		options = append(options, "--productid", productId)
 	}
 	if serialNumber != "" {
-		options = append(options, "--serialnumber", client.quoteArgString(serialNumber))
+		options = append(options, "--serialnumber", serialNumber)
 	}
 	if product != "" {
-		options = append(options, "--product", client.quoteArgString(product))
+		options = append(options, "--product", product)
 	}
 	if manufacturer != "" {
-		options = append(options, "--manufacturer", client.quoteArgString(manufacturer))
+		options = append(options, "--manufacturer", manufacturer)
 	}
-	options = append(options, "--active", client.quoteArgString(getYesNoFromBool(active)))
+	options = append(options, "--active", getYesNoFromBool(active))
 	return m.setPropertyInternal(client, options, true, callBack)
 }
 
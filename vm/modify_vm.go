@@ -54,30 +54,15 @@ func (m *VMachine) SetX2Acpi(client *VmSshClient, x2acpi bool, callBack func(uui
 }
 
 func (m *VMachine) SetNestedPaging(v *VmServer, nestedPaging bool, callBack func(uuid string)) error {
-	maj, _, _ := v.getVmVersion()
-	if maj == 6 {
-		return m.setProperty(&v.Client, "nestedpaging", nestedPaging, callBack)
-	} else {
-		return m.setProperty(&v.Client, "nested-paging", nestedPaging, callBack)
-	}
+	return m.setProperty(&v.Client, v.Capabilities().option("nested-paging", "nestedpaging"), nestedPaging, callBack)
 }
 
 func (m *VMachine) SetParaVirtProvider(v *VmServer, paraVirtProvider ParaVirtProviderType, callBack func(uuid string)) error {
-	maj, _, err := v.getVmVersion()
-	if err == nil && maj == 6 {
-		return m.setProperty(&v.Client, "paravirtprovider", paraVirtProvider, callBack)
-	} else {
-		return m.setProperty(&v.Client, "paravirt-provider", paraVirtProvider, callBack)
-	}
+	return m.setProperty(&v.Client, v.Capabilities().option("paravirt-provider", "paravirtprovider"), paraVirtProvider, callBack)
 }
 
 func (m *VMachine) SetCPUExecCap(v *VmServer, cpuExecCap int, callBack func(uuid string)) error {
-	maj, _, _ := v.getVmVersion()
-	if maj == 6 {
-		return m.setProperty(&v.Client, "cpuexecutioncap", cpuExecCap, callBack)
-	} else {
-		return m.setProperty(&v.Client, "cpu-execution-cap", cpuExecCap, callBack)
-	}
+	return m.setProperty(&v.Client, v.Capabilities().option("cpu-execution-cap", "cpuexecutioncap"), cpuExecCap, callBack)
 }
 
 func (m *VMachine) SetChipset(client *VmSshClient, chipSet ChipSetType, callBack func(uuid string)) error {
@@ -109,12 +94,7 @@ func (m *VMachine) SetHPet(client *VmSshClient, hpet bool, callBack func(uuid st
 }
 
 func (m *VMachine) SetUseUtc(s *VmServer, useUtc bool, callBack func(uuid string)) error {
-	maj, _, _ := s.getVmVersion()
-	if maj == 6 {
-		return m.setProperty(&s.Client, "rtcuseutc", useUtc, callBack)
-	} else {
-		return m.setProperty(&s.Client, "rtc-use-utc", useUtc, callBack)
-	}
+	return m.setProperty(&s.Client, s.Capabilities().option("rtc-use-utc", "rtcuseutc"), useUtc, callBack)
 }
 
 func (m *VMachine) SetFirmware(client *VmSshClient, firmware FirmwareType, callBack func(uuid string)) error {
@@ -139,12 +119,7 @@ func (m *VMachine) SetVgaController(client *VmSshClient, vga VgaType, callBack f
 }
 
 func (m *VMachine) SetAccelerate3D(s *VmServer, bAccel bool, callBack func(uuid string)) error {
-	maj, _, _ := s.getVmVersion()
-	if maj == 6 {
-		return m.setProperty(&s.Client, "accelerate3d", bAccel, callBack)
-	} else {
-		return m.setProperty(&s.Client, "accelerate-3d", bAccel, callBack)
-	}
+	return m.setProperty(&s.Client, s.Capabilities().option("accelerate-3d", "accelerate3d"), bAccel, callBack)
 }
 
 func (m *VMachine) SetAccelerate2D(client *VmSshClient, bAccel bool, callBack func(uuid string)) error {
@@ -152,7 +127,7 @@ func (m *VMachine) SetAccelerate2D(client *VmSshClient, bAccel bool, callBack fu
 }
 
 func (m *VMachine) SetName(client *VmSshClient, name string, callBack func(uuid string)) error {
-	return m.setProperty(client, "name", client.quoteArgString(name), callBack)
+	return m.setProperty(client, "name", name, callBack)
 }
 
 func (m *VMachine) SetOsType(client *VmSshClient, osType string, callBack func(uuid string)) error {
@@ -160,11 +135,6 @@ func (m *VMachine) SetOsType(client *VmSshClient, osType string, callBack func(u
 }
 
 func (m *VMachine) SetDescription(client *VmSshClient, description string, callBack func(uuid string)) error {
-	if client.IsLocal {
-		// description = description
-	} else {
-		description = "$'" + description + "'"
-	}
 	return m.setProperty(client, "description", description, callBack)
 }
 
@@ -173,22 +143,16 @@ func (m *VMachine) SetProcessPriority(client *VmSshClient, processPriority Proce
 }
 
 func (m *VMachine) SetBiosTimeOffset(v *VmServer, offset int, callBack func(uuid string)) error {
-	maj, _, _ := v.getVmVersion()
-	if maj == 6 {
-		return m.setProperty(&v.Client, "biossystemtimeoffset", offset, callBack)
-	} else {
-		return m.setProperty(&v.Client, "bios-system-time-offset", offset, callBack)
-	}
+	return m.setProperty(&v.Client, v.Capabilities().option("bios-system-time-offset", "biossystemtimeoffset"), offset, callBack)
 }
 
 func (m *VMachine) DeleteVm(v *VmServer, del bool) error {
 	opt := []string{"unregistervm", m.UUID}
-	maj, _, _ := v.getVmVersion()
 	if del {
-		if maj == 6 {
-			opt = append(opt, "--delete")
-		} else {
+		if v.HasCapability(Capability_deleteAll) {
 			opt = append(opt, "--delete-all")
+		} else {
+			opt = append(opt, "--delete")
 		}
 	}
 
@@ -196,10 +160,10 @@ func (m *VMachine) DeleteVm(v *VmServer, del bool) error {
 	return err
 }
 
-func (m *VMachine) CloneVm(v *VmServer, newName string, mode CloneModeType, link, macs, diskNames, hwUuids CloneOptionsType, snapShotName string, statusWriter io.Writer) error {
+func (m *VMachine) CloneVm(client *VmSshClient, newName string, mode CloneModeType, link, macs, diskNames, hwUuids CloneOptionsType, snapShotName string, statusWriter io.Writer) error {
 	opt := []any{"clonevm", m.UUID}
 	opt = append(opt, "--mode", mode)
-	opt = append(opt, "--name", v.Client.quoteArgString(newName))
+	opt = append(opt, "--name", newName)
 	opStr := ""
 	if link != CloneOption_none {
 		if opStr != "" {
@@ -241,10 +205,13 @@ func (m *VMachine) CloneVm(v *VmServer, newName string, mode CloneModeType, link
 		}
 		opStr += s
 	}
-	opt = append(opt, "--options", v.Client.quoteArgString(opStr))
+	// empty args are dropped by argPreProcess
+	if opStr != "" {
+		opt = append(opt, "--options", opStr)
+	}
 	opt = append(opt, "--register")
 	if link != CloneOption_none {
-		opt = append(opt, "--snapshot", v.Client.quoteArgString(snapShotName))
+		opt = append(opt, "--snapshot", snapShotName)
 	}
 
 	optStr, err := argPreProcess("", opt)
@@ -252,20 +219,20 @@ func (m *VMachine) CloneVm(v *VmServer, newName string, mode CloneModeType, link
 		return err
 	}
 
-	_, err = RunCmd(&v.Client, VBOXMANAGE_APP, optStr, nil, statusWriter)
+	_, err = RunCmd(client, VBOXMANAGE_APP, optStr, nil, statusWriter)
+	finishProgress(statusWriter, err)
 	return err
 }
 
 func (m *VMachine) AddSharedFolder(v *VmServer, name, hostPath, mountPath string, readOnly, autoMount, global bool, callBack func(uuid string)) error {
 	opt := []string{"sharedfolder", "add"}
-	maj, _, _ := v.getVmVersion()
-	if global && maj > 6 {
+	if global && v.HasCapability(Capability_globalSharedFolder) {
 		opt = append(opt, "global")
 	} else {
 		opt = append(opt, m.UUID)
 	}
-	opt = append(opt, "--name", v.Client.quoteArgString(name))
-	opt = append(opt, "--hostpath", v.Client.quoteArgString(hostPath))
+	opt = append(opt, "--name", name)
+	opt = append(opt, "--hostpath", hostPath)
 	if readOnly {
 		opt = append(opt, "--readonly")
 	}
@@ -273,30 +240,29 @@ func (m *VMachine) AddSharedFolder(v *VmServer, name, hostPath, mountPath string
 		opt = append(opt, "--automount")
 	}
 	if mountPath != "" {
-		opt = append(opt, "--auto-mount-point", v.Client.quoteArgString(mountPath))
+		opt = append(opt, "--auto-mount-point", mountPath)
 	}
 	return m.setPropertyInternal(&v.Client, opt, true, callBack)
 }
 
 func (m *VMachine) RemoveSharedFolder(v *VmServer, name string, global bool, callBack func(uuid string)) error {
 	opt := []string{"sharedfolder", "remove"}
-	maj, _, _ := v.getVmVersion()
-	if global && maj > 6 {
+	if global && v.HasCapability(Capability_globalSharedFolder) {
 		opt = append(opt, "global")
 	} else {
 		opt = append(opt, m.UUID)
 	}
-	opt = append(opt, "--name", v.Client.quoteArgString(name))
+	opt = append(opt, "--name", name)
 	return m.setPropertyInternal(&v.Client, opt, true, callBack)
 }
 
 func (m *VMachine) SetExtraData(v *VmServer, key, value string) error {
-	opt := []string{"setextradata", m.UUID, v.Client.quoteArgString(key), v.Client.quoteArgString(value)}
+	opt := []string{"setextradata", m.UUID, key, value}
 	return m.setPropertyInternal(&v.Client, opt, true, nil)
 }
 
 func (m *VMachine) GetExtraData(v *VmServer, key string) (string, error) {
-	opt := []string{"getextradata", m.UUID, v.Client.quoteArgString(key)}
+	opt := []string{"getextradata", m.UUID, key}
 	lines, err := m.runCmd(&v.Client, VBOXMANAGE_APP, opt, false, nil)
 	if err != nil {
 		return "", err
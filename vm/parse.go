@@ -0,0 +1,132 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package vm
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Output of VBoxManage which a parser did not understand - e.g. because
+// the labels were translated or changed with a new version
+type ParseReport struct {
+	Time   time.Time
+	Server string
+	Cmd    string
+	// blocks of lines which could not be assigned
+	Sections [][]string
+	// labels which were expected but not found
+	Missing []string
+}
+
+func (r *ParseReport) String() string {
+	var b strings.Builder
+	for _, label := range r.Missing {
+		fmt.Fprintf(&b, "missing: %s\n", label)
+	}
+	for _, section := range r.Sections {
+		b.WriteString(strings.Join(section, "\n"))
+		b.WriteString("\n\n")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+var parseReportCallBack struct {
+	lock sync.Mutex
+	f    func(r *ParseReport)
+}
+
+// f is called for every parse report - from the goroutine of the command
+func SetParseReportCallBack(f func(r *ParseReport)) {
+	parseReportCallBack.lock.Lock()
+	defer parseReportCallBack.lock.Unlock()
+	parseReportCallBack.f = f
+}
+
+type parseCollector struct {
+	sections [][]string
+	missing  []string
+}
+
+func (c *parseCollector) unparsed(lines ...string) {
+	if len(lines) > 0 {
+		c.sections = append(c.sections, slices.Clone(lines))
+	}
+}
+
+func (c *parseCollector) missingLabel(label string) {
+	c.missing = append(c.missing, label)
+}
+
+// Writes the collected sections to the audit log and calls the call back
+func (c *parseCollector) report(client *VmSshClient, args []string) {
+	if len(c.sections) == 0 && len(c.missing) == 0 {
+		return
+	}
+	r := ParseReport{
+		Time:     time.Now(),
+		Server:   client.address,
+		Cmd:      VBOXMANAGE_APP + " " + strings.Join(args, " "),
+		Sections: c.sections,
+		Missing:  c.missing,
+	}
+	if client.IsLocal {
+		r.Server = "local"
+	}
+	if DEBUG {
+		fmt.Println("VM - unparsed output of", r.Cmd, "\n"+r.String())
+	}
+	auditParseReport(&r, args)
+
+	parseReportCallBack.lock.Lock()
+	f := parseReportCallBack.f
+	parseReportCallBack.lock.Unlock()
+	if f != nil {
+		f(&r)
+	}
+}
+
+// Calls parse for every block of lines separated by empty lines. Blocks
+// for which parse returns false are collected as unparsed.
+func parseRecords(lines []string, c *parseCollector, parse func(record []string) bool) {
+	start := -1
+	for n := 0; n <= len(lines); n++ {
+		if n < len(lines) && strings.TrimSpace(lines[n]) != "" {
+			if start < 0 {
+				start = n
+			}
+			continue
+		}
+		if start >= 0 {
+			if !parse(lines[start:n]) {
+				c.unparsed(lines[start:n]...)
+			}
+			start = -1
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package vm
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// One option of a modifying command with the value it will get
+type PreviewChange struct {
+	Option string
+	After  string
+}
+
+// A command collected by the preview executor
+type PreviewCmd struct {
+	Cmd     string
+	Args    []string
+	Changes []PreviewChange
+}
+
+// Collects the modifying commands instead of running them. Commands which
+// only read are passed to the next executor, so the caller sees the same
+// data as without the preview.
+type PreviewExecutor struct {
+	lock   sync.Mutex
+	next   Executor
+	cmds   []PreviewCmd
+	active int
+	last   time.Time
+}
+
+func NewPreviewExecutor(next Executor) *PreviewExecutor {
+	if next == nil {
+		next = liveExecutor{}
+	}
+	return &PreviewExecutor{next: next, last: time.Now()}
+}
+
+func (p *PreviewExecutor) Type() ExecutorType {
+	return Executor_preview
+}
+
+func (p *PreviewExecutor) Run(ctx context.Context, client *VmSshClient, cmd string, args []string, userWriterOut, userWriterErr io.Writer) ([]string, error) {
+	p.lock.Lock()
+	p.active++
+	p.lock.Unlock()
+	defer func() {
+		p.lock.Lock()
+		p.active--
+		p.last = time.Now()
+		p.lock.Unlock()
+	}()
+
+	if isReadOnlyCmd(cmd, args) {
+		return p.next.Run(ctx, client, cmd, args, userWriterOut, userWriterErr)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	item := PreviewCmd{
+		Cmd:     cmd,
+		Args:    slices.Clone(args),
+		Changes: previewChanges(args),
+	}
+	p.lock.Lock()
+	p.cmds = append(p.cmds, item)
+	p.lock.Unlock()
+	// looks like a successful VBoxManage without output
+	return []string{""}, nil
+}
+
+// Waits until no command has been running for quiet - the apply functions
+// of the GUI start their commands in goroutines. Returns after max at the
+// latest.
+func (p *PreviewExecutor) Wait(quiet, max time.Duration) []PreviewCmd {
+	end := time.Now().Add(max)
+	for time.Now().Before(end) {
+		p.lock.Lock()
+		done := p.active == 0 && time.Since(p.last) >= quiet
+		p.lock.Unlock()
+		if done {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return p.Commands()
+}
+
+func (p *PreviewExecutor) Commands() []PreviewCmd {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return slices.Clone(p.cmds)
+}
+
+func (c *VmSshClient) isPreview() bool {
+	return c.executor.get().Type() == Executor_preview
+}
+
+// collected commands are not written to the audit log - they did not run
+func isAuditable(e Executor, cmd string, args []string) bool {
+	return e.Type() != Executor_preview || isReadOnlyCmd(cmd, args)
+}
+
+// VBoxManage sub commands which do not change anything
+func isReadOnlyCmd(cmd string, args []string) bool {
+	if cmd != VBOXMANAGE_APP || len(args) == 0 {
+		return false
+	}
+	switch args[0] {
+	case "--version", "-v", "-version", "list", "showvminfo", "showmediuminfo", "showhdinfo", "getextradata", "metrics":
+		return true
+	case "guestproperty":
+		return len(args) > 1 && (args[1] == "get" || args[1] == "enumerate" || args[1] == "wait")
+	case "snapshot":
+		return len(args) > 2 && (args[2] == "list" || args[2] == "showvminfo")
+	case "mediumproperty":
+		return len(args) > 1 && args[1] == "get"
+	case "bandwidthctl":
+		return len(args) > 2 && args[2] == "list"
+	}
+	return false
+}
+
+// the --option=value arguments of modifyvm
+func previewChanges(args []string) []PreviewChange {
+	if len(args) < 3 || args[0] != "modifyvm" {
+		return nil
+	}
+	var changes []PreviewChange
+	for _, arg := range args[2:] {
+		if !strings.HasPrefix(arg, "--") {
+			continue
+		}
+		option, value, ok := strings.Cut(arg[2:], "=")
+		if !ok {
+			continue
+		}
+		changes = append(changes, PreviewChange{Option: option, After: value})
+	}
+	return changes
+}
+
+// Finds the value of a modifyvm option in the properties of showvminfo.
+// The names differ between the versions in dashes and underscores only.
+func (m *VMachine) PropertyForOption(option string) (string, bool) {
+	key := normalizeOption(option)
+	for k, v := range m.Properties {
+		if normalizeOption(k) == key {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+func normalizeOption(s string) string {
+	s = strings.ToLower(s)
+	s = strings.ReplaceAll(s, "-", "")
+	return strings.ReplaceAll(s, "_", "")
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package vm
+
+import (
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type ProgressEventType int
+
+const (
+	ProgressEvent_percent ProgressEventType = iota
+	ProgressEvent_phase
+	ProgressEvent_error
+	ProgressEvent_done
+)
+
+var (
+	regexProgressPercent = regexp.MustCompile(`^(\d{1,3})%$`)
+	regexProgressCode    = regexp.MustCompile(`code (\w+) \((0x[0-9a-fA-F]+)\)`)
+	regexProgressState   = regexp.MustCompile(`^Progress state: (\w+)`)
+)
+
+// One step of a long running VBoxManage command
+type ProgressEvent struct {
+	Type ProgressEventType
+	// 0..100 or -1 if unknown
+	Percent int
+	// last text line which was no error - e.g. "Interpreting /x.ova"
+	Phase   string
+	Elapsed time.Duration
+	// linear estimation - 0 if unknown
+	Remaining time.Duration
+	// e.g. "VBOX_E_FILE_ERROR (0x80bb0004)"
+	ErrorCode string
+	// error text (ProgressEvent_error) or the error of the command (ProgressEvent_done)
+	Error string
+}
+
+// Parses the "0%...10%...100%" output of VBoxManage. Pass it as status
+// writer to CloneVm, ExportOva, ImportOva, CreateMedia, TakeSnapshot and
+// DeleteSnapshot - they send the ProgressEvent_done event.
+type ProgressWriter struct {
+	lock      sync.Mutex
+	start     time.Time
+	buf       string
+	percent   int
+	phase     string
+	errorCode string
+	// collected with lock held, sent without
+	pending  []ProgressEvent
+	callBack func(ProgressEvent)
+}
+
+var _ io.Writer = (*ProgressWriter)(nil)
+
+func NewProgressWriter(callBack func(ProgressEvent)) *ProgressWriter {
+	return &ProgressWriter{
+		start:    time.Now(),
+		percent:  -1,
+		callBack: callBack,
+	}
+}
+
+func (w *ProgressWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	w.buf += strings.ReplaceAll(string(p), "\r", "\n")
+	for {
+		item, ok := w.nextItem()
+		if !ok {
+			break
+		}
+		w.handle(item)
+	}
+	w.lock.Unlock()
+	w.deliver()
+	return len(p), nil
+}
+
+// items are separated by a line end or by the "..." after a percent item,
+// a phase like "Interpreting ../x.ova..." ends at the line end only
+func (w *ProgressWriter) nextItem() (string, bool) {
+	nl := strings.IndexByte(w.buf, '\n')
+	index := strings.Index(w.buf, "...")
+	if index >= 0 && (nl < 0 || index < nl) && regexProgressPercent.MatchString(strings.TrimSpace(w.buf[:index])) {
+		item := w.buf[:index]
+		w.buf = w.buf[index+3:]
+		return item, true
+	}
+	if nl < 0 {
+		return "", false
+	}
+	item := w.buf[:nl]
+	w.buf = w.buf[nl+1:]
+	return item, true
+}
+
+func (w *ProgressWriter) handle(item string) {
+	item = strings.TrimSpace(item)
+	if item == "" {
+		return
+	}
+	if items := regexProgressPercent.FindStringSubmatch(item); items != nil {
+		p, _ := strconv.Atoi(items[1])
+		w.percent = min(p, 100)
+		w.send(ProgressEvent_percent, "")
+		return
+	}
+	if items := regexProgressCode.FindStringSubmatch(item); items != nil {
+		w.errorCode = items[1] + " (" + items[2] + ")"
+		w.send(ProgressEvent_error, item)
+		return
+	}
+	if items := regexProgressState.FindStringSubmatch(item); items != nil {
+		if w.errorCode == "" {
+			w.errorCode = items[1]
+		}
+		w.send(ProgressEvent_error, item)
+		return
+	}
+	if strings.Contains(item, "error:") {
+		w.send(ProgressEvent_error, item)
+		return
+	}
+	w.phase = item
+	w.send(ProgressEvent_phase, "")
+}
+
+func (w *ProgressWriter) event(t ProgressEventType, errStr string) ProgressEvent {
+	ev := ProgressEvent{
+		Type:      t,
+		Percent:   w.percent,
+		Phase:     w.phase,
+		Elapsed:   time.Since(w.start),
+		ErrorCode: w.errorCode,
+		Error:     errStr,
+	}
+	if w.percent > 0 && w.percent < 100 {
+		ev.Remaining = time.Duration(float64(ev.Elapsed) * float64(100-w.percent) / float64(w.percent))
+	}
+	return ev
+}
+
+func (w *ProgressWriter) send(t ProgressEventType, errStr string) {
+	if w.callBack != nil {
+		w.pending = append(w.pending, w.event(t, errStr))
+	}
+}
+
+// calls the callback for the pending events - without holding lock
+func (w *ProgressWriter) deliver() {
+	w.lock.Lock()
+	events := w.pending
+	w.pending = nil
+	w.lock.Unlock()
+	for _, ev := range events {
+		w.callBack(ev)
+	}
+}
+
+// Sends the final event - normally called by the vm functions
+func (w *ProgressWriter) Finish(err error) {
+	w.lock.Lock()
+	if w.buf != "" {
+		w.handle(w.buf)
+		w.buf = ""
+	}
+	errStr := ""
+	if err != nil {
+		errStr = err.Error()
+	} else {
+		w.percent = 100
+	}
+	w.send(ProgressEvent_done, errStr)
+	w.lock.Unlock()
+	w.deliver()
+}
+
+func finishProgress(statusWriter io.Writer, err error) {
+	if w, ok := statusWriter.(*ProgressWriter); ok {
+		w.Finish(err)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package vm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestProgressWriter(t *testing.T) {
+	var events []ProgressEvent
+	w := NewProgressWriter(func(ev ProgressEvent) {
+		events = append(events, ev)
+	})
+	for _, chunk := range []string{
+		"Interpreting ../x.ova...\n",
+		"0%...1", "0%...\r",
+		"Progress state: VBOX_E_FILE_ERROR\n",
+		"VBoxManage: error: Appliance import failed\n",
+		"VBoxManage: error: Details: code VBOX_E_FILE_ERROR (0x80bb0004), component ApplianceWrap\n",
+		"20%",
+	} {
+		w.Write([]byte(chunk))
+	}
+	w.Finish(errors.New("exit status 1"))
+
+	want := []struct {
+		t       ProgressEventType
+		percent int
+		phase   string
+	}{
+		{ProgressEvent_phase, -1, "Interpreting ../x.ova..."},
+		{ProgressEvent_percent, 0, "Interpreting ../x.ova..."},
+		{ProgressEvent_percent, 10, "Interpreting ../x.ova..."},
+		{ProgressEvent_error, 10, "Interpreting ../x.ova..."},
+		{ProgressEvent_error, 10, "Interpreting ../x.ova..."},
+		{ProgressEvent_error, 10, "Interpreting ../x.ova..."},
+		{ProgressEvent_percent, 20, "Interpreting ../x.ova..."},
+		{ProgressEvent_done, 20, "Interpreting ../x.ova..."},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, ev := range events {
+		if ev.Type != want[i].t || ev.Percent != want[i].percent || ev.Phase != want[i].phase {
+			t.Errorf("event %d = %+v, want %+v", i, ev, want[i])
+		}
+	}
+	if ev := events[len(events)-1]; ev.ErrorCode != "VBOX_E_FILE_ERROR (0x80bb0004)" || ev.Error != "exit status 1" {
+		t.Errorf("done event = %+v", ev)
+	}
+}
+
+func TestProgressWriterFinish(t *testing.T) {
+	var last ProgressEvent
+	w := NewProgressWriter(func(ev ProgressEvent) {
+		last = ev
+	})
+	w.Write([]byte("0%...50%..."))
+	if last.Type != ProgressEvent_percent || last.Percent != 50 {
+		t.Errorf("event = %+v", last)
+	}
+	w.Finish(nil)
+	if last.Type != ProgressEvent_done || last.Percent != 100 || last.Error != "" || last.Remaining != 0 {
+		t.Errorf("done event = %+v", last)
+	}
+}
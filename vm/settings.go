@@ -0,0 +1,96 @@
+// Copyright (c) 2026 Reiner Pröls
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+//
+// Author: Reiner Pröls
+
+package vm
+
+import (
+	"encoding/xml"
+	"errors"
+	"os"
+	"strings"
+)
+
+// Parts of the .vbox settings file which are not available with showvminfo
+
+type xmlSnapshot struct {
+	UUID        string        `xml:"uuid,attr"`
+	TimeStamp   string        `xml:"timeStamp,attr"`
+	StateFile   string        `xml:"stateFile,attr"`
+	Description string        `xml:"Description"`
+	Children    []xmlSnapshot `xml:"Snapshots>Snapshot"`
+}
+
+type xmlAttachedDevice struct {
+	Port           int    `xml:"port,attr"`
+	Device         int    `xml:"device,attr"`
+	BandwidthGroup string `xml:"bandwidthGroup,attr"`
+}
+
+type xmlStorageController struct {
+	Name     string              `xml:"name,attr"`
+	Attached []xmlAttachedDevice `xml:"AttachedDevice"`
+}
+
+type xmlMachine struct {
+	CurrentStateModified string       `xml:"currentStateModified,attr"`
+	Snapshot             *xmlSnapshot `xml:"Snapshot"`
+	// older settings versions have the controllers outside of Hardware
+	StorageControllers         []xmlStorageController `xml:"StorageControllers>StorageController"`
+	HardwareStorageControllers []xmlStorageController `xml:"Hardware>StorageControllers>StorageController"`
+}
+
+type xmlVirtualBox struct {
+	Machine xmlMachine `xml:"Machine"`
+}
+
+func (x *xmlMachine) storageControllers() []xmlStorageController {
+	return append(x.HardwareStorageControllers, x.StorageControllers...)
+}
+
+func readSettingsFile(client *VmSshClient, cfgFile string) (*xmlVirtualBox, error) {
+	if cfgFile == "" {
+		return nil, errors.New("no settings file")
+	}
+	var data []byte
+	if client.IsLocal {
+		// no cat on Windows
+		b, err := os.ReadFile(cfgFile)
+		if err != nil {
+			return nil, err
+		}
+		data = b
+	} else {
+		lines, err := RunCmd(client, "cat", []string{cfgFile}, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		data = []byte(strings.Join(lines, "\n"))
+	}
+	var vbox xmlVirtualBox
+	err := xml.Unmarshal(data, &vbox)
+	if err != nil {
+		return nil, err
+	}
+	return &vbox, nil
+}
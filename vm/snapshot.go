@@ -25,21 +25,182 @@
 package vm
 
 import (
+	"errors"
+	"fmt"
 	"io"
+	"strings"
+	"time"
 )
 
+type SnapshotNode struct {
+	Name        string
+	UUID        string
+	Description string
+	// zero if unknown
+	TimeStamp time.Time
+	// taken from a running VM - includes the saved state
+	Online   bool
+	Parent   *SnapshotNode
+	Children []*SnapshotNode
+}
+
+type SnapshotTree struct {
+	// nil if the VM has no snapshots
+	Root *SnapshotNode
+	// the current state is a child of this snapshot - nil if the VM has no snapshots
+	Current *SnapshotNode
+	// the current state differs from the current snapshot
+	CurrentStateModified bool
+
+	nodes map[string]*SnapshotNode
+}
+
+// Tree from the last status update - without time stamps and online flags
+func NewSnapshotTree(config ConfigSnapshots) *SnapshotTree {
+	t := SnapshotTree{
+		nodes: make(map[string]*SnapshotNode, 10),
+	}
+	t.Root = t.addNode(config.Root, nil)
+	t.Current = t.nodes[config.CurrentUUID]
+	return &t
+}
+
+func (t *SnapshotTree) addNode(c *ConfigSnapshot, parent *SnapshotNode) *SnapshotNode {
+	if c == nil {
+		return nil
+	}
+	n := &SnapshotNode{
+		Name:        c.Name,
+		UUID:        c.UUID,
+		Description: c.Description,
+		Parent:      parent,
+	}
+	t.nodes[n.UUID] = n
+	for _, child := range c.Children {
+		n.Children = append(n.Children, t.addNode(child, n))
+	}
+	return n
+}
+
+// Tree with details read from the settings file of the VM
+func (m *VMachine) SnapshotTree(client *VmSshClient) (*SnapshotTree, error) {
+	config := m.Config()
+	t := NewSnapshotTree(config.Snapshots)
+	if t.Root == nil {
+		return t, nil
+	}
+	return t, t.LoadDetails(client, config.General.CfgFile)
+}
+
+func (t *SnapshotTree) Find(uuid string) *SnapshotNode {
+	return t.nodes[uuid]
+}
+
+func (t *SnapshotTree) Len() int {
+	return len(t.nodes)
+}
+
+// depth first, parents before children
+func (t *SnapshotTree) Walk(f func(node *SnapshotNode)) {
+	t.Root.Walk(f)
+}
+
+func (n *SnapshotNode) Walk(f func(node *SnapshotNode)) {
+	if n == nil {
+		return
+	}
+	f(n)
+	for _, child := range n.Children {
+		child.Walk(f)
+	}
+}
+
+// from the root down to n
+func (n *SnapshotNode) Path() []*SnapshotNode {
+	var path []*SnapshotNode
+	for p := n; p != nil; p = p.Parent {
+		path = append([]*SnapshotNode{p}, path...)
+	}
+	return path
+}
+
+func (n *SnapshotNode) IsAncestorOf(o *SnapshotNode) bool {
+	for p := o.Parent; p != nil; p = p.Parent {
+		if p == n {
+			return true
+		}
+	}
+	return false
+}
+
+// Snapshots from down to to (both included) - to must be a descendant of from
+func (t *SnapshotTree) Range(fromUUID, toUUID string) ([]*SnapshotNode, error) {
+	from := t.nodes[fromUUID]
+	to := t.nodes[toUUID]
+	if from == nil || to == nil {
+		return nil, errors.New("unknown snapshot")
+	}
+	if from != to && !from.IsAncestorOf(to) {
+		return nil, errors.New("snapshots are not on the same branch")
+	}
+	var list []*SnapshotNode
+	for p := to; p != from; p = p.Parent {
+		list = append([]*SnapshotNode{p}, list...)
+	}
+	return append([]*SnapshotNode{from}, list...), nil
+}
+
+// A snapshot with more than one child can not be deleted
+func (t *SnapshotTree) CanDeleteRange(fromUUID, toUUID string) error {
+	list, err := t.Range(fromUUID, toUUID)
+	if err != nil {
+		return err
+	}
+	for _, n := range list {
+		if len(n.Children) > 1 {
+			return fmt.Errorf("snapshot '%s' has more than one child", n.Name)
+		}
+	}
+	return nil
+}
+
+// Time stamps and online flags are only stored in the .vbox file
+func (t *SnapshotTree) LoadDetails(client *VmSshClient, cfgFile string) error {
+	vbox, err := readSettingsFile(client, cfgFile)
+	if err != nil {
+		return err
+	}
+	t.CurrentStateModified = vbox.Machine.CurrentStateModified == "true"
+	if vbox.Machine.Snapshot != nil {
+		t.applyDetails(vbox.Machine.Snapshot)
+	}
+	return nil
+}
+
+func (t *SnapshotTree) applyDetails(x *xmlSnapshot) {
+	n := t.nodes[strings.Trim(x.UUID, "{}")]
+	if n != nil {
+		ts, err := time.Parse(time.RFC3339, x.TimeStamp)
+		if err == nil {
+			n.TimeStamp = ts
+		}
+		n.Online = x.StateFile != ""
+	}
+	for i := range x.Children {
+		t.applyDetails(&x.Children[i])
+	}
+}
+
 func (m *VMachine) TakeSnapshot(client *VmSshClient, name, description string, live bool, statusWriter io.Writer) error {
-	opt := []string{"snapshot", m.UUID, "take", client.quoteArgString(name)}
+	opt := []string{"snapshot", m.UUID, "take", name}
 	if description != "" {
-		if !client.IsLocal {
-			description = "$'" + description + "'"
-		}
-		opt = append(opt, description)
+		opt = append(opt, "--description="+description)
 	}
 	if live {
 		opt = append(opt, "--live")
 	}
 	lines, err := RunCmd(client, VBOXMANAGE_APP, opt, nil, statusWriter)
+	finishProgress(statusWriter, err)
 	if err != nil {
 		m.addLogEntry(lines, false)
 	}
@@ -47,8 +208,9 @@ func (m *VMachine) TakeSnapshot(client *VmSshClient, name, description string, l
 }
 
 func (m *VMachine) DeleteSnapshot(client *VmSshClient, uuid string, statusWriter io.Writer) error {
-	opt := []string{"snapshot", m.UUID, "delete", client.quoteArgString(uuid)}
+	opt := []string{"snapshot", m.UUID, "delete", uuid}
 	lines, err := RunCmd(client, VBOXMANAGE_APP, opt, nil, statusWriter)
+	finishProgress(statusWriter, err)
 	if err != nil {
 		m.addLogEntry(lines, false)
 	}
@@ -56,10 +218,51 @@ func (m *VMachine) DeleteSnapshot(client *VmSshClient, uuid string, statusWriter
 }
 
 func (m *VMachine) RestoreSnapshot(client *VmSshClient, uuid string, statusWriter io.Writer) error {
-	opt := []string{"snapshot", m.UUID, "restore", client.quoteArgString(uuid)}
+	opt := []string{"snapshot", m.UUID, "restore", uuid}
 	lines, err := RunCmd(client, VBOXMANAGE_APP, opt, nil, statusWriter)
+	finishProgress(statusWriter, err)
 	if err != nil {
 		m.addLogEntry(lines, false)
 	}
 	return err
 }
+
+func (m *VMachine) EditSnapshot(client *VmSshClient, uuid, name, description string) error {
+	opt := []string{"snapshot", m.UUID, "edit", uuid, "--name=" + name, "--description=" + description}
+	lines, err := RunCmd(client, VBOXMANAGE_APP, opt, nil, nil)
+	if err != nil {
+		m.addLogEntry(lines, false)
+	}
+	return err
+}
+
+// Back to the current snapshot - discards the current state
+func (m *VMachine) RestoreCurrentSnapshot(client *VmSshClient, statusWriter io.Writer) error {
+	opt := []string{"snapshot", m.UUID, "restorecurrent"}
+	lines, err := RunCmd(client, VBOXMANAGE_APP, opt, nil, statusWriter)
+	finishProgress(statusWriter, err)
+	if err != nil {
+		m.addLogEntry(lines, false)
+	}
+	return err
+}
+
+// Deletes the snapshots from down to to - stops at the first error
+func (m *VMachine) DeleteSnapshotRange(client *VmSshClient, t *SnapshotTree, fromUUID, toUUID string, statusWriter io.Writer) error {
+	err := t.CanDeleteRange(fromUUID, toUUID)
+	if err != nil {
+		return err
+	}
+	list, _ := t.Range(fromUUID, toUUID)
+	for _, n := range list {
+		opt := []string{"snapshot", m.UUID, "delete", n.UUID}
+		lines, err := RunCmd(client, VBOXMANAGE_APP, opt, nil, statusWriter)
+		if err != nil {
+			finishProgress(statusWriter, err)
+			m.addLogEntry(lines, false)
+			return fmt.Errorf("snapshot '%s': %w", n.Name, err)
+		}
+	}
+	finishProgress(statusWriter, nil)
+	return nil
+}
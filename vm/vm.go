@@ -25,9 +25,12 @@
 package vm
 
 import (
+	"context"
 	"errors"
 	"io"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"bytemystery-com/vboxssh/run"
 
@@ -40,6 +43,8 @@ const (
 	MAX_LOG_ENTRIES             = 25
 	DEBUG                       = false
 	EXTRADATA_STARTINWINDOW_KEY = "/user/.vboxssh/startinwindow"
+	// a hanging poll (locked session) must not block forever
+	BACKGROUND_CMD_TIMEOUT = 60 * time.Second
 )
 
 type RunState int
@@ -215,8 +220,13 @@ type VMachine struct {
 	Name       string
 	UUID       string
 	Properties map[string]string
+	config     atomic.Pointer[VMConfig]
 	lock       *sync.RWMutex
 	logBuffer  [][]string
+	// last state of the bulk listing
+	listState VMListState
+	// nil if not running or no guest additions
+	guestInfo atomic.Pointer[GuestInfo]
 }
 
 type NicAdapter struct {
@@ -422,31 +432,124 @@ const (
 	StartInWindow_no
 )
 
+type NatProtocolType int
+
+const (
+	NatProtocol_tcp NatProtocolType = iota
+	NatProtocol_udp
+)
+
+type BandwidthGroupType int
+
+const (
+	BandwidthGroup_disk BandwidthGroupType = iota
+	BandwidthGroup_network
+)
+
+type GuestPropertyFlagType int
+
+const (
+	GuestPropertyFlag_transient GuestPropertyFlagType = iota
+	GuestPropertyFlag_transreset
+	GuestPropertyFlag_rdonlyguest
+	GuestPropertyFlag_rdonlyhost
+	GuestPropertyFlag_readonly
+)
+
+type PlatformArchType int
+
+const (
+	PlatformArch_default PlatformArchType = iota
+	PlatformArch_x86
+	PlatformArch_arm
+)
+
 type VmSshClient struct {
 	Client  *ssh.Client
 	IsLocal bool
+	// polling waits behind user actions for a free session
+	Background bool
+	// per call - 0 means no timeout
+	Timeout  time.Duration
+	ctx      context.Context
+	limiter  *run.SessionLimiter
+	config   *CmdConfig
+	executor *executorRef
+	// for the audit log
+	address string
+	// written to the stdin of the commands - a secret which is neither
+	// recorded nor audited
+	secret string
+	// shared by all copies - guards Client of the server which is replaced
+	// by the health monitor after a reconnect
+	clientLock *sync.RWMutex
+}
+
+func (s *VmSshClient) copy() VmSshClient {
+	if s.clientLock != nil {
+		s.clientLock.RLock()
+		defer s.clientLock.RUnlock()
+	}
+	return *s
 }
 
-func (s *VmSshClient) quoteArgString(arg string) string {
-	if s.IsLocal {
-		return arg
+func (s *VmSshClient) sshClient() *ssh.Client {
+	if s.clientLock != nil {
+		s.clientLock.RLock()
+		defer s.clientLock.RUnlock()
 	}
-	return "'" + arg + "'"
+	return s.Client
+}
+
+// Copy of the client whose commands are cancelled with ctx
+func (s *VmSshClient) WithContext(ctx context.Context) *VmSshClient {
+	c := s.copy()
+	c.ctx = ctx
+	return &c
+}
+
+// Copy of the client with a timeout for each command
+func (s *VmSshClient) WithTimeout(timeout time.Duration) *VmSshClient {
+	c := s.copy()
+	c.Timeout = timeout
+	return &c
+}
+
+// Copy of the client which passes secret via stdin - for options like
+// --passwordfile stdin which keep the secret off the command line
+func (s *VmSshClient) WithSecret(secret string) *VmSshClient {
+	c := s.copy()
+	c.secret = secret
+	return &c
+}
+
+func (s *VmSshClient) context() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
 }
 
 func RunCmd(client *VmSshClient, cmd string, args []string, userWriterOut, userWriterErr io.Writer) ([]string, error) {
 	if client == nil {
 		return nil, errors.New("null pointer as client")
 	}
-	var lines []string
-	var err error
-	if client.IsLocal {
-		lines, err = run.RunLocalCmd(cmd, args, userWriterOut, userWriterErr)
-	} else if client.Client != nil {
-		lines, err = run.RunSshCmd(client.Client, cmd, args, userWriterOut, userWriterErr)
-	} else {
-		return nil, errors.New("ssh client is null")
-	}
+	return RunCmdContext(client.context(), client, cmd, args, userWriterOut, userWriterErr)
+}
 
+// The timeout of the client is applied to ctx. The time waiting for a
+// free session counts.
+func RunCmdContext(ctx context.Context, client *VmSshClient, cmd string, args []string, userWriterOut, userWriterErr io.Writer) ([]string, error) {
+	if client == nil {
+		return nil, errors.New("null pointer as client")
+	}
+	ctx, cancel := run.WithTimeout(ctx, client.Timeout)
+	defer cancel()
+	start := time.Now()
+	e := client.executor.get()
+	lines, err := e.Run(ctx, client, cmd, args, userWriterOut, userWriterErr)
+	if isAuditable(e, cmd, args) {
+		auditCmd(client, cmd, args, start, lines, err)
+	}
 	return lines, err
 }
@@ -26,7 +26,6 @@ package vm
 
 import (
 	"errors"
-	"fmt"
 	"io"
 	"maps"
 	"path"
@@ -34,15 +33,19 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 
+	"bytemystery-com/vboxssh/run"
 	"bytemystery-com/vboxssh/server"
 
 	"github.com/google/uuid"
+	"golang.org/x/crypto/ssh"
 )
 
 var (
 	regexNicName = regexp.MustCompile(`^Name:\s*(.*)`)
 
+	regexUsbHeader       = regexp.MustCompile(`^Host USB Devices:`)
 	regexUsbUUID         = regexp.MustCompile(`^UUID:\s*([0-9a-fA-F-]*)`)
 	regexUsbProduct      = regexp.MustCompile(`^Product:\s*(.*)`)
 	regexUsbManufacturer = regexp.MustCompile(`^Manufacturer:\s*(.*)`)
@@ -87,6 +90,7 @@ var (
 	regexSystemProperties = regexp.MustCompile(`^(.*):\s*(.*)`)
 	regexHostInfos        = regexp.MustCompile(`^(.*):\s*(.*)`)
 
+	regexExtPackHeader = regexp.MustCompile(`^Extension Packs:\s*[0-9]+`)
 	// Pack no. 0
 	regexExtPackNr          = regexp.MustCompile(`^Pack no\.\s+[0-9]+:\s+(.*)`)
 	regexExtPackVersion     = regexp.MustCompile(`^Version:\s+(.*)`)
@@ -95,8 +99,6 @@ var (
 	regexExtPackWhyUnUsable = regexp.MustCompile(`^Why unusable:\s+(.*)`)
 
 	regexImportDryRunVsys = regexp.MustCompile(`^Virtual system\s+([0-9]+):`)
-
-	regexVersion = regexp.MustCompile(`^([0-9]+)\.([0-9]+)`)
 )
 
 type VmServer struct {
@@ -119,6 +121,11 @@ type VmServer struct {
 	DvdImagesPath    string `json:"isopath"`
 	HddImagesPath    string `json:"vdipath"`
 	OvaPath          string `json:"ovapath"`
+
+	CmdConfig CmdConfig `json:"cmdconfig"`
+
+	monitor      *connMonitor
+	capabilities *capabilityCache
 }
 
 func NewVmServer(s server.Server) VmServer {
@@ -126,33 +133,39 @@ func NewVmServer(s server.Server) VmServer {
 		Server:           s,
 		UUID:             uuid.NewString(),
 		SystemProperties: make(map[string]string, 120),
+		monitor:          &connMonitor{},
+		capabilities:     &capabilityCache{},
 	}
 	v.Client.IsLocal = v.IsLocal()
+	v.Client.limiter = run.NewSessionLimiter(s.MaxSessions)
+	v.Client.config = &CmdConfig{}
+	v.Client.executor = &executorRef{}
+	v.Client.clientLock = &sync.RWMutex{}
+	v.Client.address = v.AuditAddress()
 	return v
 }
 
-func (v *VmServer) GetVmMajorVersion() (int, error) {
-	maj, _, err := v.getVmVersion()
-	return maj, err
+// Copy of the client for polling - shares the session limit
+func (v *VmServer) BackgroundClient() *VmSshClient {
+	c := v.Client.copy()
+	c.Background = true
+	c.Timeout = BACKGROUND_CMD_TIMEOUT
+	return &c
 }
 
-func (v *VmServer) getVmVersion() (int, int, error) {
-	if v.Version == "" {
-		return 0, 0, errors.New("No version")
+func (v *VmServer) SetMaxSessions(max int) {
+	v.MaxSessions = max
+	if v.Client.limiter != nil {
+		v.Client.limiter.SetMax(max)
 	}
-	items := regexVersion.FindStringSubmatch(v.Version)
-	if len(items) == 3 {
-		maj, err := strconv.Atoi(items[1])
-		if err != nil {
-			return 0, 0, errors.New("Parse error")
-		}
-		min, err := strconv.Atoi(items[2])
-		if err != nil {
-			return 0, 0, errors.New("Parse error")
-		}
-		return maj, min, nil
+}
+
+// active sessions, waiting calls and the maximum
+func (v *VmServer) GetSessionStats() (int, int, int) {
+	if v.Client.limiter == nil {
+		return 0, 0, 0
 	}
-	return 0, 0, errors.New("Unknown version")
+	return v.Client.limiter.Stats()
 }
 
 func (v *VmServer) Connect(fOk func(), fErr func(error)) error {
@@ -170,15 +183,23 @@ func (v *VmServer) Connect(fOk func(), fErr func(error)) error {
 		}
 		return nil
 	}
+	v.stopMonitor()
+	// the address may have been changed
+	v.Client.address = v.AuditAddress()
 	client, err := v.Server.Connect()
 	if err != nil {
+		v.setConnState(ConnState_offline, err)
 		if fErr != nil {
 			fErr(err)
 		}
 		return err
 	}
 
-	v.Client.Client = client
+	// a client published by the monitor in the meantime
+	if old := v.swapClient(client); old != nil && old != client {
+		v.Server.Disonnect(&old)
+	}
+	v.startMonitor(client)
 	version, err := v.GetVersion()
 	if err != nil {
 		if fErr != nil {
@@ -204,12 +225,28 @@ func (v *VmServer) IsConnected() bool {
 	if v.IsLocal() {
 		return true
 	}
-	if v.Client.Client != nil {
+	if v.SshClient() != nil {
 		return true
 	}
 	return false
 }
 
+// The current ssh client - nil if not connected
+func (v *VmServer) SshClient() *ssh.Client {
+	return v.Client.sshClient()
+}
+
+// Replaces the ssh client - returns the old one
+func (v *VmServer) swapClient(client *ssh.Client) *ssh.Client {
+	if v.Client.clientLock != nil {
+		v.Client.clientLock.Lock()
+		defer v.Client.clientLock.Unlock()
+	}
+	old := v.Client.Client
+	v.Client.Client = client
+	return old
+}
+
 // Version
 func (s *VmServer) GetVersion() (string, error) {
 	lines, err := RunCmd(&s.Client, VBOXMANAGE_APP, []string{"--version"}, nil, nil)
@@ -354,74 +391,78 @@ func (s *VmServer) GetUsbDevices() ([]UsbDevice, error) {
 		return nil, err
 	}
 	usb := []UsbDevice{}
-	usbDevice := UsbDevice{}
-	for _, line := range lines {
-		if line == "" {
-			if usbDevice.UUID != "" {
-				usb = append(usb, usbDevice)
+	c := parseCollector{}
+	parseRecords(lines, &c, func(record []string) bool {
+		// Host USB Devices:
+		if regexUsbHeader.MatchString(record[0]) {
+			record = record[1:]
+		}
+		if len(record) == 0 || (len(record) == 1 && strings.TrimSpace(record[0]) == "<none>") {
+			return true
+		}
+		usbDevice := UsbDevice{}
+		for _, line := range record {
+			items := regexUsbUUID.FindStringSubmatch(line)
+			if len(items) == 2 {
+				usbDevice.UUID = items[1]
+				continue
 			}
-			usbDevice = UsbDevice{}
-			continue
-		}
-		items := regexUsbUUID.FindStringSubmatch(line)
-		if len(items) == 2 {
-			usb = append(usb, UsbDevice{
-				UUID: items[1],
-			})
-			continue
-		}
-		items = regexUsbManufacturer.FindStringSubmatch(line)
-		if len(items) == 2 {
-			usb[len(usb)-1].Manufacturer = items[1]
-			if len(usb[len(usb)-1].Name) > 0 {
-				usb[len(usb)-1].Name = items[1] + "-" + usb[len(usb)-1].Name
-			} else {
-				usb[len(usb)-1].Name = items[1]
+			items = regexUsbManufacturer.FindStringSubmatch(line)
+			if len(items) == 2 {
+				usbDevice.Manufacturer = items[1]
+				if len(usbDevice.Name) > 0 {
+					usbDevice.Name = items[1] + "-" + usbDevice.Name
+				} else {
+					usbDevice.Name = items[1]
+				}
+				continue
 			}
-			continue
-		}
 
-		items = regexUsbProduct.FindStringSubmatch(line)
-		if len(items) == 2 {
-			usb[len(usb)-1].Product = items[1]
-			if len(usb[len(usb)-1].Name) > 0 {
-				usb[len(usb)-1].Name += "-" + items[1]
-			} else {
-				usb[len(usb)-1].Name = items[1]
+			items = regexUsbProduct.FindStringSubmatch(line)
+			if len(items) == 2 {
+				usbDevice.Product = items[1]
+				if len(usbDevice.Name) > 0 {
+					usbDevice.Name += "-" + items[1]
+				} else {
+					usbDevice.Name = items[1]
+				}
+				continue
 			}
-			continue
-		}
-		// ProductId
-		items = regexUsbProductId.FindStringSubmatch(line)
-		if len(items) == 2 {
-			usb[len(usb)-1].ProductId = items[1]
-			continue
-		}
-		// VendorId
-		items = regexUsbVendorId.FindStringSubmatch(line)
-		if len(items) == 2 {
-			usb[len(usb)-1].VendorId = items[1]
-			continue
-		}
-		// Port
-		items = regexUsbPort.FindStringSubmatch(line)
-		if len(items) == 2 {
-			p, err := strconv.Atoi(items[1])
-			if err == nil {
-				usb[len(usb)-1].Port = p
+			// ProductId
+			items = regexUsbProductId.FindStringSubmatch(line)
+			if len(items) == 2 {
+				usbDevice.ProductId = items[1]
+				continue
+			}
+			// VendorId
+			items = regexUsbVendorId.FindStringSubmatch(line)
+			if len(items) == 2 {
+				usbDevice.VendorId = items[1]
+				continue
+			}
+			// Port
+			items = regexUsbPort.FindStringSubmatch(line)
+			if len(items) == 2 {
+				p, err := strconv.Atoi(items[1])
+				if err == nil {
+					usbDevice.Port = p
+				}
+				continue
+			}
+			// SerialNumber
+			items = regexUsbSerialNumber.FindStringSubmatch(line)
+			if len(items) == 2 {
+				usbDevice.SerialNumber = items[1]
+				continue
 			}
-			continue
 		}
-		// SerialNumber
-		items = regexUsbSerialNumber.FindStringSubmatch(line)
-		if len(items) == 2 {
-			usb[len(usb)-1].SerialNumber = items[1]
-			continue
+		if usbDevice.UUID == "" {
+			return false
 		}
-	}
-	if usbDevice.UUID != "" {
 		usb = append(usb, usbDevice)
-	}
+		return true
+	})
+	c.report(&s.Client, []string{"list", "usbhost"})
 	return usb, nil
 }
 
@@ -432,59 +473,57 @@ func (s *VmServer) GetDvdMedias() ([]DvdInfo, error) {
 		return nil, err
 	}
 	dvds := []DvdInfo{}
-	dvd := DvdInfo{}
-	inUsed := false
-	for _, line := range lines {
-		if line == "" {
-			if dvd.MediaInfo.UUID != "" {
-				dvds = append(dvds, dvd)
-			}
-			dvd = DvdInfo{}
-			inUsed = false
-			continue
-		}
-		if inUsed {
-			items := regexDvdUsed2.FindStringSubmatch(line)
-			if len(items) == 2 {
-				dvd.UsedBy = append(dvd.UsedBy, items[1])
-				continue
-			}
-		} else {
-			items := regexDvdUUID.FindStringSubmatch(line)
-			if len(items) == 2 {
-				dvd = DvdInfo{
-					MediaInfo{
-						UUID: items[1],
-					},
+	c := parseCollector{}
+	parseRecords(lines, &c, func(record []string) bool {
+		dvd := DvdInfo{}
+		inUsed := false
+		for _, line := range record {
+			if inUsed {
+				items := regexDvdUsed2.FindStringSubmatch(line)
+				if len(items) == 2 {
+					dvd.UsedBy = append(dvd.UsedBy, items[1])
+					continue
 				}
-				continue
-			}
-			items = regexDvdState.FindStringSubmatch(line)
-			if len(items) == 2 {
-				if items[1] == "created" {
-					dvd.State = MediaState_created
+			} else {
+				items := regexDvdUUID.FindStringSubmatch(line)
+				if len(items) == 2 {
+					dvd = DvdInfo{
+						MediaInfo{
+							UUID: items[1],
+						},
+					}
+					continue
 				}
-				continue
-			}
-			items = regexDvdUsed1.FindStringSubmatch(line)
-			if len(items) == 2 {
-				items = regexDvdUsed2.FindStringSubmatch(items[1])
+				items = regexDvdState.FindStringSubmatch(line)
 				if len(items) == 2 {
-					dvd.UsedBy = append(dvd.UsedBy, items[1])
-					inUsed = true
+					if items[1] == "created" {
+						dvd.State = MediaState_created
+					}
+					continue
+				}
+				items = regexDvdUsed1.FindStringSubmatch(line)
+				if len(items) == 2 {
+					items = regexDvdUsed2.FindStringSubmatch(items[1])
+					if len(items) == 2 {
+						dvd.UsedBy = append(dvd.UsedBy, items[1])
+						inUsed = true
+					}
+					continue
+				}
+				items = regexDvdLocation.FindStringSubmatch(line)
+				if len(items) == 2 {
+					dvd.Location = items[1]
+					continue
 				}
-				continue
-			}
-			items = regexDvdLocation.FindStringSubmatch(line)
-			if len(items) == 2 {
-				dvd.Location = items[1]
-				continue
 			}
 		}
-	}
-	if dvd.UUID != "" {
+		if dvd.UUID == "" {
+			return false
+		}
 		dvds = append(dvds, dvd)
-	}
+		return true
+	})
+	c.report(&s.Client, []string{"list", "--long", "dvds"})
 	slices.SortFunc(dvds, func(a, b DvdInfo) int {
 		A := path.Base(a.Location)
 		B := path.Base(b.Location)
@@ -515,59 +554,57 @@ func (s *VmServer) GetFloppyMedias() ([]FloppyInfo, error) {
 		return nil, err
 	}
 	floppies := []FloppyInfo{}
-	floppy := FloppyInfo{}
-	inUsed := false
-	for _, line := range lines {
-		if line == "" {
-			if floppy.MediaInfo.UUID != "" {
-				floppies = append(floppies, floppy)
-			}
-			floppy = FloppyInfo{}
-			inUsed = false
-			continue
-		}
-		if inUsed {
-			items := regexFloppyUsed2.FindStringSubmatch(line)
-			if len(items) == 2 {
-				floppy.UsedBy = append(floppy.UsedBy, items[1])
-				continue
-			}
-		} else {
-			items := regexFloppyUUID.FindStringSubmatch(line)
-			if len(items) == 2 {
-				floppy = FloppyInfo{
-					MediaInfo{
-						UUID: items[1],
-					},
+	c := parseCollector{}
+	parseRecords(lines, &c, func(record []string) bool {
+		floppy := FloppyInfo{}
+		inUsed := false
+		for _, line := range record {
+			if inUsed {
+				items := regexFloppyUsed2.FindStringSubmatch(line)
+				if len(items) == 2 {
+					floppy.UsedBy = append(floppy.UsedBy, items[1])
+					continue
 				}
-				continue
-			}
-			items = regexFloppyState.FindStringSubmatch(line)
-			if len(items) == 2 {
-				if items[1] == "created" {
-					floppy.State = MediaState_created
+			} else {
+				items := regexFloppyUUID.FindStringSubmatch(line)
+				if len(items) == 2 {
+					floppy = FloppyInfo{
+						MediaInfo{
+							UUID: items[1],
+						},
+					}
+					continue
 				}
-				continue
-			}
-			items = regexFloppyUsed1.FindStringSubmatch(line)
-			if len(items) == 2 {
-				items = regexFloppyUsed2.FindStringSubmatch(items[1])
+				items = regexFloppyState.FindStringSubmatch(line)
 				if len(items) == 2 {
-					floppy.UsedBy = append(floppy.UsedBy, items[1])
-					inUsed = true
+					if items[1] == "created" {
+						floppy.State = MediaState_created
+					}
+					continue
+				}
+				items = regexFloppyUsed1.FindStringSubmatch(line)
+				if len(items) == 2 {
+					items = regexFloppyUsed2.FindStringSubmatch(items[1])
+					if len(items) == 2 {
+						floppy.UsedBy = append(floppy.UsedBy, items[1])
+						inUsed = true
+					}
+					continue
+				}
+				items = regexFloppyLocation.FindStringSubmatch(line)
+				if len(items) == 2 {
+					floppy.Location = items[1]
+					continue
 				}
-				continue
-			}
-			items = regexFloppyLocation.FindStringSubmatch(line)
-			if len(items) == 2 {
-				floppy.Location = items[1]
-				continue
 			}
 		}
-	}
-	if floppy.UUID != "" {
+		if floppy.UUID == "" {
+			return false
+		}
 		floppies = append(floppies, floppy)
-	}
+		return true
+	})
+	c.report(&s.Client, []string{"list", "--long", "floppies"})
 
 	slices.SortFunc(floppies, func(a, b FloppyInfo) int {
 		A := path.Base(a.Location)
@@ -599,20 +636,47 @@ func (s *VmServer) GetHddMedias() ([]*HddInfo, map[string]*HddInfo, error) {
 		return nil, nil, err
 	}
 	hdds := []*HddInfo{}
-	hdd := &HddInfo{}
-	inUsed := false
-	for _, line := range lines {
-		if line == "" {
-			if hdd.UUID != "" {
-				hdds = append(hdds, hdd)
+	c := parseCollector{}
+	parseRecords(lines, &c, func(record []string) bool {
+		hdd := &HddInfo{}
+		inUsed := false
+		for _, line := range record {
+			if inUsed {
+				if regexStartWithSpace.MatchString(line) {
+					items := regexHddUsed2.FindStringSubmatch(line)
+					if len(items) == regexHddUsed2.NumSubexp()+1 {
+						u := &UsedByInfo{
+							UUID: items[1],
+						}
+						items2 := regexHddUsed3.FindStringSubmatch(items[2])
+						if len(items2) == regexHddUsed3.NumSubexp()+1 {
+							u.SnapshotDescription = items2[1]
+							u.SnapshotUUID = items2[2]
+						}
+						hdd.UsedBy = append(hdd.UsedBy, u)
+					}
+					continue
+				} else {
+					inUsed = false
+				}
 			}
-			hdd = &HddInfo{}
-			inUsed = false
-			continue
-		}
-		if inUsed {
-			if regexStartWithSpace.MatchString(line) {
-				items := regexHddUsed2.FindStringSubmatch(line)
+			items := regexHddUUID.FindStringSubmatch(line)
+			if len(items) == 2 {
+				hdd = &HddInfo{
+					UUID: items[1],
+				}
+				continue
+			}
+			items = regexHddState.FindStringSubmatch(line)
+			if len(items) == 2 {
+				if items[1] == "created" {
+					hdd.State = MediaState_created
+				}
+				continue
+			}
+			items = regexHddUsed1.FindStringSubmatch(line)
+			if len(items) == regexHddUsed1.NumSubexp()+1 {
+				items = regexHddUsed2.FindStringSubmatch(items[1])
 				if len(items) == regexHddUsed2.NumSubexp()+1 {
 					u := &UsedByInfo{
 						UUID: items[1],
@@ -623,57 +687,27 @@ func (s *VmServer) GetHddMedias() ([]*HddInfo, map[string]*HddInfo, error) {
 						u.SnapshotUUID = items2[2]
 					}
 					hdd.UsedBy = append(hdd.UsedBy, u)
+					inUsed = true
 				}
 				continue
-			} else {
-				inUsed = false
 			}
-		}
-		items := regexHddUUID.FindStringSubmatch(line)
-		if len(items) == 2 {
-			hdd = &HddInfo{
-				UUID: items[1],
-			}
-			continue
-		}
-		items = regexHddState.FindStringSubmatch(line)
-		if len(items) == 2 {
-			if items[1] == "created" {
-				hdd.State = MediaState_created
+			items = regexHddLocation.FindStringSubmatch(line)
+			if len(items) == 2 {
+				hdd.Location = items[1]
+				continue
 			}
-			continue
-		}
-		items = regexHddUsed1.FindStringSubmatch(line)
-		if len(items) == regexHddUsed1.NumSubexp()+1 {
-			items = regexHddUsed2.FindStringSubmatch(items[1])
-			if len(items) == regexHddUsed2.NumSubexp()+1 {
-				u := &UsedByInfo{
-					UUID: items[1],
-				}
-				items2 := regexHddUsed3.FindStringSubmatch(items[2])
-				if len(items2) == regexHddUsed3.NumSubexp()+1 {
-					u.SnapshotDescription = items2[1]
-					u.SnapshotUUID = items2[2]
-				}
-				hdd.UsedBy = append(hdd.UsedBy, u)
-				inUsed = true
+			items = regexHddParentUUID.FindStringSubmatch(line)
+			if len(items) == 2 {
+				hdd.Parent = items[1]
+				continue
 			}
-			continue
-		}
-		items = regexHddLocation.FindStringSubmatch(line)
-		if len(items) == 2 {
-			hdd.Location = items[1]
-			continue
 		}
-		items = regexHddParentUUID.FindStringSubmatch(line)
-		if len(items) == 2 {
-			hdd.Parent = items[1]
-			continue
+		if hdd.UUID == "" {
+			return false
 		}
-	}
-	if hdd.UUID != "" {
 		hdds = append(hdds, hdd)
-	}
+		return true
+	})
 
 	m := make(map[string]*HddInfo, len(hdds))
 	for _, item := range hdds {
@@ -689,7 +723,7 @@ func (s *VmServer) GetHddMedias() ([]*HddInfo, map[string]*HddInfo, error) {
 			if ok {
 				item2.Childs = append(item2.Childs, item)
 			} else {
-				fmt.Printf("!!! Something went wrong !!!")
+				c.unparsed("Parent UUID: " + item.Parent + " of " + item.UUID + " not found")
 			}
 		}
 	}
@@ -715,6 +749,7 @@ func (s *VmServer) GetHddMedias() ([]*HddInfo, map[string]*HddInfo, error) {
 		return 1
 	})
 
+	c.report(&s.Client, []string{"list", "--long", "hdds"})
 	return l, m, nil
 }
 
@@ -728,54 +763,53 @@ func (s *VmServer) GetOsTypes(update bool) ([]*OsType, error) {
 		return nil, err
 	}
 	ostypes := []*OsType{}
-	ostype := new(OsType)
-	for _, line := range lines {
-		if line == "" {
-			if ostype.ID != "" {
-				ostypes = append(ostypes, ostype)
+	c := parseCollector{}
+	parseRecords(lines, &c, func(record []string) bool {
+		ostype := new(OsType)
+		for _, line := range record {
+			items := regexOsId.FindStringSubmatch(line)
+			if len(items) == 2 {
+				ostype.ID = items[1]
+				continue
+			}
+			items = regexOsDescription.FindStringSubmatch(line)
+			if len(items) == 2 {
+				ostype.Name = items[1]
+				continue
+			}
+			items = regexOsFamilyId.FindStringSubmatch(line)
+			if len(items) == 2 {
+				ostype.FamilyId = items[1]
+				continue
+			}
+			items = regexOsFamilyDescription.FindStringSubmatch(line)
+			if len(items) == 2 {
+				ostype.Family = items[1]
+				continue
+			}
+			items = regexOsArchitecture.FindStringSubmatch(line)
+			if len(items) == 2 {
+				ostype.Architecture = items[1]
+				continue
+			}
+			items = regexOsSubType.FindStringSubmatch(line)
+			if len(items) == 2 {
+				ostype.Subtype = items[1]
+				continue
+			}
+			items = regexOs64Bit.FindStringSubmatch(line)
+			if len(items) == 2 {
+				ostype.Is64Bit, _ = strconv.ParseBool(items[1])
+				continue
 			}
-			ostype = new(OsType)
-			continue
-		}
-		items := regexOsId.FindStringSubmatch(line)
-		if len(items) == 2 {
-			ostype.ID = items[1]
-			continue
-		}
-		items = regexOsDescription.FindStringSubmatch(line)
-		if len(items) == 2 {
-			ostype.Name = items[1]
-			continue
-		}
-		items = regexOsFamilyId.FindStringSubmatch(line)
-		if len(items) == 2 {
-			ostype.FamilyId = items[1]
-			continue
-		}
-		items = regexOsFamilyDescription.FindStringSubmatch(line)
-		if len(items) == 2 {
-			ostype.Family = items[1]
-			continue
-		}
-		items = regexOsArchitecture.FindStringSubmatch(line)
-		if len(items) == 2 {
-			ostype.Architecture = items[1]
-			continue
-		}
-		items = regexOsSubType.FindStringSubmatch(line)
-		if len(items) == 2 {
-			ostype.Subtype = items[1]
-			continue
 		}
-		items = regexOs64Bit.FindStringSubmatch(line)
-		if len(items) == 2 {
-			ostype.Is64Bit, _ = strconv.ParseBool(items[1])
-			continue
+		if ostype.ID == "" {
+			return false
 		}
-	}
-	if ostype.ID != "" {
 		ostypes = append(ostypes, ostype)
-	}
+		return true
+	})
+	c.report(&s.Client, []string{"list", "--long", "ostypes"})
 	slices.SortFunc(ostypes, func(a, b *OsType) int {
 		A := a.Name
 		B := b.Name
@@ -955,7 +989,7 @@ func (s *VmServer) CreateMedia(client *VmSshClient, media MediaType, size int64,
 	} else {
 		opt = append(opt, "Standard")
 	}
-	opt = append(opt, "--filename="+client.quoteArgString(file))
+	opt = append(opt, "--filename="+file)
 	opt = append(opt, "--size")
 	opt = append(opt, size)
 
@@ -965,12 +999,13 @@ func (s *VmServer) CreateMedia(client *VmSshClient, media MediaType, size int64,
 	}
 
 	lines, err := RunCmd(client, VBOXMANAGE_APP, optS, nil, statusWriter)
+	finishProgress(statusWriter, err)
 	_ = lines
 	return err
 }
 
 func (s *VmServer) DeleteMedia(client *VmSshClient, media MediaType, file string) error {
-	opt := []any{media, client.quoteArgString(file), "--delete"}
+	opt := []any{media, file, "--delete"}
 	optS, err := argPreProcess("closemedium", opt)
 	if err != nil {
 		return nil
@@ -1010,7 +1045,7 @@ func (s *VmServer) ExportOva(client *VmSshClient, machines []string, format OvaF
 		opStr = "--options=" + opStr
 	}
 
-	opt = append(opt, "--output="+client.quoteArgString(file))
+	opt = append(opt, "--output="+file)
 	opt = append(opt, "--"+fStr)
 
 	if opStr != "" {
@@ -1022,6 +1057,7 @@ func (s *VmServer) ExportOva(client *VmSshClient, machines []string, format OvaF
 	}
 
 	lines, err := RunCmd(client, VBOXMANAGE_APP, opt, nil, statusWriter)
+	finishProgress(statusWriter, err)
 	_ = lines
 	return err
 }
@@ -1029,7 +1065,7 @@ func (s *VmServer) ExportOva(client *VmSshClient, machines []string, format OvaF
 func (s *VmServer) ImportOvaDryRun(client *VmSshClient, file string) (int, error) {
 	opt := []string{"import"}
 	opt = append(opt, "--dry-run")
-	opt = append(opt, s.Client.quoteArgString(file))
+	opt = append(opt, file)
 	lines, err := RunCmd(client, VBOXMANAGE_APP, opt, nil, nil)
 	if err != nil {
 		return 0, err
@@ -1050,7 +1086,7 @@ func (s *VmServer) ImportOva(client *VmSshClient, mac MacImportType, isVdi bool,
 		return err
 	}
 	opt := []string{"import"}
-	opt = append(opt, s.Client.quoteArgString(file))
+	opt = append(opt, file)
 	opStr := ""
 	if isVdi {
 		opStr = "importtovdi," + macStr
@@ -1063,12 +1099,23 @@ func (s *VmServer) ImportOva(client *VmSshClient, mac MacImportType, isVdi bool,
 	opt = append(opt, vsys...)
 
 	lines, err := RunCmd(client, VBOXMANAGE_APP, opt, nil, statusWriter)
+	finishProgress(statusWriter, err)
 	_ = lines
 	return err
 }
 
-func (s *VmServer) CreateVm(client *VmSshClient, name string) error {
-	opt := []string{"createvm", "--name", s.Client.quoteArgString(name), "--register"}
+func (s *VmServer) CreateVm(client *VmSshClient, name string, arch PlatformArchType) error {
+	opt := []string{"createvm", "--name", name, "--register"}
+	if arch != PlatformArch_default {
+		if !s.HasCapability(Capability_platformArch) {
+			return s.Capabilities().unsupported(Capability_platformArch)
+		}
+		a, err := argTranslate(arch)
+		if err != nil {
+			return err
+		}
+		opt = append(opt, "--platform-architecture="+a)
+	}
 
 	lines, err := RunCmd(client, VBOXMANAGE_APP, opt, nil, nil)
 	_ = lines
@@ -1082,6 +1129,8 @@ func (s *VmServer) GetExtPackHostInfos() ([]*ExtPackInfoType, error) {
 	}
 	extPackList := make([]*ExtPackInfoType, 0, 1)
 	var info *ExtPackInfoType
+	c := parseCollector{}
+	var stray []string
 	for _, line := range lines {
 		if line == "" {
 			continue
@@ -1095,6 +1144,13 @@ func (s *VmServer) GetExtPackHostInfos() ([]*ExtPackInfoType, error) {
 			extPackList = append(extPackList, info)
 			continue
 		}
+		if info == nil {
+			// Extension Packs: 1
+			if !regexExtPackHeader.MatchString(line) {
+				stray = append(stray, line)
+			}
+			continue
+		}
 
 		items = regexExtPackVersion.FindStringSubmatch(line)
 		if len(items) == 2 {
@@ -1122,5 +1178,7 @@ func (s *VmServer) GetExtPackHostInfos() ([]*ExtPackInfoType, error) {
 			continue
 		}
 	}
+	c.unparsed(stray...)
+	c.report(&s.Client, []string{"list", "--long", "extpacks"})
 	return extPackList, nil
 }